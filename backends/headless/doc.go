@@ -0,0 +1,7 @@
+// Package headless implements a Window for the Pixel game development library that requires
+// neither GLFW nor a display.
+//
+// The Window mirrors the API of the opengl Window, draws onto a software Canvas and receives its
+// input from events injected by the program, which makes it possible to run game loops in tests
+// on headless machines.
+package headless
@@ -0,0 +1,184 @@
+package headless
+
+import (
+	"io"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Pressed returns whether the Button is currently pressed down.
+func (w *Window) Pressed(button pixel.Button) bool {
+	return w.input.Curr.Buttons[button]
+}
+
+// JustPressed returns whether the Button has been pressed in the last frame.
+func (w *Window) JustPressed(button pixel.Button) bool {
+	return w.input.PressEvents[button]
+}
+
+// JustReleased returns whether the Button has been released in the last frame.
+func (w *Window) JustReleased(button pixel.Button) bool {
+	return w.input.ReleaseEvents[button]
+}
+
+// Repeated returns whether a repeat event has been triggered on button.
+func (w *Window) Repeated(button pixel.Button) bool {
+	return w.input.Curr.Repeat[button]
+}
+
+// MousePosition returns the current mouse position in the Window's Bounds.
+func (w *Window) MousePosition() pixel.Vec {
+	return w.input.Curr.Mouse
+}
+
+// MousePreviousPosition returns the previous mouse position in the Window's Bounds.
+func (w *Window) MousePreviousPosition() pixel.Vec {
+	return w.input.Prev.Mouse
+}
+
+// SetMousePosition positions the mouse cursor anywhere within the Window's Bounds.
+func (w *Window) SetMousePosition(v pixel.Vec) {
+	if (v.X >= 0 && v.X <= w.bounds.W()) &&
+		(v.Y >= 0 && v.Y <= w.bounds.H()) {
+		w.input.SetMousePosition(v)
+	}
+}
+
+// MouseInsideWindow returns true if the mouse position is within the Window's Bounds.
+func (w *Window) MouseInsideWindow() bool {
+	return w.input.MouseInsideWindow
+}
+
+// MouseScroll returns the mouse scroll amount (in both axes) since the last call to Window.Update.
+func (w *Window) MouseScroll() pixel.Vec {
+	return w.input.Curr.Scroll
+}
+
+// MousePreviousScroll returns the mouse scroll amount of the frame before the last one.
+func (w *Window) MousePreviousScroll() pixel.Vec {
+	return w.input.Prev.Scroll
+}
+
+// Typed returns the text typed on the keyboard since the last call to Window.Update.
+func (w *Window) Typed() string {
+	return w.input.Curr.Typed
+}
+
+func (w *Window) SetButtonCallback(callback func(win *Window, button pixel.Button, action pixel.Action)) {
+	w.buttonCallback = callback
+}
+
+func (w *Window) SetCharCallback(callback func(win *Window, r rune)) {
+	w.charCallback = callback
+}
+
+func (w *Window) SetMouseEnteredCallback(callback func(win *Window, entered bool)) {
+	w.mouseEnteredCallback = callback
+}
+
+func (w *Window) SetMouseMovedCallback(callback func(win *Window, pos pixel.Vec)) {
+	w.mouseMovedCallback = callback
+}
+
+func (w *Window) SetScrollCallback(callback func(win *Window, scroll pixel.Vec)) {
+	w.scrollCallback = callback
+}
+
+// ButtonEvent injects a press, release or repeat of a button. It becomes visible after the next
+// call to Update.
+func (w *Window) ButtonEvent(button pixel.Button, action pixel.Action) {
+	w.input.ButtonEvent(button, action)
+	if w.buttonCallback != nil {
+		w.buttonCallback(w, button, action)
+	}
+}
+
+// CharEvent injects a typed character. It becomes visible after the next call to Update.
+func (w *Window) CharEvent(r rune) {
+	w.input.CharEvent(r)
+	if w.charCallback != nil {
+		w.charCallback(w, r)
+	}
+}
+
+// TypeEvent injects all characters of the string, as if they were typed one by one.
+func (w *Window) TypeEvent(s string) {
+	for _, r := range s {
+		w.CharEvent(r)
+	}
+}
+
+// MouseMoveEvent injects a mouse movement to the given position in the Window's Bounds. It becomes
+// visible after the next call to Update.
+func (w *Window) MouseMoveEvent(pos pixel.Vec) {
+	w.input.MouseMoveEvent(pos)
+	if w.mouseMovedCallback != nil {
+		w.mouseMovedCallback(w, pos)
+	}
+}
+
+// MouseScrollEvent injects a mouse scroll. It becomes visible after the next call to Update.
+func (w *Window) MouseScrollEvent(scroll pixel.Vec) {
+	w.input.MouseScrollEvent(scroll.X, scroll.Y)
+	if w.scrollCallback != nil {
+		w.scrollCallback(w, scroll)
+	}
+}
+
+// MouseEnteredEvent injects the mouse entering or leaving the Window.
+func (w *Window) MouseEnteredEvent(entered bool) {
+	w.input.MouseEnteredEvent(entered)
+	if w.mouseEnteredCallback != nil {
+		w.mouseEnteredCallback(w, entered)
+	}
+}
+
+// UpdateInput processes the injected events. Call this function to process events without
+// swapping buffers. Note that the Update method invokes UpdateInput.
+func (w *Window) UpdateInput() {
+	w.doUpdateInput()
+}
+
+// UpdateInputWait exists for compatibility with the opengl backend. Since injected events are
+// never awaited, it's equivalent to UpdateInput.
+func (w *Window) UpdateInputWait(timeout time.Duration) {
+	w.doUpdateInput()
+}
+
+// internal input bookkeeping
+func (w *Window) doUpdateInput() {
+	w.updateJoystickInput()
+	w.input.Update(&w.currJoy)
+}
+
+// RecordInput starts recording every input event and the state of the joysticks, frame by frame,
+// into the writer, until StopRecordingInput is called. The recording can be replayed with
+// ReplayInput.
+func (w *Window) RecordInput(wr io.Writer) error {
+	return w.input.StartRecording(wr)
+}
+
+// StopRecordingInput stops recording the input and reports any error that occurred while writing
+// the recording.
+func (w *Window) StopRecordingInput() error {
+	return w.input.StopRecording()
+}
+
+// ReplayInput replays a recording made with RecordInput, one recorded frame per call to Update,
+// so that Pressed, JustPressed, Typed, MousePosition, JoystickAxis, etc. return exactly what they
+// returned while recording. Live input is ignored until the replay is over or StopReplayingInput
+// is called. Callbacks are not invoked for replayed events.
+func (w *Window) ReplayInput(r io.Reader) error {
+	return w.input.StartReplay(r)
+}
+
+// StopReplayingInput stops replaying the input and resumes handling live input.
+func (w *Window) StopReplayingInput() {
+	w.input.StopReplay()
+}
+
+// ReplayingInput returns whether a recording of input is being replayed.
+func (w *Window) ReplayingInput() bool {
+	return w.input.Replaying()
+}
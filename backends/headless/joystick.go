@@ -0,0 +1,113 @@
+package headless
+
+import (
+	"github.com/gopxl/pixel/v2"
+)
+
+// JoystickPresent returns if the joystick is currently connected.
+//
+// This API is experimental.
+func (w *Window) JoystickPresent(js pixel.Joystick) bool {
+	return w.currJoy.Connected[js]
+}
+
+// JoystickName returns the name of the joystick. A disconnected joystick will return an
+// empty string.
+//
+// This API is experimental.
+func (w *Window) JoystickName(js pixel.Joystick) string {
+	return w.currJoy.Name[js]
+}
+
+// JoystickButtonCount returns the number of buttons a connected joystick has.
+//
+// This API is experimental.
+func (w *Window) JoystickButtonCount(js pixel.Joystick) int {
+	return len(w.currJoy.Buttons[js])
+}
+
+// JoystickAxisCount returns the number of axes a connected joystick has.
+//
+// This API is experimental.
+func (w *Window) JoystickAxisCount(js pixel.Joystick) int {
+	return len(w.currJoy.Axis[js])
+}
+
+// JoystickPressed returns whether the joystick Button is currently pressed down.
+// If the button index is out of range, this will return false.
+//
+// This API is experimental.
+func (w *Window) JoystickPressed(js pixel.Joystick, button pixel.GamepadButton) bool {
+	return w.currJoy.GetButton(js, button)
+}
+
+// JoystickJustPressed returns whether the joystick Button has just been pressed down.
+// If the button index is out of range, this will return false.
+//
+// This API is experimental.
+func (w *Window) JoystickJustPressed(js pixel.Joystick, button pixel.GamepadButton) bool {
+	return w.currJoy.GetButton(js, button) && !w.prevJoy.GetButton(js, button)
+}
+
+// JoystickJustReleased returns whether the joystick Button has just been released up.
+// If the button index is out of range, this will return false.
+//
+// This API is experimental.
+func (w *Window) JoystickJustReleased(js pixel.Joystick, button pixel.GamepadButton) bool {
+	return !w.currJoy.GetButton(js, button) && w.prevJoy.GetButton(js, button)
+}
+
+// JoystickAxis returns the value of a joystick axis at the last call to Window.Update.
+// If the axis index is out of range, this will return 0.
+//
+// This API is experimental.
+func (w *Window) JoystickAxis(js pixel.Joystick, axis pixel.GamepadAxis) float64 {
+	return w.currJoy.GetAxis(js, axis)
+}
+
+// JoystickConnectEvent injects the connection of a gamepad with the given name. The gamepad has
+// all the standard gamepad buttons and axes, all released and centered.
+func (w *Window) JoystickConnectEvent(js pixel.Joystick, name string) {
+	w.tempJoy.Connected[js] = true
+	w.tempJoy.Name[js] = name
+	w.tempJoy.Buttons[js] = make([]pixel.Action, pixel.NumGamepadButtons)
+	w.tempJoy.Axis[js] = make([]float32, pixel.NumAxes)
+}
+
+// JoystickDisconnectEvent injects the disconnection of a joystick.
+func (w *Window) JoystickDisconnectEvent(js pixel.Joystick) {
+	w.tempJoy.Connected[js] = false
+	w.tempJoy.Name[js] = ""
+	w.tempJoy.Buttons[js] = []pixel.Action{}
+	w.tempJoy.Axis[js] = []float32{}
+}
+
+// JoystickButtonEvent injects a press or release of a button of a connected joystick. It becomes
+// visible after the next call to Update.
+func (w *Window) JoystickButtonEvent(js pixel.Joystick, button pixel.GamepadButton, action pixel.Action) {
+	if int(button) < 0 || int(button) >= len(w.tempJoy.Buttons[js]) {
+		return
+	}
+	w.tempJoy.Buttons[js][button] = action
+}
+
+// JoystickAxisEvent injects a new value of an axis of a connected joystick. It becomes visible
+// after the next call to Update.
+func (w *Window) JoystickAxisEvent(js pixel.Joystick, axis pixel.GamepadAxis, value float64) {
+	if int(axis) < 0 || int(axis) >= len(w.tempJoy.Axis[js]) {
+		return
+	}
+	w.tempJoy.Axis[js][axis] = float32(value)
+}
+
+// Used internally during Window.UpdateInput to update the state of the joysticks.
+func (w *Window) updateJoystickInput() {
+	w.prevJoy = w.currJoy
+	w.currJoy = w.tempJoy
+
+	// the injected state keeps being modified, so the current state needs its own copy
+	for js := range w.currJoy.Buttons {
+		w.currJoy.Buttons[js] = append([]pixel.Action(nil), w.tempJoy.Buttons[js]...)
+		w.currJoy.Axis[js] = append([]float32(nil), w.tempJoy.Axis[js]...)
+	}
+}
@@ -0,0 +1,262 @@
+package headless
+
+import (
+	"image/color"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/internal"
+	"github.com/gopxl/pixel/v2/backends/software"
+)
+
+// WindowConfig is a structure for specifying the properties of a Window.
+//
+// Note that you always need to set the Bounds of a Window.
+type WindowConfig struct {
+	// Title of the Window.
+	Title string
+
+	// Bounds specify the bounds of the Window in pixels.
+	Bounds pixel.Rect
+
+	// Initial window position
+	Position pixel.Vec
+
+	// VSync (vertical synchronization) has no effect on a headless Window, it's only stored.
+	VSync bool
+
+	// Invisible specifies whether the window will be initially hidden.
+	Invisible bool
+}
+
+// Window is a window handler without an actual window. Use this type to run code written for a
+// Window without a display, drawing onto an in-memory Canvas and receiving injected input.
+type Window struct {
+	title         string
+	bounds        pixel.Rect
+	pos           pixel.Vec
+	canvas        *software.Canvas
+	closed        bool
+	visible       bool
+	focused       bool
+	vsync         bool
+	cursorVisible bool
+	clipboard     string
+
+	input                     internal.InputHandler
+	prevJoy, currJoy, tempJoy internal.JoystickState
+
+	buttonCallback       func(win *Window, button pixel.Button, action pixel.Action)
+	charCallback         func(win *Window, r rune)
+	mouseEnteredCallback func(win *Window, entered bool)
+	mouseMovedCallback   func(win *Window, pos pixel.Vec)
+	scrollCallback       func(win *Window, scroll pixel.Vec)
+}
+
+var _ pixel.Window = (*Window)(nil)
+
+// NewWindow creates a new Window with it's properties specified in the provided config.
+//
+// Creating a headless Window never fails, the error is returned for compatibility with the opengl
+// backend.
+func NewWindow(cfg WindowConfig) (*Window, error) {
+	w := &Window{
+		title:         cfg.Title,
+		bounds:        cfg.Bounds,
+		pos:           cfg.Position,
+		visible:       !cfg.Invisible,
+		focused:       !cfg.Invisible,
+		vsync:         cfg.VSync,
+		cursorVisible: true,
+	}
+	w.canvas = software.NewCanvas(cfg.Bounds)
+	w.Update()
+	return w, nil
+}
+
+// Destroy destroys the Window. It exists for compatibility with the opengl backend and does
+// nothing.
+func (w *Window) Destroy() {}
+
+// Update swaps buffers and processes the injected events. Call this method at the end of each
+// frame.
+func (w *Window) Update() {
+	w.SwapBuffers()
+	w.UpdateInput()
+}
+
+// SwapBuffers does nothing, since the content of a headless Window is its Canvas. It exists for
+// compatibility with the opengl backend.
+func (w *Window) SwapBuffers() {
+	w.canvas.SetBounds(w.bounds)
+}
+
+// ClipboardText returns the current value of the Window's clipboard. The clipboard is private to
+// the Window and not shared with the system.
+func (w *Window) ClipboardText() string {
+	return w.clipboard
+}
+
+// SetClipboardText sets the Window's clipboard.
+func (w *Window) SetClipboardText(text string) {
+	w.clipboard = text
+}
+
+// Clipboard returns the contents of the Window's clipboard.
+func (w *Window) Clipboard() string {
+	return w.clipboard
+}
+
+// SetClipboard sets the Window's clipboard to the specified UTF-8 encoded string.
+func (w *Window) SetClipboard(str string) {
+	w.clipboard = str
+}
+
+// SetClosed sets the closed flag of the Window.
+func (w *Window) SetClosed(closed bool) {
+	w.closed = closed
+}
+
+// Closed returns the closed flag of the Window, which reports whether the Window should be closed.
+func (w *Window) Closed() bool {
+	return w.closed
+}
+
+// SetTitle changes the title of the Window.
+func (w *Window) SetTitle(title string) {
+	w.title = title
+}
+
+// Title returns the title of the Window.
+func (w *Window) Title() string {
+	return w.title
+}
+
+// SetBounds sets the bounds of the Window in pixels. The Canvas is resized on the next call to
+// Update or SwapBuffers.
+func (w *Window) SetBounds(bounds pixel.Rect) {
+	w.bounds = bounds
+}
+
+// Bounds returns the current bounds of the Window.
+func (w *Window) Bounds() pixel.Rect {
+	return w.bounds
+}
+
+// SetPos sets the position of the upper-left corner of the Window.
+func (w *Window) SetPos(pos pixel.Vec) {
+	w.pos = pos
+}
+
+// GetPos gets the position of the upper-left corner of the Window.
+func (w *Window) GetPos() pixel.Vec {
+	return w.pos
+}
+
+// Focused returns true if the Window has input focus.
+func (w *Window) Focused() bool {
+	return w.focused
+}
+
+// SetVSync sets whether the Window's Update should synchronize with the monitor refresh rate. It
+// has no effect on a headless Window.
+func (w *Window) SetVSync(vsync bool) {
+	w.vsync = vsync
+}
+
+// VSync returns whether the Window is set to synchronize with the monitor refresh rate.
+func (w *Window) VSync() bool {
+	return w.vsync
+}
+
+// SetCursorVisible sets the visibility of the mouse cursor inside the Window client area.
+func (w *Window) SetCursorVisible(visible bool) {
+	w.cursorVisible = visible
+}
+
+// SetCursorDisabled hides the cursor.
+func (w *Window) SetCursorDisabled() {
+	w.cursorVisible = false
+}
+
+// CursorVisible returns the visibility status of the mouse cursor.
+func (w *Window) CursorVisible() bool {
+	return w.cursorVisible
+}
+
+// MakeTriangles generates a specialized copy of the supplied Triangles that will draw onto this
+// Window.
+//
+// Window supports TrianglesPosition, TrianglesColor, TrianglesPicture and TrianglesClipped.
+func (w *Window) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	return w.canvas.MakeTriangles(t)
+}
+
+// MakePicture generates a specialized copy of the supplied Picture that will draw onto this Window.
+//
+// Window supports PictureColor.
+func (w *Window) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	return w.canvas.MakePicture(p)
+}
+
+// SetMatrix sets a Matrix that every point will be projected by.
+func (w *Window) SetMatrix(m pixel.Matrix) {
+	w.canvas.SetMatrix(m)
+}
+
+// SetColorMask sets a global color mask for the Window.
+func (w *Window) SetColorMask(c color.Color) {
+	w.canvas.SetColorMask(c)
+}
+
+// SetComposeMethod sets a Porter-Duff composition method to be used in the following draws onto
+// this Window.
+func (w *Window) SetComposeMethod(cmp pixel.ComposeMethod) {
+	w.canvas.SetComposeMethod(cmp)
+}
+
+// SetSmooth sets whether the stretched Pictures drawn onto this Window should be drawn smooth or
+// pixely.
+func (w *Window) SetSmooth(smooth bool) {
+	w.canvas.SetSmooth(smooth)
+}
+
+// Smooth returns whether the stretched Pictures drawn onto this Window are set to be drawn smooth
+// or pixely.
+func (w *Window) Smooth() bool {
+	return w.canvas.Smooth()
+}
+
+// Clear clears the Window with a single color.
+func (w *Window) Clear(c color.Color) {
+	w.canvas.Clear(c)
+}
+
+// Color returns the color of the pixel over the given position inside the Window.
+func (w *Window) Color(at pixel.Vec) pixel.RGBA {
+	return w.canvas.Color(at)
+}
+
+// Canvas returns the window's underlying Canvas
+func (w *Window) Canvas() *software.Canvas {
+	return w.canvas
+}
+
+// Show makes the window visible.
+func (w *Window) Show() {
+	w.visible = true
+}
+
+// Hide hides the window.
+func (w *Window) Hide() {
+	w.visible = false
+}
+
+// Visible returns whether the window is visible.
+func (w *Window) Visible() bool {
+	return w.visible
+}
+
+// Focus makes the window focused.
+func (w *Window) Focus() {
+	w.focused = true
+}
@@ -0,0 +1,175 @@
+package headless_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/headless"
+	"github.com/gopxl/pixel/v2/ext/imdraw"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWindow(t *testing.T) *headless.Window {
+	win, err := headless.NewWindow(headless.WindowConfig{
+		Title:  "testing",
+		Bounds: pixel.R(0, 0, 100, 100),
+	})
+	require.NoError(t, err)
+	return win
+}
+
+func TestWindow_Buttons(t *testing.T) {
+	win := newWindow(t)
+
+	win.ButtonEvent(pixel.KeySpace, pixel.Press)
+	assert.False(t, win.Pressed(pixel.KeySpace), "events are applied on Update")
+
+	win.Update()
+	assert.True(t, win.Pressed(pixel.KeySpace))
+	assert.True(t, win.JustPressed(pixel.KeySpace))
+
+	win.Update()
+	assert.True(t, win.Pressed(pixel.KeySpace))
+	assert.False(t, win.JustPressed(pixel.KeySpace))
+
+	win.ButtonEvent(pixel.KeySpace, pixel.Release)
+	win.Update()
+	assert.False(t, win.Pressed(pixel.KeySpace))
+	assert.True(t, win.JustReleased(pixel.KeySpace))
+}
+
+func TestWindow_MouseAndTyping(t *testing.T) {
+	win := newWindow(t)
+
+	var moved pixel.Vec
+	win.SetMouseMovedCallback(func(_ *headless.Window, pos pixel.Vec) { moved = pos })
+
+	win.MouseMoveEvent(pixel.V(10, 20))
+	win.MouseScrollEvent(pixel.V(0, 1))
+	win.MouseScrollEvent(pixel.V(0, 2))
+	win.TypeEvent("hi")
+	win.Update()
+
+	assert.Equal(t, pixel.V(10, 20), moved)
+	assert.Equal(t, pixel.V(10, 20), win.MousePosition())
+	assert.Equal(t, pixel.V(0, 3), win.MouseScroll())
+	assert.Equal(t, "hi", win.Typed())
+
+	win.Update()
+	assert.Equal(t, pixel.V(10, 20), win.MousePreviousPosition())
+	assert.Equal(t, pixel.ZV, win.MouseScroll())
+	assert.Equal(t, "", win.Typed())
+}
+
+func TestWindow_Joystick(t *testing.T) {
+	win := newWindow(t)
+
+	win.JoystickConnectEvent(pixel.Joystick1, "pad")
+	win.JoystickButtonEvent(pixel.Joystick1, pixel.GamepadA, pixel.Press)
+	win.JoystickAxisEvent(pixel.Joystick1, pixel.AxisLeftX, 0.5)
+	win.Update()
+
+	assert.True(t, win.JoystickPresent(pixel.Joystick1))
+	assert.Equal(t, "pad", win.JoystickName(pixel.Joystick1))
+	assert.True(t, win.JoystickJustPressed(pixel.Joystick1, pixel.GamepadA))
+	assert.Equal(t, 0.5, win.JoystickAxis(pixel.Joystick1, pixel.AxisLeftX))
+
+	win.JoystickButtonEvent(pixel.Joystick1, pixel.GamepadA, pixel.Release)
+	win.Update()
+	assert.True(t, win.JoystickJustReleased(pixel.Joystick1, pixel.GamepadA))
+
+	win.JoystickDisconnectEvent(pixel.Joystick1)
+	win.Update()
+	assert.False(t, win.JoystickPresent(pixel.Joystick1))
+	assert.Equal(t, 0.0, win.JoystickAxis(pixel.Joystick1, pixel.AxisLeftX))
+}
+
+func TestWindow_Draw(t *testing.T) {
+	win := newWindow(t)
+	win.Clear(pixel.RGB(0, 0, 1))
+
+	imd := imdraw.New(nil)
+	imd.Color = pixel.RGB(1, 0, 0)
+	imd.Push(pixel.V(10, 10), pixel.V(20, 20))
+	imd.Rectangle(0)
+	imd.Draw(win)
+	win.Update()
+
+	assert.Equal(t, pixel.RGB(1, 0, 0), win.Color(pixel.V(15, 15)))
+	assert.Equal(t, pixel.RGB(0, 0, 1), win.Color(pixel.V(50, 50)))
+}
+
+func TestWindow_Closed(t *testing.T) {
+	win := newWindow(t)
+
+	frames := 0
+	for !win.Closed() {
+		frames++
+		if frames == 3 {
+			win.SetClosed(true)
+		}
+		win.Update()
+	}
+	assert.Equal(t, 3, frames)
+}
+
+func TestWindow_RecordAndReplay(t *testing.T) {
+	win := newWindow(t)
+
+	var rec bytes.Buffer
+	require.NoError(t, win.RecordInput(&rec))
+
+	win.ButtonEvent(pixel.KeyA, pixel.Press)
+	win.MouseMoveEvent(pixel.V(1, 2))
+	win.Update()
+	win.TypeEvent("ok")
+	win.JoystickConnectEvent(pixel.Joystick2, "pad")
+	win.JoystickAxisEvent(pixel.Joystick2, pixel.AxisRightY, -1)
+	win.Update()
+	win.ButtonEvent(pixel.KeyA, pixel.Release)
+	win.MouseScrollEvent(pixel.V(0, -1))
+	win.Update()
+	require.NoError(t, win.StopRecordingInput())
+
+	replay := newWindow(t)
+	require.NoError(t, replay.ReplayInput(bytes.NewReader(rec.Bytes())))
+	assert.True(t, replay.ReplayingInput())
+
+	// live input is ignored while replaying
+	replay.ButtonEvent(pixel.KeyB, pixel.Press)
+
+	replay.Update()
+	assert.True(t, replay.JustPressed(pixel.KeyA))
+	assert.False(t, replay.Pressed(pixel.KeyB))
+	assert.Equal(t, pixel.V(1, 2), replay.MousePosition())
+
+	replay.Update()
+	assert.True(t, replay.Pressed(pixel.KeyA))
+	assert.False(t, replay.JustPressed(pixel.KeyA))
+	assert.Equal(t, "ok", replay.Typed())
+	assert.Equal(t, "pad", replay.JoystickName(pixel.Joystick2))
+	assert.Equal(t, -1.0, replay.JoystickAxis(pixel.Joystick2, pixel.AxisRightY))
+
+	replay.Update()
+	assert.True(t, replay.JustReleased(pixel.KeyA))
+	assert.Equal(t, pixel.V(0, -1), replay.MouseScroll())
+	assert.Equal(t, -1.0, replay.JoystickAxis(pixel.Joystick2, pixel.AxisRightY))
+
+	replay.Update()
+	assert.False(t, replay.ReplayingInput())
+
+	assert.Error(t, replay.ReplayInput(bytes.NewReader(rec.Bytes()[:rec.Len()-3])))
+	assert.Error(t, replay.ReplayInput(strings.NewReader("garbage")))
+
+	// corrupt lengths are rejected instead of allocated
+	for _, length := range []uint64{1 << 40, 1 << 63} {
+		corrupt := append([]byte(nil), rec.Bytes()[:5]...)
+		corrupt = append(corrupt, 0, 1, 0, 1) // no events, a connected Joystick1
+		corrupt = binary.AppendUvarint(corrupt, length)
+		assert.Error(t, replay.ReplayInput(bytes.NewReader(corrupt)))
+	}
+}
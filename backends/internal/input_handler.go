@@ -1,6 +1,11 @@
 package internal
 
-import "github.com/gopxl/pixel/v2"
+import (
+	"errors"
+	"io"
+
+	"github.com/gopxl/pixel/v2"
+)
 
 type InputState struct {
 	Mouse   pixel.Vec
@@ -17,6 +22,9 @@ type InputHandler struct {
 	ReleaseEvents, tempReleaseEvents [pixel.NumButtons]bool
 
 	MouseInsideWindow bool
+
+	recorder *recorder
+	player   *player
 }
 
 // SetMousePosition overrides the mouse position
@@ -29,40 +37,142 @@ func (ih *InputHandler) SetMousePosition(pos pixel.Vec) {
 
 // ButtonEvent sets the action state of a button for the next update
 func (ih *InputHandler) ButtonEvent(button pixel.Button, action pixel.Action) {
-	switch action {
-	case pixel.Press:
-		ih.tempPressEvents[button] = true
-		ih.temp.Buttons[button] = true
-	case pixel.Release:
-		ih.tempReleaseEvents[button] = true
-		ih.temp.Buttons[button] = false
-	case pixel.Repeat:
-		ih.temp.Repeat[button] = true
-	}
+	ih.event(inputEvent{kind: buttonEvent, button: button, action: action})
 }
 
 // MouseMoveEvent sets the mouse position for the next update
 func (ih *InputHandler) MouseMoveEvent(pos pixel.Vec) {
-	ih.temp.Mouse = pos
+	ih.event(inputEvent{kind: mouseMoveEvent, vec: pos})
 }
 
 // MouseScrollEvent adds to the scroll offset for the next update
 func (ih *InputHandler) MouseScrollEvent(x, y float64) {
-	ih.temp.Scroll.X += x
-	ih.temp.Scroll.Y += y
+	ih.event(inputEvent{kind: mouseScrollEvent, vec: pixel.V(x, y)})
 }
 
 // MouseEnteredEvent is called when the mouse enters or leaves the window
 func (ih *InputHandler) MouseEnteredEvent(entered bool) {
-	ih.MouseInsideWindow = entered
+	ih.event(inputEvent{kind: mouseEnteredEvent, entered: entered})
 }
 
 // CharEvent adds to the typed string for the next update
 func (ih *InputHandler) CharEvent(r rune) {
-	ih.temp.Typed += string(r)
+	ih.event(inputEvent{kind: charEvent, char: r})
+}
+
+// event records and applies a live event. Live events are ignored while replaying.
+func (ih *InputHandler) event(ev inputEvent) {
+	if ih.player != nil {
+		return
+	}
+	if ih.recorder != nil {
+		ih.recorder.record(ev)
+	}
+	ih.apply(ev)
+}
+
+func (ih *InputHandler) apply(ev inputEvent) {
+	switch ev.kind {
+	case buttonEvent:
+		switch ev.action {
+		case pixel.Press:
+			ih.tempPressEvents[ev.button] = true
+			ih.temp.Buttons[ev.button] = true
+		case pixel.Release:
+			ih.tempReleaseEvents[ev.button] = true
+			ih.temp.Buttons[ev.button] = false
+		case pixel.Repeat:
+			ih.temp.Repeat[ev.button] = true
+		}
+	case mouseMoveEvent:
+		ih.temp.Mouse = ev.vec
+	case mouseScrollEvent:
+		ih.temp.Scroll = ih.temp.Scroll.Add(ev.vec)
+	case mouseEnteredEvent:
+		ih.MouseInsideWindow = ev.entered
+	case charEvent:
+		ih.temp.Typed += string(ev.char)
+	}
+}
+
+// StartRecording starts writing every event and joystick state, frame by frame, into w until
+// StopRecording is called. The recording starts with the held buttons, the mouse position and
+// whether the mouse is inside of the window.
+func (ih *InputHandler) StartRecording(w io.Writer) error {
+	if ih.recorder != nil {
+		return errors.New("input is already being recorded")
+	}
+	r, err := newRecorder(w, inputSnapshot{
+		mouse:   ih.temp.Mouse,
+		buttons: ih.temp.Buttons,
+		inside:  ih.MouseInsideWindow,
+	})
+	if err != nil {
+		return err
+	}
+	ih.recorder = r
+	return nil
+}
+
+// StopRecording stops recording and reports any error that occurred while writing the recording.
+func (ih *InputHandler) StopRecording() error {
+	if ih.recorder == nil {
+		return nil
+	}
+	err := ih.recorder.close()
+	ih.recorder = nil
+	return err
+}
+
+// StartReplay reads a recording made with StartRecording and replays it, one recorded frame per
+// Update. The input state is reset to the one the recording started with, dropping live events
+// not yet made current by Update. Live events are ignored until the replay is over or StopReplay
+// is called.
+func (ih *InputHandler) StartReplay(r io.Reader) error {
+	p, err := newPlayer(r)
+	if err != nil {
+		return err
+	}
+	ih.player = p
+
+	ih.temp = InputState{Mouse: p.start.mouse, Buttons: p.start.buttons}
+	ih.Curr = ih.temp
+	ih.tempPressEvents = [pixel.NumButtons]bool{}
+	ih.tempReleaseEvents = [pixel.NumButtons]bool{}
+	ih.MouseInsideWindow = p.start.inside
+	return nil
+}
+
+// StopReplay stops replaying and resumes handling live events.
+func (ih *InputHandler) StopReplay() {
+	ih.player = nil
+}
+
+// Replaying returns whether a recording is being replayed.
+func (ih *InputHandler) Replaying() bool {
+	return ih.player != nil
 }
 
-func (ih *InputHandler) Update() {
+// Update makes the events since the last update current. The joystick state must be the freshly
+// polled one, it is recorded, or replaced with the replayed one.
+func (ih *InputHandler) Update(joy *JoystickState) {
+	if ih.player != nil {
+		if events, ok := ih.player.next(); ok {
+			for _, ev := range events {
+				if ih.recorder != nil {
+					ih.recorder.record(ev)
+				}
+				ih.apply(ev)
+			}
+			*joy = copyJoystickState(&ih.player.joy)
+		} else {
+			ih.player = nil
+		}
+	}
+	if ih.recorder != nil {
+		ih.recorder.endFrame(joy)
+	}
+
 	ih.Prev = ih.Curr
 	ih.Curr = ih.temp
 
@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// inputFrameState is what an InputHandler reports after an update.
+type inputFrameState struct {
+	curr, prev    InputState
+	press, rel    [pixel.NumButtons]bool
+	inside        bool
+	joyConnected  bool
+	joyFirstAxis  float32
+	joyFirstPress bool
+}
+
+func frameState(ih *InputHandler, joy *JoystickState) inputFrameState {
+	s := inputFrameState{
+		curr:         ih.Curr,
+		prev:         ih.Prev,
+		press:        ih.PressEvents,
+		rel:          ih.ReleaseEvents,
+		inside:       ih.MouseInsideWindow,
+		joyConnected: joy.Connected[pixel.Joystick1],
+	}
+	if len(joy.Axis[pixel.Joystick1]) > 0 {
+		s.joyFirstAxis = joy.Axis[pixel.Joystick1][0]
+	}
+	s.joyFirstPress = joy.GetButton(pixel.Joystick1, pixel.GamepadA)
+	return s
+}
+
+// recordSession holds KeyA with the mouse at (5, 5) before recording a few frames of input.
+func recordSession(t *testing.T) ([]byte, []inputFrameState) {
+	ih := &InputHandler{}
+	ih.ButtonEvent(pixel.KeyA, pixel.Press)
+	ih.MouseMoveEvent(pixel.V(5, 5))
+	ih.MouseEnteredEvent(true)
+	ih.Update(&JoystickState{})
+
+	var buf bytes.Buffer
+	require.NoError(t, ih.StartRecording(&buf))
+
+	var joy JoystickState
+	var states []inputFrameState
+	frames := []func(){
+		func() {
+			ih.MouseMoveEvent(pixel.V(10, 20))
+			ih.CharEvent('h')
+			ih.CharEvent('i')
+		},
+		func() {
+			ih.ButtonEvent(pixel.KeyA, pixel.Release)
+			ih.ButtonEvent(pixel.MouseButtonLeft, pixel.Press)
+			ih.MouseScrollEvent(0, 1)
+			joy.Connected[pixel.Joystick1] = true
+			joy.Name[pixel.Joystick1] = "pad"
+			joy.Buttons[pixel.Joystick1] = []pixel.Action{pixel.Press}
+			joy.Axis[pixel.Joystick1] = []float32{0.5}
+		},
+		func() {
+			ih.ButtonEvent(pixel.MouseButtonLeft, pixel.Repeat)
+			ih.MouseEnteredEvent(false)
+		},
+		func() {},
+	}
+	for _, frame := range frames {
+		frame()
+		ih.Update(&joy)
+		states = append(states, frameState(ih, &joy))
+	}
+	require.NoError(t, ih.StopRecording())
+	return buf.Bytes(), states
+}
+
+func TestInputHandler_Replay(t *testing.T) {
+	recording, want := recordSession(t)
+
+	ih := &InputHandler{}
+	ih.MouseMoveEvent(pixel.V(100, 100))
+	ih.Update(&JoystickState{})
+	// live events not yet made current don't leak into the replay
+	ih.ButtonEvent(pixel.KeyB, pixel.Press)
+	ih.CharEvent('x')
+
+	require.NoError(t, ih.StartReplay(bytes.NewReader(recording)))
+	assert.True(t, ih.Replaying())
+	assert.True(t, ih.Curr.Buttons[pixel.KeyA])
+	assert.Equal(t, pixel.V(5, 5), ih.Curr.Mouse)
+	assert.True(t, ih.MouseInsideWindow)
+
+	var got []inputFrameState
+	for range want {
+		// the live joystick and events are replaced by the recorded ones
+		joy := JoystickState{}
+		ih.ButtonEvent(pixel.KeyC, pixel.Press)
+		ih.Update(&joy)
+		got = append(got, frameState(ih, &joy))
+	}
+	assert.Equal(t, want, got)
+	assert.False(t, ih.Curr.Buttons[pixel.KeyB])
+	assert.False(t, ih.Curr.Buttons[pixel.KeyC])
+
+	// the replay ends after the last recorded frame
+	ih.Update(&JoystickState{})
+	assert.False(t, ih.Replaying())
+	ih.ButtonEvent(pixel.KeyC, pixel.Press)
+	ih.Update(&JoystickState{})
+	assert.True(t, ih.Curr.Buttons[pixel.KeyC])
+}
+
+func TestInputHandler_StartReplayErrors(t *testing.T) {
+	recording, _ := recordSession(t)
+	header := []byte(recordingMagic + string(rune(recordingVersion)))
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, io.EOF},
+		{"not a recording", []byte("GIF89a"), nil},
+		{"unsupported version", []byte(recordingMagic + "\xff"), nil},
+		{"truncated state", recording[:len(header)+4], io.ErrUnexpectedEOF},
+		{"truncated frame", recording[:len(recording)-3], io.ErrUnexpectedEOF},
+		{"too many held buttons", binary.AppendUvarint(append(header, make([]byte, 17)...), 1<<16), nil},
+		{"too long joystick name", joystickRecording(header, 1<<20), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ih := &InputHandler{}
+			err := ih.StartReplay(bytes.NewReader(tt.data))
+			require.Error(t, err)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			}
+			assert.False(t, ih.Replaying())
+		})
+	}
+
+	ih := &InputHandler{}
+	assert.NoError(t, ih.StartReplay(bytes.NewReader(joystickRecording(header, 3))))
+}
+
+// joystickRecording returns a recording of a single frame connecting a joystick whose name claims
+// to be n bytes long, followed by three bytes.
+func joystickRecording(header []byte, n uint64) []byte {
+	var buf bytes.Buffer
+	buf.Write(header)
+	// the mouse, whether it is inside of the window and no held buttons
+	buf.Write(make([]byte, 8+8+1+1))
+	// no events and one joystick change, connecting the first joystick
+	buf.Write([]byte{0, 1})
+	buf.Write([]byte{0, 1})
+	buf.Write(binary.AppendUvarint(nil, n))
+	buf.WriteString("pad")
+	// no buttons and no axes
+	buf.Write([]byte{0, 0})
+	return buf.Bytes()
+}
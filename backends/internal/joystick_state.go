@@ -1,6 +1,10 @@
 package internal
 
-import "github.com/gopxl/pixel/v2"
+import (
+	"slices"
+
+	"github.com/gopxl/pixel/v2"
+)
 
 type JoystickState struct {
 	Connected [pixel.NumJoysticks]bool
@@ -26,3 +30,21 @@ func (js *JoystickState) GetAxis(joystick pixel.Joystick, axis pixel.GamepadAxis
 	}
 	return float64(js.Axis[joystick][axis])
 }
+
+// copyJoystickState returns a deep copy of the joystick state.
+func copyJoystickState(js *JoystickState) JoystickState {
+	c := *js
+	for i := range c.Buttons {
+		c.Buttons[i] = append([]pixel.Action(nil), js.Buttons[i]...)
+		c.Axis[i] = append([]float32(nil), js.Axis[i]...)
+	}
+	return c
+}
+
+// joystickEqual returns whether a joystick is in the same state in both joystick states.
+func joystickEqual(a, b *JoystickState, js int) bool {
+	return a.Connected[js] == b.Connected[js] &&
+		a.Name[js] == b.Name[js] &&
+		slices.Equal(a.Buttons[js], b.Buttons[js]) &&
+		slices.Equal(a.Axis[js], b.Axis[js])
+}
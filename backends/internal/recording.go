@@ -0,0 +1,416 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// recordingMagic starts every input recording, followed by the format version.
+const (
+	recordingMagic   = "PXIR"
+	recordingVersion = 2
+)
+
+// Limits of the joystick states in a recording, so that a corrupt one can't make the player
+// allocate huge buffers.
+const (
+	maxJoystickName    = 1 << 10
+	maxJoystickButtons = 1 << 8
+	maxJoystickAxes    = 1 << 8
+)
+
+type eventKind uint8
+
+const (
+	buttonEvent eventKind = iota
+	charEvent
+	mouseMoveEvent
+	mouseScrollEvent
+	mouseEnteredEvent
+)
+
+// inputEvent is a single recorded call to one of the event methods of InputHandler.
+type inputEvent struct {
+	kind    eventKind
+	button  pixel.Button
+	action  pixel.Action
+	char    rune
+	vec     pixel.Vec
+	entered bool
+}
+
+// joystickChange is the complete state of a single joystick, recorded whenever it differs from the
+// previous frame.
+type joystickChange struct {
+	joystick  pixel.Joystick
+	connected bool
+	name      string
+	buttons   []pixel.Action
+	axis      []float32
+}
+
+// inputSnapshot is the input state when a recording starts, restored before replaying it.
+type inputSnapshot struct {
+	mouse   pixel.Vec
+	buttons [pixel.NumButtons]bool
+	inside  bool
+}
+
+// inputFrame holds everything that happened between two calls to InputHandler.Update.
+type inputFrame struct {
+	events    []inputEvent
+	joysticks []joystickChange
+}
+
+// recorder encodes input frames into a writer as they are completed.
+//
+// The format is the magic string and the version, followed by the snapshot of the input state and
+// by frames until the end of the stream. The snapshot is the mouse position, whether the mouse is
+// inside of the window, the number of held buttons and the buttons. A frame is the number of
+// events, the events, the number of joystick changes and the changes. Integers are varints, floats
+// are little endian.
+type recorder struct {
+	w     *bufio.Writer
+	frame inputFrame
+	joy   JoystickState
+	err   error
+}
+
+func newRecorder(w io.Writer, start inputSnapshot) (*recorder, error) {
+	r := &recorder{w: bufio.NewWriter(w)}
+	r.w.WriteString(recordingMagic)
+	r.w.WriteByte(recordingVersion)
+
+	writeFloat64(r.w, start.mouse.X)
+	writeFloat64(r.w, start.mouse.Y)
+	writeBool(r.w, start.inside)
+	var held []int
+	for button, down := range start.buttons {
+		if down {
+			held = append(held, button)
+		}
+	}
+	writeUvarint(r.w, uint64(len(held)))
+	for _, button := range held {
+		writeVarint(r.w, int64(button))
+	}
+	return r, r.w.Flush()
+}
+
+func (r *recorder) record(ev inputEvent) {
+	r.frame.events = append(r.frame.events, ev)
+}
+
+// endFrame records the joysticks that changed since the last frame and writes the frame.
+func (r *recorder) endFrame(joy *JoystickState) {
+	for js := range joy.Connected {
+		if !joystickEqual(&r.joy, joy, js) {
+			r.frame.joysticks = append(r.frame.joysticks, joystickChange{
+				joystick:  pixel.Joystick(js),
+				connected: joy.Connected[js],
+				name:      joy.Name[js],
+				buttons:   append([]pixel.Action(nil), joy.Buttons[js]...),
+				axis:      append([]float32(nil), joy.Axis[js]...),
+			})
+		}
+	}
+	r.joy = copyJoystickState(joy)
+
+	if r.err == nil {
+		r.err = writeFrame(r.w, &r.frame)
+	}
+	r.frame = inputFrame{}
+}
+
+func (r *recorder) close() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.w.Flush()
+}
+
+// player holds a decoded input recording and hands it out frame by frame.
+type player struct {
+	start  inputSnapshot
+	frames []inputFrame
+	joy    JoystickState
+}
+
+func newPlayer(rd io.Reader) (*player, error) {
+	r := bufio.NewReader(rd)
+
+	header := make([]byte, len(recordingMagic)+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("reading input recording header: %w", err)
+	}
+	if string(header[:len(recordingMagic)]) != recordingMagic {
+		return nil, errors.New("not an input recording")
+	}
+	if header[len(recordingMagic)] != recordingVersion {
+		return nil, fmt.Errorf("unsupported input recording version %d", header[len(recordingMagic)])
+	}
+
+	p := &player{}
+	var err error
+	if p.start, err = readSnapshot(r); err != nil {
+		return nil, fmt.Errorf("reading input recording state: %w", noEOF(err))
+	}
+	for {
+		if _, err := r.Peek(1); err == io.EOF {
+			break
+		}
+		frame, err := readFrame(r)
+		if err != nil {
+			return nil, fmt.Errorf("reading input recording frame %d: %w", len(p.frames), err)
+		}
+		p.frames = append(p.frames, frame)
+	}
+	return p, nil
+}
+
+// next returns the events of the next frame and applies its joystick changes to the replayed
+// joystick state. It returns false when the recording is over.
+func (p *player) next() ([]inputEvent, bool) {
+	if len(p.frames) == 0 {
+		return nil, false
+	}
+	frame := p.frames[0]
+	p.frames = p.frames[1:]
+
+	for _, c := range frame.joysticks {
+		p.joy.Connected[c.joystick] = c.connected
+		p.joy.Name[c.joystick] = c.name
+		p.joy.Buttons[c.joystick] = c.buttons
+		p.joy.Axis[c.joystick] = c.axis
+	}
+	return frame.events, true
+}
+
+func writeFrame(w *bufio.Writer, f *inputFrame) error {
+	writeUvarint(w, uint64(len(f.events)))
+	for _, ev := range f.events {
+		w.WriteByte(byte(ev.kind))
+		switch ev.kind {
+		case buttonEvent:
+			writeVarint(w, int64(ev.button))
+			writeVarint(w, int64(ev.action))
+		case charEvent:
+			writeVarint(w, int64(ev.char))
+		case mouseMoveEvent, mouseScrollEvent:
+			writeFloat64(w, ev.vec.X)
+			writeFloat64(w, ev.vec.Y)
+		case mouseEnteredEvent:
+			writeBool(w, ev.entered)
+		}
+	}
+
+	writeUvarint(w, uint64(len(f.joysticks)))
+	for _, c := range f.joysticks {
+		writeVarint(w, int64(c.joystick))
+		writeBool(w, c.connected)
+		writeUvarint(w, uint64(len(c.name)))
+		w.WriteString(c.name)
+		writeUvarint(w, uint64(len(c.buttons)))
+		for _, a := range c.buttons {
+			writeVarint(w, int64(a))
+		}
+		writeUvarint(w, uint64(len(c.axis)))
+		for _, a := range c.axis {
+			binary.Write(w, binary.LittleEndian, a)
+		}
+	}
+
+	return w.Flush()
+}
+
+func readFrame(r *bufio.Reader) (f inputFrame, err error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return f, err
+	}
+	for i := uint64(0); i < n; i++ {
+		var ev inputEvent
+		kind, err := r.ReadByte()
+		if err != nil {
+			return f, noEOF(err)
+		}
+		ev.kind = eventKind(kind)
+		switch ev.kind {
+		case buttonEvent:
+			var button, action int64
+			if button, err = binary.ReadVarint(r); err == nil {
+				action, err = binary.ReadVarint(r)
+			}
+			if button < 0 || button >= int64(pixel.NumButtons) {
+				return f, fmt.Errorf("invalid button %d", button)
+			}
+			ev.button, ev.action = pixel.Button(button), pixel.Action(action)
+		case charEvent:
+			var char int64
+			char, err = binary.ReadVarint(r)
+			ev.char = rune(char)
+		case mouseMoveEvent, mouseScrollEvent:
+			if ev.vec.X, err = readFloat64(r); err == nil {
+				ev.vec.Y, err = readFloat64(r)
+			}
+		case mouseEnteredEvent:
+			ev.entered, err = readBool(r)
+		default:
+			return f, fmt.Errorf("invalid event kind %d", kind)
+		}
+		if err != nil {
+			return f, noEOF(err)
+		}
+		f.events = append(f.events, ev)
+	}
+
+	n, err = binary.ReadUvarint(r)
+	if err != nil {
+		return f, noEOF(err)
+	}
+	for i := uint64(0); i < n; i++ {
+		c, err := readJoystickChange(r)
+		if err != nil {
+			return f, noEOF(err)
+		}
+		f.joysticks = append(f.joysticks, c)
+	}
+
+	return f, nil
+}
+
+func readSnapshot(r *bufio.Reader) (s inputSnapshot, err error) {
+	if s.mouse.X, err = readFloat64(r); err != nil {
+		return s, err
+	}
+	if s.mouse.Y, err = readFloat64(r); err != nil {
+		return s, err
+	}
+	if s.inside, err = readBool(r); err != nil {
+		return s, err
+	}
+	n, err := readLen(r, int(pixel.NumButtons), "held buttons")
+	if err != nil {
+		return s, err
+	}
+	for i := 0; i < n; i++ {
+		button, err := binary.ReadVarint(r)
+		if err != nil {
+			return s, err
+		}
+		if button < 0 || button >= int64(pixel.NumButtons) {
+			return s, fmt.Errorf("invalid button %d", button)
+		}
+		s.buttons[button] = true
+	}
+	return s, nil
+}
+
+func readJoystickChange(r *bufio.Reader) (c joystickChange, err error) {
+	js, err := binary.ReadVarint(r)
+	if err != nil {
+		return c, err
+	}
+	if js < 0 || js >= int64(pixel.NumJoysticks) {
+		return c, fmt.Errorf("invalid joystick %d", js)
+	}
+	c.joystick = pixel.Joystick(js)
+	if c.connected, err = readBool(r); err != nil {
+		return c, err
+	}
+
+	n, err := readLen(r, maxJoystickName, "joystick name")
+	if err != nil {
+		return c, err
+	}
+	name := make([]byte, n)
+	if _, err := io.ReadFull(r, name); err != nil {
+		return c, err
+	}
+	c.name = string(name)
+
+	if n, err = readLen(r, maxJoystickButtons, "joystick buttons"); err != nil {
+		return c, err
+	}
+	c.buttons = make([]pixel.Action, n)
+	for i := range c.buttons {
+		a, err := binary.ReadVarint(r)
+		if err != nil {
+			return c, err
+		}
+		c.buttons[i] = pixel.Action(a)
+	}
+
+	if n, err = readLen(r, maxJoystickAxes, "joystick axes"); err != nil {
+		return c, err
+	}
+	c.axis = make([]float32, n)
+	if err := binary.Read(r, binary.LittleEndian, c.axis); err != nil {
+		return c, err
+	}
+
+	return c, nil
+}
+
+func writeUvarint(w *bufio.Writer, x uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutUvarint(buf[:], x)])
+}
+
+func writeVarint(w *bufio.Writer, x int64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutVarint(buf[:], x)])
+}
+
+func writeFloat64(w *bufio.Writer, x float64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(x))
+	w.Write(buf[:])
+}
+
+func writeBool(w *bufio.Writer, b bool) {
+	if b {
+		w.WriteByte(1)
+	} else {
+		w.WriteByte(0)
+	}
+}
+
+// readLen reads the length of a list, which must not be longer than max.
+func readLen(r *bufio.Reader, max int, what string) (int, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(max) {
+		return 0, fmt.Errorf("invalid length of %s %d", what, n)
+	}
+	return int(n), nil
+}
+
+func readFloat64(r *bufio.Reader) (float64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(buf[:])), nil
+}
+
+func readBool(r *bufio.Reader) (bool, error) {
+	b, err := r.ReadByte()
+	return b != 0, err
+}
+
+// noEOF turns an EOF in the middle of a frame into an unexpected one.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
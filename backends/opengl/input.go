@@ -1,6 +1,7 @@
 package opengl
 
 import (
+	"io"
 	"time"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
@@ -322,6 +323,37 @@ func (w *Window) UpdateInputWait(timeout time.Duration) {
 
 // internal input bookkeeping
 func (w *Window) doUpdateInput() {
-	w.input.Update()
 	w.updateJoystickInput()
+	w.input.Update(&w.currJoy)
+}
+
+// RecordInput starts recording every input event and the state of the joysticks, frame by frame,
+// into the writer, until StopRecordingInput is called. The recording can be replayed with
+// ReplayInput.
+func (w *Window) RecordInput(wr io.Writer) error {
+	return w.input.StartRecording(wr)
+}
+
+// StopRecordingInput stops recording the input and reports any error that occurred while writing
+// the recording.
+func (w *Window) StopRecordingInput() error {
+	return w.input.StopRecording()
+}
+
+// ReplayInput replays a recording made with RecordInput, one recorded frame per call to Update,
+// so that Pressed, JustPressed, Typed, MousePosition, JoystickAxis, etc. return exactly what they
+// returned while recording. Live input is ignored until the replay is over or StopReplayingInput
+// is called. Callbacks are not invoked for replayed events.
+func (w *Window) ReplayInput(r io.Reader) error {
+	return w.input.StartReplay(r)
+}
+
+// StopReplayingInput stops replaying the input and resumes handling live input.
+func (w *Window) StopReplayingInput() {
+	w.input.StopReplay()
+}
+
+// ReplayingInput returns whether a recording of input is being replayed.
+func (w *Window) ReplayingInput() bool {
+	return w.input.Replaying()
 }
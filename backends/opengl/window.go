@@ -112,6 +112,8 @@ type Window struct {
 	cursor               *Cursor
 }
 
+var _ pixel.Window = (*Window)(nil)
+
 var currWin *Window
 
 // NewWindow creates a new Window with it's properties specified in the provided config.
@@ -0,0 +1,277 @@
+package software
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Canvas is an off-screen rectangular BasicTarget and Picture at the same time, that you can draw
+// onto. All drawing happens on the CPU, no GPU or display is required.
+//
+// It supports TrianglesPosition, TrianglesColor, TrianglesPicture, TrianglesClipped and
+// PictureColor.
+type Canvas struct {
+	bounds pixel.Rect
+	pixels []uint8
+	stride int
+
+	cmp    pixel.ComposeMethod
+	mat    pixel.Matrix
+	col    pixel.RGBA
+	smooth bool
+
+	sprite *pixel.Sprite
+}
+
+var (
+	_ pixel.ComposeTarget = (*Canvas)(nil)
+	_ pixel.PictureColor  = (*Canvas)(nil)
+)
+
+// NewCanvas creates a new empty, fully transparent Canvas with given bounds.
+func NewCanvas(bounds pixel.Rect) *Canvas {
+	c := &Canvas{
+		mat: pixel.IM,
+		col: pixel.Alpha(1),
+	}
+	c.SetBounds(bounds)
+	return c
+}
+
+// MakeTriangles creates a specialized copy of the supplied Triangles that draws onto this Canvas.
+//
+// TrianglesPosition, TrianglesColor, TrianglesPicture and TrianglesClipped are supported.
+func (c *Canvas) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	td := pixel.MakeTrianglesData(t.Len())
+	td.Update(t)
+	return &canvasTriangles{
+		TrianglesData: td,
+		dst:           c,
+	}
+}
+
+// MakePicture create a specialized copy of the supplied Picture that draws onto this Canvas.
+//
+// PictureColor is supported. Another software Canvas is sampled live, so changes made to it after
+// calling MakePicture are visible when drawing.
+func (c *Canvas) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	switch p := p.(type) {
+	case *canvasPicture:
+		return &canvasPicture{
+			src: p.src,
+			dst: c,
+		}
+	case *Canvas:
+		return &canvasPicture{
+			src: p,
+			dst: c,
+		}
+	}
+	return &canvasPicture{
+		src: pixel.PictureDataFromPicture(p),
+		dst: c,
+	}
+}
+
+// SetMatrix sets a Matrix that every point will be projected by.
+func (c *Canvas) SetMatrix(m pixel.Matrix) {
+	c.mat = m
+}
+
+// SetColorMask sets a color that every color in triangles or a picture will be multiplied by.
+func (c *Canvas) SetColorMask(col color.Color) {
+	if col == nil {
+		c.col = pixel.Alpha(1)
+		return
+	}
+	c.col = pixel.ToRGBA(col)
+}
+
+// SetComposeMethod sets a Porter-Duff composition method to be used in the following draws onto
+// this Canvas.
+func (c *Canvas) SetComposeMethod(cmp pixel.ComposeMethod) {
+	c.cmp = cmp
+}
+
+// SetBounds resizes the Canvas to the new bounds. Old content will be preserved.
+func (c *Canvas) SetBounds(bounds pixel.Rect) {
+	if c.pixels != nil && bounds == c.bounds {
+		return
+	}
+
+	_, _, w, h := intBounds(bounds)
+	if w <= 0 {
+		w = 1
+	}
+	if h <= 0 {
+		h = 1
+	}
+
+	old, oldBounds, oldStride := c.pixels, c.bounds, c.stride
+	c.pixels = make([]uint8, 4*w*h)
+	c.stride = w
+	c.bounds = bounds
+
+	// preserve old content
+	if old != nil {
+		ox, oy, ow, oh := intBounds(oldBounds)
+		for y := 0; y < oh; y++ {
+			for x := 0; x < ow; x++ {
+				dx, dy, ok := c.index(ox+x, oy+y)
+				if !ok {
+					continue
+				}
+				so := (y*oldStride + x) * 4
+				do := (dy*c.stride + dx) * 4
+				copy(c.pixels[do:do+4], old[so:so+4])
+			}
+		}
+	}
+
+	if c.sprite == nil {
+		c.sprite = pixel.NewSprite(nil, pixel.Rect{})
+	}
+	c.sprite.Set(c, c.Bounds())
+}
+
+// Bounds returns the rectangular bounds of the Canvas.
+func (c *Canvas) Bounds() pixel.Rect {
+	return c.bounds
+}
+
+// SetSmooth sets whether stretched Pictures drawn onto this Canvas should be drawn smooth or
+// pixely.
+func (c *Canvas) SetSmooth(smooth bool) {
+	c.smooth = smooth
+}
+
+// Smooth returns whether stretched Pictures drawn onto this Canvas are set to be drawn smooth or
+// pixely.
+func (c *Canvas) Smooth() bool {
+	return c.smooth
+}
+
+// Clear fills the whole Canvas with a single color.
+func (c *Canvas) Clear(color color.Color) {
+	rgba := pixel.ToRGBA(color).Mul(c.col)
+	for i := 0; i < len(c.pixels); i += 4 {
+		c.store(i, rgba)
+	}
+}
+
+// Color returns the color of the pixel over the given position inside the Canvas.
+func (c *Canvas) Color(at pixel.Vec) pixel.RGBA {
+	if !c.bounds.Contains(at) {
+		return pixel.Alpha(0)
+	}
+	x, y, ok := c.index(int(math.Floor(at.X)), int(math.Floor(at.Y)))
+	if !ok {
+		return pixel.Alpha(0)
+	}
+	return c.load((y*c.stride + x) * 4)
+}
+
+// SetPixels replaces the content of the Canvas with the provided pixels. The provided slice must be
+// an alpha-premultiplied RGBA sequence of correct length (4 * width * height), starting with the
+// bottom row.
+func (c *Canvas) SetPixels(pixels []uint8) {
+	if len(pixels) != len(c.pixels) {
+		panic(fmt.Errorf("(%T).SetPixels: invalid pixels length", c))
+	}
+	copy(c.pixels, pixels)
+}
+
+// Pixels returns an alpha-premultiplied RGBA sequence of the content of the Canvas, starting with
+// the bottom row.
+func (c *Canvas) Pixels() []uint8 {
+	pixels := make([]uint8, len(c.pixels))
+	copy(pixels, c.pixels)
+	return pixels
+}
+
+// Image returns the content of the Canvas as an image.RGBA.
+//
+// The resulting image.RGBA's Bounds will be equivalent of the Canvas's Bounds and, as usual for
+// images, its first row is the top one.
+func (c *Canvas) Image() *image.RGBA {
+	bx, by, _, h := intBounds(c.bounds)
+	rgba := image.NewRGBA(image.Rect(bx, by, bx+c.stride, by+h))
+	for y := 0; y < h; y++ {
+		src := c.pixels[(h-1-y)*c.stride*4 : (h-y)*c.stride*4]
+		copy(rgba.Pix[y*rgba.Stride:], src)
+	}
+	return rgba
+}
+
+// Draw draws the content of the Canvas onto another Target, transformed by the given Matrix, just
+// like if it was a Sprite containing the whole Canvas.
+func (c *Canvas) Draw(t pixel.Target, matrix pixel.Matrix) {
+	c.sprite.Draw(t, matrix)
+}
+
+// DrawColorMask draws the content of the Canvas onto another Target, transformed by the given
+// Matrix and multiplied by the given mask, just like if it was a Sprite containing the whole Canvas.
+//
+// If the color mask is nil, a fully opaque white mask will be used causing no effect.
+func (c *Canvas) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.Color) {
+	c.sprite.DrawColorMask(t, matrix, mask)
+}
+
+// index converts absolute integer pixel coordinates into coordinates relative to the bottom-left
+// corner of the Canvas.
+func (c *Canvas) index(x, y int) (ix, iy int, ok bool) {
+	bx, by, _, _ := intBounds(c.bounds)
+	ix, iy = x-bx, y-by
+	ok = ix >= 0 && iy >= 0 && ix < c.stride && iy*c.stride*4 < len(c.pixels)
+	return ix, iy, ok
+}
+
+func (c *Canvas) load(off int) pixel.RGBA {
+	return pixel.RGBA{
+		R: float64(c.pixels[off+0]) / 255,
+		G: float64(c.pixels[off+1]) / 255,
+		B: float64(c.pixels[off+2]) / 255,
+		A: float64(c.pixels[off+3]) / 255,
+	}
+}
+
+func (c *Canvas) store(off int, col pixel.RGBA) {
+	c.pixels[off+0] = toByte(col.R)
+	c.pixels[off+1] = toByte(col.G)
+	c.pixels[off+2] = toByte(col.B)
+	c.pixels[off+3] = toByte(col.A)
+}
+
+type canvasTriangles struct {
+	*pixel.TrianglesData
+	dst *Canvas
+}
+
+func (ct *canvasTriangles) Draw() {
+	ct.dst.rasterize(ct.TrianglesData, nil)
+}
+
+type canvasPicture struct {
+	src pixel.PictureColor
+	dst *Canvas
+}
+
+func (cp *canvasPicture) Bounds() pixel.Rect {
+	return cp.src.Bounds()
+}
+
+func (cp *canvasPicture) Color(at pixel.Vec) pixel.RGBA {
+	return cp.src.Color(at)
+}
+
+func (cp *canvasPicture) Draw(t pixel.TargetTriangles) {
+	ct := t.(*canvasTriangles)
+	if cp.dst != ct.dst {
+		panic(fmt.Errorf("(%T).Draw: TargetTriangles generated by different Canvas", cp))
+	}
+	ct.dst.rasterize(ct.TrianglesData, cp.src)
+}
@@ -0,0 +1,145 @@
+package software_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/gopxl/pixel/v2/ext/imdraw"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanvas_Clear(t *testing.T) {
+	c := software.NewCanvas(pixel.R(0, 0, 4, 4))
+	c.Clear(pixel.RGB(1, 0, 0))
+
+	assert.Equal(t, pixel.RGB(1, 0, 0), c.Color(pixel.V(0, 0)))
+	assert.Equal(t, pixel.RGB(1, 0, 0), c.Color(pixel.V(3.5, 3.5)))
+	assert.Equal(t, pixel.Alpha(0), c.Color(pixel.V(4, 4)))
+}
+
+func TestCanvas_Triangles(t *testing.T) {
+	c := software.NewCanvas(pixel.R(-5, -5, 5, 5))
+
+	imd := imdraw.New(nil)
+	imd.Color = pixel.RGB(0, 1, 0)
+	imd.Push(pixel.V(-2, -2), pixel.V(2, 2))
+	imd.Rectangle(0)
+	imd.Draw(c)
+
+	for y := -5.0; y < 5; y++ {
+		for x := -5.0; x < 5; x++ {
+			want := pixel.Alpha(0)
+			if x >= -2 && x < 2 && y >= -2 && y < 2 {
+				want = pixel.RGB(0, 1, 0)
+			}
+			assert.Equal(t, want, c.Color(pixel.V(x, y)), "pixel (%v, %v)", x, y)
+		}
+	}
+}
+
+func TestCanvas_SharedEdge(t *testing.T) {
+	c := software.NewCanvas(pixel.R(0, 0, 8, 8))
+
+	// two half-transparent triangles sharing a diagonal must not overlap
+	imd := imdraw.New(nil)
+	imd.Color = pixel.Alpha(0.5)
+	imd.Push(pixel.V(0, 0), pixel.V(8, 0), pixel.V(8, 8))
+	imd.Polygon(0)
+	imd.Push(pixel.V(0, 0), pixel.V(8, 8), pixel.V(0, 8))
+	imd.Polygon(0)
+	imd.Draw(c)
+
+	for y := 0.0; y < 8; y++ {
+		for x := 0.0; x < 8; x++ {
+			assert.InDelta(t, 0.5, c.Color(pixel.V(x, y)).A, 0.01, "pixel (%v, %v)", x, y)
+		}
+	}
+}
+
+func TestCanvas_Sprite(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 2, 2))
+	pic.Pix[pic.Index(pixel.V(0, 0))] = color.RGBA{255, 0, 0, 255}
+	pic.Pix[pic.Index(pixel.V(1, 0))] = color.RGBA{0, 255, 0, 255}
+	pic.Pix[pic.Index(pixel.V(0, 1))] = color.RGBA{0, 0, 255, 255}
+	pic.Pix[pic.Index(pixel.V(1, 1))] = color.RGBA{255, 255, 255, 255}
+
+	c := software.NewCanvas(pixel.R(0, 0, 4, 4))
+	sprite := pixel.NewSprite(pic, pic.Bounds())
+	sprite.Draw(c, pixel.IM.Scaled(pixel.ZV, 2).Moved(c.Bounds().Center()))
+
+	assert.Equal(t, pixel.RGB(1, 0, 0), c.Color(pixel.V(0, 0)))
+	assert.Equal(t, pixel.RGB(1, 0, 0), c.Color(pixel.V(1, 1)))
+	assert.Equal(t, pixel.RGB(0, 1, 0), c.Color(pixel.V(3, 0)))
+	assert.Equal(t, pixel.RGB(0, 0, 1), c.Color(pixel.V(0, 3)))
+	assert.Equal(t, pixel.RGB(1, 1, 1), c.Color(pixel.V(3, 3)))
+
+	// the top row of the image is the top row of the Canvas
+	img := c.Image()
+	assert.Equal(t, color.RGBA{0, 0, 255, 255}, img.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, img.RGBAAt(0, 3))
+}
+
+func TestCanvas_ColorMaskAndCompose(t *testing.T) {
+	c := software.NewCanvas(pixel.R(0, 0, 2, 2))
+	c.Clear(pixel.RGB(0, 0, 1))
+
+	imd := imdraw.New(nil)
+	imd.Color = pixel.RGB(1, 1, 1)
+	imd.Push(pixel.V(0, 0), pixel.V(2, 2))
+	imd.Rectangle(0)
+
+	c.SetColorMask(pixel.RGB(1, 0, 0))
+	c.SetComposeMethod(pixel.ComposePlus)
+	imd.Draw(c)
+
+	assert.Equal(t, pixel.RGB(1, 0, 1), c.Color(pixel.V(1, 1)))
+
+	c.SetColorMask(nil)
+	c.SetComposeMethod(pixel.ComposeCopy)
+	c.SetMatrix(pixel.IM.Moved(pixel.V(1, 0)))
+	imd.Draw(c)
+
+	assert.Equal(t, pixel.RGB(1, 0, 1), c.Color(pixel.V(0, 1)))
+	assert.Equal(t, pixel.RGB(1, 1, 1), c.Color(pixel.V(1, 1)))
+}
+
+func TestCanvas_Clipped(t *testing.T) {
+	c := software.NewCanvas(pixel.R(0, 0, 4, 4))
+
+	tri := pixel.MakeTrianglesData(6)
+	for i, v := range []pixel.Vec{pixel.V(0, 0), pixel.V(4, 0), pixel.V(4, 4), pixel.V(0, 0), pixel.V(4, 4), pixel.V(0, 4)} {
+		(*tri)[i].Position = v
+		(*tri)[i].ClipRect = pixel.R(0, 0, 2, 4)
+		(*tri)[i].IsClipped = true
+	}
+	c.MakeTriangles(tri).Draw()
+
+	assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(1, 1)))
+	assert.Equal(t, pixel.Alpha(0), c.Color(pixel.V(3, 1)))
+}
+
+func TestCanvas_SetBounds(t *testing.T) {
+	c := software.NewCanvas(pixel.R(0, 0, 2, 2))
+	c.Clear(pixel.RGB(1, 0, 0))
+	c.SetBounds(pixel.R(1, 1, 4, 4))
+
+	assert.Equal(t, pixel.RGB(1, 0, 0), c.Color(pixel.V(1, 1)))
+	assert.Equal(t, pixel.Alpha(0), c.Color(pixel.V(2, 2)))
+	assert.Len(t, c.Pixels(), 4*3*3)
+}
+
+func TestCanvas_DrawCanvas(t *testing.T) {
+	src := software.NewCanvas(pixel.R(0, 0, 2, 2))
+	dst := software.NewCanvas(pixel.R(0, 0, 2, 2))
+
+	src.Clear(pixel.RGB(1, 0, 0))
+	src.Draw(dst, pixel.IM.Moved(dst.Bounds().Center()))
+	assert.Equal(t, pixel.RGB(1, 0, 0), dst.Color(pixel.V(0, 0)))
+
+	// the source Canvas is sampled live
+	src.Clear(pixel.RGB(0, 1, 0))
+	src.Draw(dst, pixel.IM.Moved(dst.Bounds().Center()))
+	assert.Equal(t, pixel.RGB(0, 1, 0), dst.Color(pixel.V(0, 0)))
+}
@@ -0,0 +1,5 @@
+// Package software implements a pure-Go, CPU-only Target for the Pixel game development library.
+//
+// Its Canvas rasterizes Triangles into memory without requiring a GPU or a display, which makes it
+// suitable for unit tests, server-side rendering and thumbnails.
+package software
@@ -0,0 +1,142 @@
+package software
+
+import (
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// rasterize draws every triangle of td onto the Canvas. If pic is not nil, it is sampled using the
+// Picture property of the vertices.
+func (c *Canvas) rasterize(td *pixel.TrianglesData, pic pixel.PictureColor) {
+	for i := 0; i+2 < td.Len(); i += 3 {
+		c.triangle((*td)[i:i+3], pic)
+	}
+}
+
+// triangle rasterizes a single triangle by evaluating its edge functions at the center of every
+// pixel within its bounding box. Pixel centers lying exactly on an edge are only covered by the
+// triangle if that edge is a top or a left one, so that adjacent triangles never cover a pixel
+// twice.
+func (c *Canvas) triangle(v pixel.TrianglesData, pic pixel.PictureColor) {
+	p0 := c.mat.Project(v[0].Position)
+	p1 := c.mat.Project(v[1].Position)
+	p2 := c.mat.Project(v[2].Position)
+
+	area := edge(p0, p1, p2)
+	if area == 0 || math.IsNaN(area) {
+		return
+	}
+	// make the triangle counter-clockwise
+	if area < 0 {
+		p1, p2 = p2, p1
+		v = pixel.TrianglesData{v[0], v[2], v[1]}
+		area = -area
+	}
+
+	bx, by, bw, bh := intBounds(c.bounds)
+	minX := max(int(math.Floor(math.Min(p0.X, math.Min(p1.X, p2.X)))), bx)
+	minY := max(int(math.Floor(math.Min(p0.Y, math.Min(p1.Y, p2.Y)))), by)
+	maxX := min(int(math.Ceil(math.Max(p0.X, math.Max(p1.X, p2.X)))), bx+bw)
+	maxY := min(int(math.Ceil(math.Max(p0.Y, math.Max(p1.Y, p2.Y)))), by+bh)
+
+	tl0, tl1, tl2 := isTopLeft(p1, p2), isTopLeft(p2, p0), isTopLeft(p0, p1)
+
+	// clipping rectangles are given in pixels relative to the bottom-left corner of the Canvas
+	clip, clipped := v[0].ClipRect, v[0].IsClipped
+
+	for y := minY; y < maxY; y++ {
+		for x := minX; x < maxX; x++ {
+			p := pixel.V(float64(x)+0.5, float64(y)+0.5)
+
+			w0, w1, w2 := edge(p1, p2, p), edge(p2, p0, p), edge(p0, p1, p)
+			if !covers(w0, tl0) || !covers(w1, tl1) || !covers(w2, tl2) {
+				continue
+			}
+
+			if clipped {
+				fc := pixel.V(p.X-float64(bx), p.Y-float64(by))
+				if fc.X < clip.Min.X || fc.Y < clip.Min.Y || fc.X > clip.Max.X || fc.Y > clip.Max.Y {
+					continue
+				}
+			}
+
+			l0, l1, l2 := w0/area, w1/area, w2/area
+			col := v[0].Color.Scaled(l0).Add(v[1].Color.Scaled(l1)).Add(v[2].Color.Scaled(l2))
+
+			if pic != nil {
+				intensity := v[0].Intensity*l0 + v[1].Intensity*l1 + v[2].Intensity*l2
+				if intensity != 0 {
+					at := v[0].Picture.Scaled(l0).Add(v[1].Picture.Scaled(l1)).Add(v[2].Picture.Scaled(l2))
+					tex := c.sample(pic, at)
+					col = col.Scaled(1 - intensity).Add(col.Mul(tex).Scaled(intensity))
+				}
+			}
+
+			col = col.Mul(c.col)
+
+			off := ((y-by)*c.stride + (x - bx)) * 4
+			c.store(off, c.cmp.Compose(col, c.load(off)))
+		}
+	}
+}
+
+// sample returns the color of the Picture at the given position, either of the nearest pixel, or
+// bilinearly interpolated from the four nearest pixels if the Canvas is smooth.
+func (c *Canvas) sample(pic pixel.PictureColor, at pixel.Vec) pixel.RGBA {
+	bounds := pic.Bounds()
+	if !c.smooth {
+		return pic.Color(clampInto(bounds, at))
+	}
+
+	u, v := at.X-0.5, at.Y-0.5
+	x0, y0 := math.Floor(u), math.Floor(v)
+	fx, fy := u-x0, v-y0
+
+	c00 := pic.Color(clampInto(bounds, pixel.V(x0, y0)))
+	c10 := pic.Color(clampInto(bounds, pixel.V(x0+1, y0)))
+	c01 := pic.Color(clampInto(bounds, pixel.V(x0, y0+1)))
+	c11 := pic.Color(clampInto(bounds, pixel.V(x0+1, y0+1)))
+
+	bottom := c00.Scaled(1 - fx).Add(c10.Scaled(fx))
+	top := c01.Scaled(1 - fx).Add(c11.Scaled(fx))
+	return bottom.Scaled(1 - fy).Add(top.Scaled(fy))
+}
+
+// clampInto clamps the position into the pixels covered by the rectangle, the same way a texture
+// is clamped to its edges.
+func clampInto(r pixel.Rect, u pixel.Vec) pixel.Vec {
+	return pixel.V(
+		pixel.Clamp(u.X, r.Min.X, math.Nextafter(r.Max.X, r.Min.X)),
+		pixel.Clamp(u.Y, r.Min.Y, math.Nextafter(r.Max.Y, r.Min.Y)),
+	)
+}
+
+// edge returns the doubled signed area of the triangle a, b, p. It's positive if p lies to the left
+// of the directed line from a to b.
+func edge(a, b, p pixel.Vec) float64 {
+	return (b.X-a.X)*(p.Y-a.Y) - (b.Y-a.Y)*(p.X-a.X)
+}
+
+// isTopLeft reports whether the edge from a to b of a counter-clockwise triangle is a top or a left
+// edge.
+func isTopLeft(a, b pixel.Vec) bool {
+	d := b.Sub(a)
+	return d.Y < 0 || (d.Y == 0 && d.X < 0)
+}
+
+func covers(w float64, topLeft bool) bool {
+	return w > 0 || (w == 0 && topLeft)
+}
+
+func toByte(x float64) uint8 {
+	return uint8(pixel.Clamp(x, 0, 1)*255 + 0.5)
+}
+
+func intBounds(bounds pixel.Rect) (x, y, w, h int) {
+	x0 := int(math.Floor(bounds.Min.X))
+	y0 := int(math.Floor(bounds.Min.Y))
+	x1 := int(math.Ceil(bounds.Max.X))
+	y1 := int(math.Ceil(bounds.Max.Y))
+	return x0, y0, x1 - x0, y1 - y0
+}
@@ -0,0 +1,126 @@
+package actions
+
+import (
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+type action struct {
+	bindings []Binding
+	value    float64
+	pressed  bool
+	prev     bool
+}
+
+// Map maps named actions, such as "jump" or "move_x", to the bindings that trigger them.
+//
+// Call Update once per frame, after the window has been updated, to refresh the state of the
+// actions. The state of an action is the state of its strongest binding, so an action bound to both
+// a key and a gamepad axis works with either.
+//
+// The zero value is an empty Map ready to use.
+type Map struct {
+	actions map[string]*action
+	names   []string
+}
+
+// NewMap creates an empty Map.
+func NewMap() *Map {
+	return &Map{}
+}
+
+// Bind adds the bindings to the action, creating the action if it doesn't exist yet.
+func (m *Map) Bind(name string, bindings ...Binding) {
+	a := m.action(name)
+	a.bindings = append(a.bindings, bindings...)
+}
+
+// Unbind removes the action and all of its bindings.
+func (m *Map) Unbind(name string) {
+	if _, ok := m.actions[name]; !ok {
+		return
+	}
+	delete(m.actions, name)
+	for i, n := range m.names {
+		if n == name {
+			m.names = append(m.names[:i], m.names[i+1:]...)
+			break
+		}
+	}
+}
+
+// Rebind replaces all of the bindings of the action.
+func (m *Map) Rebind(name string, bindings ...Binding) {
+	a := m.action(name)
+	a.bindings = append([]Binding(nil), bindings...)
+}
+
+// Bindings returns the bindings of the action.
+func (m *Map) Bindings(name string) []Binding {
+	a, ok := m.actions[name]
+	if !ok {
+		return nil
+	}
+	return append([]Binding(nil), a.bindings...)
+}
+
+// Actions returns the names of all actions in the order they were first bound.
+func (m *Map) Actions() []string {
+	return append([]string(nil), m.names...)
+}
+
+// Update refreshes the state of all actions from the input.
+func (m *Map) Update(in pixel.WindowInput) {
+	for _, a := range m.actions {
+		a.value = 0
+		for _, b := range a.bindings {
+			if v := b.Value(in); math.Abs(v) > math.Abs(a.value) {
+				a.value = v
+			}
+		}
+		a.prev = a.pressed
+		a.pressed = a.value != 0
+	}
+}
+
+// Pressed returns whether any binding of the action is active.
+func (m *Map) Pressed(name string) bool {
+	a, ok := m.actions[name]
+	return ok && a.pressed
+}
+
+// JustPressed returns whether the action became active in the last frame.
+func (m *Map) JustPressed(name string) bool {
+	a, ok := m.actions[name]
+	return ok && a.pressed && !a.prev
+}
+
+// JustReleased returns whether the action stopped being active in the last frame.
+func (m *Map) JustReleased(name string) bool {
+	a, ok := m.actions[name]
+	return ok && !a.pressed && a.prev
+}
+
+// Value returns the value of the action in range [-1, 1]. For buttons it is 0 or 1, for axes and
+// composites it is the signed position of the axis.
+func (m *Map) Value(name string) float64 {
+	a, ok := m.actions[name]
+	if !ok {
+		return 0
+	}
+	return a.value
+}
+
+func (m *Map) action(name string) *action {
+	if m.actions == nil {
+		m.actions = make(map[string]*action)
+	}
+	a, ok := m.actions[name]
+	if !ok {
+		a = &action{}
+		m.actions[name] = a
+		m.names = append(m.names, name)
+	}
+	return a
+}
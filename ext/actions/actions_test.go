@@ -0,0 +1,158 @@
+package actions_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/headless"
+	"github.com/gopxl/pixel/v2/ext/actions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWindow(t *testing.T) *headless.Window {
+	win, err := headless.NewWindow(headless.WindowConfig{Bounds: pixel.R(0, 0, 10, 10)})
+	require.NoError(t, err)
+	return win
+}
+
+func TestMap_Buttons(t *testing.T) {
+	win := newWindow(t)
+	m := actions.NewMap()
+	m.Bind("jump",
+		actions.Button{Button: pixel.KeySpace},
+		actions.GamepadButton{Joystick: pixel.Joystick1, Button: pixel.GamepadA},
+	)
+
+	win.ButtonEvent(pixel.KeySpace, pixel.Press)
+	win.Update()
+	m.Update(win)
+	assert.True(t, m.Pressed("jump"))
+	assert.True(t, m.JustPressed("jump"))
+	assert.Equal(t, 1.0, m.Value("jump"))
+
+	win.ButtonEvent(pixel.KeySpace, pixel.Release)
+	win.JoystickConnectEvent(pixel.Joystick1, "pad")
+	win.JoystickButtonEvent(pixel.Joystick1, pixel.GamepadA, pixel.Press)
+	win.Update()
+	m.Update(win)
+	assert.True(t, m.Pressed("jump"), "held by the gamepad")
+	assert.False(t, m.JustPressed("jump"))
+
+	win.JoystickButtonEvent(pixel.Joystick1, pixel.GamepadA, pixel.Release)
+	win.Update()
+	m.Update(win)
+	assert.False(t, m.Pressed("jump"))
+	assert.True(t, m.JustReleased("jump"))
+
+	assert.False(t, m.Pressed("unknown"))
+	assert.Equal(t, 0.0, m.Value("unknown"))
+}
+
+func TestMap_Chord(t *testing.T) {
+	win := newWindow(t)
+	m := actions.NewMap()
+	m.Bind("save", actions.Button{Button: pixel.KeyS, Modifiers: []pixel.Button{pixel.KeyLeftControl}})
+
+	win.ButtonEvent(pixel.KeyS, pixel.Press)
+	win.Update()
+	m.Update(win)
+	assert.False(t, m.Pressed("save"))
+
+	win.ButtonEvent(pixel.KeyLeftControl, pixel.Press)
+	win.Update()
+	m.Update(win)
+	assert.True(t, m.JustPressed("save"))
+}
+
+func TestMap_Axes(t *testing.T) {
+	win := newWindow(t)
+	m := actions.NewMap()
+	m.Bind("move_x",
+		actions.Composite{
+			Negative: actions.Button{Button: pixel.KeyA},
+			Positive: actions.Button{Button: pixel.KeyD},
+		},
+		actions.GamepadAxis{Joystick: pixel.Joystick1, Axis: pixel.AxisLeftX, Deadzone: 0.2},
+	)
+
+	win.ButtonEvent(pixel.KeyA, pixel.Press)
+	win.Update()
+	m.Update(win)
+	assert.Equal(t, -1.0, m.Value("move_x"))
+
+	win.ButtonEvent(pixel.KeyD, pixel.Press)
+	win.Update()
+	m.Update(win)
+	assert.Equal(t, 0.0, m.Value("move_x"), "opposite keys cancel out")
+	assert.True(t, m.JustReleased("move_x"))
+
+	win.ButtonEvent(pixel.KeyA, pixel.Release)
+	win.ButtonEvent(pixel.KeyD, pixel.Release)
+	win.JoystickConnectEvent(pixel.Joystick1, "pad")
+	win.JoystickAxisEvent(pixel.Joystick1, pixel.AxisLeftX, 0.1)
+	win.Update()
+	m.Update(win)
+	assert.Equal(t, 0.0, m.Value("move_x"), "within the deadzone")
+	assert.False(t, m.Pressed("move_x"))
+
+	win.JoystickAxisEvent(pixel.Joystick1, pixel.AxisLeftX, 0.6)
+	win.Update()
+	m.Update(win)
+	assert.InDelta(t, 0.5, m.Value("move_x"), 1e-6)
+	assert.True(t, m.JustPressed("move_x"))
+
+	win.JoystickAxisEvent(pixel.Joystick1, pixel.AxisLeftX, -1)
+	win.Update()
+	m.Update(win)
+	assert.Equal(t, -1.0, m.Value("move_x"))
+}
+
+func TestMap_JSON(t *testing.T) {
+	m := actions.NewMap()
+	m.Bind("move_x",
+		actions.Composite{
+			Negative: actions.Button{Button: pixel.KeyA},
+			Positive: actions.Button{Button: pixel.KeyD},
+		},
+		actions.GamepadAxis{Joystick: pixel.Joystick2, Axis: pixel.AxisLeftX, Deadzone: 0.25, Inverted: true},
+	)
+	m.Bind("jump", actions.GamepadButton{Joystick: pixel.Joystick1, Button: pixel.GamepadA})
+	m.Bind("save", actions.Button{Button: pixel.KeyS, Modifiers: []pixel.Button{pixel.KeyLeftControl}})
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"move_x": [
+			{"negative": {"button": "A"}, "positive": {"button": "D"}},
+			{"joystick": "Joystick2", "axis": "AxisLeftX", "deadzone": 0.25, "inverted": true}
+		],
+		"jump": [{"joystick": "Joystick1", "gamepadButton": "GamepadA"}],
+		"save": [{"button": "S", "modifiers": ["LeftControl"]}]
+	}`, string(data))
+
+	var decoded actions.Map
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, []string{"move_x", "jump", "save"}, decoded.Actions())
+	for _, name := range m.Actions() {
+		assert.Equal(t, m.Bindings(name), decoded.Bindings(name), name)
+	}
+
+	assert.Error(t, json.Unmarshal([]byte(`{"jump": [{"button": "NoSuchKey"}]}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"jump": [{}]}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`[]`), &decoded))
+	assert.Equal(t, []string{"move_x", "jump", "save"}, decoded.Actions(), "unchanged on error")
+}
+
+func TestMap_Rebind(t *testing.T) {
+	m := actions.NewMap()
+	m.Bind("jump", actions.Button{Button: pixel.KeySpace})
+	m.Bind("fire", actions.Button{Button: pixel.MouseButtonLeft})
+	m.Rebind("jump", actions.Button{Button: pixel.KeyW})
+	assert.Equal(t, []actions.Binding{actions.Button{Button: pixel.KeyW}}, m.Bindings("jump"))
+
+	m.Unbind("jump")
+	assert.Nil(t, m.Bindings("jump"))
+	assert.Equal(t, []string{"fire"}, m.Actions())
+}
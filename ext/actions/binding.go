@@ -0,0 +1,95 @@
+package actions
+
+import (
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Binding is a single physical input that can trigger an action. Its Value is 0 when the input is
+// idle, 1 (or -1) when a button is pressed, and anything in between for analog inputs.
+//
+// Only the Binding types of this package can be serialized to JSON.
+type Binding interface {
+	Value(in pixel.WindowInput) float64
+}
+
+// Button binds a keyboard key or a mouse button. If Modifiers are set, the binding is a chord and
+// is only active while all of the modifiers are held down as well, e.g. Ctrl+S:
+//
+//	actions.Button{Button: pixel.KeyS, Modifiers: []pixel.Button{pixel.KeyLeftControl}}
+type Button struct {
+	Button    pixel.Button
+	Modifiers []pixel.Button
+}
+
+// Value returns 1 if the button and all of the modifiers are pressed, 0 otherwise.
+func (b Button) Value(in pixel.WindowInput) float64 {
+	for _, m := range b.Modifiers {
+		if !in.Pressed(m) {
+			return 0
+		}
+	}
+	if !in.Pressed(b.Button) {
+		return 0
+	}
+	return 1
+}
+
+// GamepadButton binds a button of a gamepad.
+type GamepadButton struct {
+	Joystick pixel.Joystick
+	Button   pixel.GamepadButton
+}
+
+// Value returns 1 if the gamepad button is pressed, 0 otherwise.
+func (b GamepadButton) Value(in pixel.WindowInput) float64 {
+	if !in.JoystickPressed(b.Joystick, b.Button) {
+		return 0
+	}
+	return 1
+}
+
+// GamepadAxis binds an axis of a gamepad. Values within the Deadzone around the center of the axis
+// are reported as 0 and the rest of the range is rescaled, so that the Value still goes smoothly
+// from 0 to 1 (or -1). Inverted flips the sign of the axis.
+type GamepadAxis struct {
+	Joystick pixel.Joystick
+	Axis     pixel.GamepadAxis
+	Deadzone float64
+	Inverted bool
+}
+
+// Value returns the position of the axis with the deadzone applied, in range [-1, 1].
+func (a GamepadAxis) Value(in pixel.WindowInput) float64 {
+	v := pixel.Clamp(in.JoystickAxis(a.Joystick, a.Axis), -1, 1)
+	if a.Inverted {
+		v = -v
+	}
+	if math.Abs(v) <= a.Deadzone {
+		return 0
+	}
+	if a.Deadzone >= 1 {
+		return math.Copysign(1, v)
+	}
+	return math.Copysign((math.Abs(v)-a.Deadzone)/(1-a.Deadzone), v)
+}
+
+// Composite builds an axis from two bindings, typically two keys, e.g. A and D for the horizontal
+// movement. Its Value is the Value of Positive minus the Value of Negative.
+type Composite struct {
+	Negative Binding
+	Positive Binding
+}
+
+// Value returns the difference of the Values of Positive and Negative, in range [-1, 1].
+func (c Composite) Value(in pixel.WindowInput) float64 {
+	var v float64
+	if c.Positive != nil {
+		v += c.Positive.Value(in)
+	}
+	if c.Negative != nil {
+		v -= c.Negative.Value(in)
+	}
+	return pixel.Clamp(v, -1, 1)
+}
@@ -0,0 +1,225 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// bindingJSON is the serialized form of the Binding types of this package. The kind of the binding
+// is inferred from the fields that are set.
+type bindingJSON struct {
+	Button        string       `json:"button,omitempty"`
+	Modifiers     []string     `json:"modifiers,omitempty"`
+	Joystick      string       `json:"joystick,omitempty"`
+	GamepadButton string       `json:"gamepadButton,omitempty"`
+	Axis          string       `json:"axis,omitempty"`
+	Deadzone      float64      `json:"deadzone,omitempty"`
+	Inverted      bool         `json:"inverted,omitempty"`
+	Negative      *bindingJSON `json:"negative,omitempty"`
+	Positive      *bindingJSON `json:"positive,omitempty"`
+}
+
+// MarshalJSON encodes the Map as a JSON object of action names to lists of bindings, in the order
+// the actions were bound. Buttons, joysticks and axes are stored by name, e.g.
+//
+//	{"jump": [{"button": "Space"}, {"joystick": "Joystick1", "gamepadButton": "GamepadA"}]}
+func (m *Map) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range m.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		bindings := make([]*bindingJSON, len(m.actions[name].bindings))
+		for j, b := range m.actions[name].bindings {
+			bj, err := encodeBinding(b)
+			if err != nil {
+				return nil, fmt.Errorf("action %q: %w", name, err)
+			}
+			bindings[j] = bj
+		}
+		key, _ := json.Marshal(name)
+		value, err := json.Marshal(bindings)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes the bindings encoded by MarshalJSON, replacing all actions of the Map.
+func (m *Map) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return errors.New("actions: expected a JSON object")
+	}
+
+	var decoded Map
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		name := tok.(string)
+		var bindings []*bindingJSON
+		if err := dec.Decode(&bindings); err != nil {
+			return fmt.Errorf("actions: action %q: %w", name, err)
+		}
+		a := decoded.action(name)
+		for _, bj := range bindings {
+			b, err := decodeBinding(bj)
+			if err != nil {
+				return fmt.Errorf("actions: action %q: %w", name, err)
+			}
+			a.bindings = append(a.bindings, b)
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	*m = decoded
+	return nil
+}
+
+func encodeBinding(b Binding) (*bindingJSON, error) {
+	switch b := b.(type) {
+	case Button:
+		bj := &bindingJSON{Button: b.Button.String()}
+		for _, mod := range b.Modifiers {
+			bj.Modifiers = append(bj.Modifiers, mod.String())
+		}
+		return bj, nil
+	case GamepadButton:
+		return &bindingJSON{Joystick: b.Joystick.String(), GamepadButton: b.Button.String()}, nil
+	case GamepadAxis:
+		return &bindingJSON{
+			Joystick: b.Joystick.String(),
+			Axis:     b.Axis.String(),
+			Deadzone: b.Deadzone,
+			Inverted: b.Inverted,
+		}, nil
+	case Composite:
+		var (
+			bj  bindingJSON
+			err error
+		)
+		if b.Negative != nil {
+			if bj.Negative, err = encodeBinding(b.Negative); err != nil {
+				return nil, err
+			}
+		}
+		if b.Positive != nil {
+			if bj.Positive, err = encodeBinding(b.Positive); err != nil {
+				return nil, err
+			}
+		}
+		return &bj, nil
+	default:
+		return nil, fmt.Errorf("binding of type %T can't be serialized", b)
+	}
+}
+
+func decodeBinding(bj *bindingJSON) (Binding, error) {
+	if bj == nil {
+		return nil, errors.New("null binding")
+	}
+	switch {
+	case bj.Button != "":
+		var (
+			b   Button
+			err error
+		)
+		if b.Button, err = parseButton(bj.Button); err != nil {
+			return nil, err
+		}
+		for _, name := range bj.Modifiers {
+			mod, err := parseButton(name)
+			if err != nil {
+				return nil, err
+			}
+			b.Modifiers = append(b.Modifiers, mod)
+		}
+		return b, nil
+	case bj.GamepadButton != "":
+		js, err := parseJoystick(bj.Joystick)
+		if err != nil {
+			return nil, err
+		}
+		gb, err := parseName("gamepad button", bj.GamepadButton, pixel.NumGamepadButtons, func(i int) string {
+			return pixel.GamepadButton(i).String()
+		})
+		if err != nil {
+			return nil, err
+		}
+		return GamepadButton{Joystick: js, Button: pixel.GamepadButton(gb)}, nil
+	case bj.Axis != "":
+		js, err := parseJoystick(bj.Joystick)
+		if err != nil {
+			return nil, err
+		}
+		axis, err := parseName("gamepad axis", bj.Axis, pixel.NumAxes, func(i int) string {
+			return pixel.GamepadAxis(i).String()
+		})
+		if err != nil {
+			return nil, err
+		}
+		if bj.Deadzone < 0 || bj.Deadzone > 1 {
+			return nil, fmt.Errorf("deadzone %v out of range [0, 1]", bj.Deadzone)
+		}
+		return GamepadAxis{Joystick: js, Axis: pixel.GamepadAxis(axis), Deadzone: bj.Deadzone, Inverted: bj.Inverted}, nil
+	case bj.Negative != nil || bj.Positive != nil:
+		var c Composite
+		if bj.Negative != nil {
+			neg, err := decodeBinding(bj.Negative)
+			if err != nil {
+				return nil, err
+			}
+			c.Negative = neg
+		}
+		if bj.Positive != nil {
+			pos, err := decodeBinding(bj.Positive)
+			if err != nil {
+				return nil, err
+			}
+			c.Positive = pos
+		}
+		return c, nil
+	default:
+		return nil, errors.New("empty binding")
+	}
+}
+
+func parseButton(name string) (pixel.Button, error) {
+	i, err := parseName("button", name, pixel.NumButtons, func(i int) string {
+		return pixel.Button(i).String()
+	})
+	return pixel.Button(i), err
+}
+
+func parseJoystick(name string) (pixel.Joystick, error) {
+	i, err := parseName("joystick", name, pixel.NumJoysticks, func(i int) string {
+		return pixel.Joystick(i).String()
+	})
+	return pixel.Joystick(i), err
+}
+
+// parseName finds the value in range [0, n) whose name is the given one.
+func parseName(kind, name string, n int, nameOf func(int) string) (int, error) {
+	for i := 0; i < n; i++ {
+		if nameOf(i) == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown %s %q", kind, name)
+}
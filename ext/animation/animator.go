@@ -0,0 +1,153 @@
+package animation
+
+import (
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// Event is an event of a frame that was entered during the last call to Animator.Update.
+type Event struct {
+	Name  string
+	Frame int
+}
+
+// Animator plays a Clip. Call Update every frame with the time elapsed since the last one and
+// draw the current frame with Draw.
+type Animator struct {
+	// Speed scales the time passed to Update. NewAnimator sets it to 1.
+	Speed float64
+
+	clip    *Clip
+	frame   int
+	dir     int
+	elapsed time.Duration
+	entered bool
+	done    bool
+	events  []Event
+	sprite  *pixel.Sprite
+}
+
+// NewAnimator creates an Animator playing the clip from the start.
+func NewAnimator(clip *Clip) *Animator {
+	a := &Animator{Speed: 1}
+	a.Play(clip)
+	return a
+}
+
+// Play switches to the clip and plays it from the start, unless it's already playing.
+func (a *Animator) Play(clip *Clip) {
+	if a.clip == clip {
+		return
+	}
+	a.clip = clip
+	a.Restart()
+}
+
+// Restart plays the current clip from the start.
+func (a *Animator) Restart() {
+	a.frame = 0
+	a.dir = 1
+	a.elapsed = 0
+	a.entered = false
+	a.done = false
+	a.events = a.events[:0]
+}
+
+// Clip returns the clip being played.
+func (a *Animator) Clip() *Clip {
+	return a.clip
+}
+
+// Frame returns the position of the current frame in the Frames of the clip.
+func (a *Animator) Frame() int {
+	return a.frame
+}
+
+// Done returns whether a clip in the Once mode has reached the end of its last frame.
+func (a *Animator) Done() bool {
+	return a.done
+}
+
+// Events returns the events of the frames entered during the last call to Update, in order.
+func (a *Animator) Events() []Event {
+	return a.events
+}
+
+// Update advances the animation by dt, scaled by Speed. Any number of frames may be skipped if dt
+// is long enough, their events are still reported.
+func (a *Animator) Update(dt time.Duration) {
+	a.events = a.events[:0]
+	if a.clip == nil || len(a.clip.Frames) == 0 {
+		return
+	}
+	if !a.entered {
+		a.entered = true
+		a.enter()
+	}
+	if a.done {
+		return
+	}
+
+	a.elapsed += time.Duration(float64(dt) * a.Speed)
+	if a.clip.Duration() <= 0 {
+		return
+	}
+	for a.elapsed >= a.clip.Frames[a.frame].Duration {
+		a.elapsed -= a.clip.Frames[a.frame].Duration
+		if !a.advance() {
+			a.done = true
+			a.elapsed = 0
+			return
+		}
+		a.enter()
+	}
+}
+
+// advance moves to the next frame according to the mode of the clip. It returns false if the clip
+// is over.
+func (a *Animator) advance() bool {
+	n := len(a.clip.Frames)
+	switch a.clip.Mode {
+	case Once:
+		if a.frame+1 >= n {
+			return false
+		}
+		a.frame++
+	case PingPong:
+		if n == 1 {
+			return true
+		}
+		if next := a.frame + a.dir; next < 0 || next >= n {
+			a.dir = -a.dir
+		}
+		a.frame += a.dir
+	default:
+		a.frame = (a.frame + 1) % n
+	}
+	return true
+}
+
+func (a *Animator) enter() {
+	if name := a.clip.Frames[a.frame].Event; name != "" {
+		a.events = append(a.events, Event{Name: name, Frame: a.frame})
+	}
+}
+
+// Draw draws the current frame to the target with the given matrix, like pixel.Sprite.Draw.
+func (a *Animator) Draw(t pixel.Target, m pixel.Matrix) {
+	if a.clip == nil || len(a.clip.Frames) == 0 {
+		return
+	}
+	f := a.clip.Frames[a.frame]
+	if a.clip.picture == nil {
+		a.clip.slice.Draw(t, m, f.Index)
+		return
+	}
+	if a.sprite == nil {
+		a.sprite = pixel.NewSprite(a.clip.picture, f.Rect)
+	} else {
+		a.sprite.Set(a.clip.picture, f.Rect)
+	}
+	a.sprite.Draw(t, m)
+}
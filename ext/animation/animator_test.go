@@ -0,0 +1,145 @@
+package animation_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/gopxl/pixel/v2/ext/animation"
+	"github.com/gopxl/pixel/v2/ext/atlas"
+	"github.com/stretchr/testify/assert"
+)
+
+func frames(a *animation.Animator, steps int, dt time.Duration) []int {
+	var seen []int
+	for i := 0; i < steps; i++ {
+		a.Update(dt)
+		seen = append(seen, a.Frame())
+	}
+	return seen
+}
+
+func clip(n int, mode animation.Mode) *animation.Clip {
+	pic := pixel.MakePictureData(pixel.R(0, 0, float64(n), 1))
+	var rects []pixel.Rect
+	for i := 0; i < n; i++ {
+		rects = append(rects, pixel.R(float64(i), 0, float64(i+1), 1))
+	}
+	return animation.NewPictureClip(pic, 100*time.Millisecond, mode, rects...)
+}
+
+func TestAnimator_Modes(t *testing.T) {
+	a := animation.NewAnimator(clip(3, animation.Loop))
+	assert.Equal(t, []int{1, 2, 0, 1}, frames(a, 4, 100*time.Millisecond))
+
+	a = animation.NewAnimator(clip(3, animation.PingPong))
+	assert.Equal(t, []int{1, 2, 1, 0, 1, 2}, frames(a, 6, 100*time.Millisecond))
+
+	a = animation.NewAnimator(clip(3, animation.Once))
+	assert.Equal(t, []int{1, 2}, frames(a, 2, 100*time.Millisecond))
+	assert.False(t, a.Done())
+	assert.Equal(t, []int{2, 2}, frames(a, 2, 100*time.Millisecond))
+	assert.True(t, a.Done())
+
+	a.Restart()
+	assert.False(t, a.Done())
+	assert.Equal(t, 0, a.Frame())
+}
+
+func TestAnimator_Durations(t *testing.T) {
+	c := clip(3, animation.Loop)
+	c.Frames[1].Duration = 300 * time.Millisecond
+	assert.Equal(t, 500*time.Millisecond, c.Duration())
+
+	a := animation.NewAnimator(c)
+	assert.Equal(t, []int{1, 1, 1, 2, 0}, frames(a, 5, 100*time.Millisecond))
+
+	// a long frame skips over several frames
+	a.Restart()
+	a.Update(time.Second + 50*time.Millisecond)
+	assert.Equal(t, 0, a.Frame())
+
+	a.Restart()
+	a.Speed = 2
+	a.Update(50 * time.Millisecond)
+	assert.Equal(t, 1, a.Frame())
+}
+
+func TestAnimator_Events(t *testing.T) {
+	c := clip(4, animation.Loop)
+	c.SetEvent(0, "start")
+	c.SetEvent(2, "step")
+
+	a := animation.NewAnimator(c)
+	a.Update(0)
+	assert.Equal(t, []animation.Event{{Name: "start", Frame: 0}}, a.Events())
+
+	a.Update(100 * time.Millisecond)
+	assert.Empty(t, a.Events())
+
+	a.Update(300 * time.Millisecond)
+	assert.Equal(t, []animation.Event{{Name: "step", Frame: 2}, {Name: "start", Frame: 0}}, a.Events())
+
+	// switching to the clip being played doesn't restart it
+	a.Play(c)
+	a.Update(0)
+	assert.Empty(t, a.Events())
+}
+
+func TestAnimator_DrawSlice(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	img.Set(1, 0, color.RGBA{0, 0, 255, 255})
+
+	var textures atlas.Atlas
+	slice := textures.SliceImage(img, pixel.V(1, 1))
+	textures.Pack()
+
+	c := animation.NewSliceClip(slice, time.Second, animation.Loop)
+	assert.Len(t, c.Frames, 2)
+
+	canvas := software.NewCanvas(pixel.R(0, 0, 1, 1))
+	a := animation.NewAnimator(c)
+	a.Draw(canvas, pixel.IM.Moved(canvas.Bounds().Center()))
+	assert.Equal(t, pixel.RGB(1, 0, 0), canvas.Color(pixel.ZV))
+
+	a.Update(time.Second)
+	a.Draw(canvas, pixel.IM.Moved(canvas.Bounds().Center()))
+	assert.Equal(t, pixel.RGB(0, 0, 1), canvas.Color(pixel.ZV))
+}
+
+func TestNewTagClip(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	data := `{
+		"frames": [
+			{"filename": "0", "frame": {"x": 0, "y": 0, "w": 1, "h": 1}, "duration": 10},
+			{"filename": "1", "frame": {"x": 1, "y": 0, "w": 1, "h": 1}, "duration": 20},
+			{"filename": "2", "frame": {"x": 2, "y": 0, "w": 1, "h": 1}, "duration": 30}
+		],
+		"meta": {"frameTags": [
+			{"name": "back", "from": 0, "to": 2, "direction": "reverse"},
+			{"name": "bounce", "from": 1, "to": 2, "direction": "pingpong"}
+		]}
+	}`
+
+	var textures atlas.Atlas
+	sheet := textures.AddSheet([]byte(data), img)
+	textures.Pack()
+
+	back, _ := sheet.Tag("back")
+	c := animation.NewTagClip(back)
+	assert.Equal(t, animation.Loop, c.Mode)
+	assert.Equal(t, []animation.Frame{
+		{Index: 2, Duration: 30 * time.Millisecond},
+		{Index: 1, Duration: 20 * time.Millisecond},
+		{Index: 0, Duration: 10 * time.Millisecond},
+	}, c.Frames)
+
+	bounce, _ := sheet.Tag("bounce")
+	c = animation.NewTagClip(bounce)
+	assert.Equal(t, animation.PingPong, c.Mode)
+	assert.Len(t, c.Frames, 2)
+}
@@ -0,0 +1,119 @@
+package animation
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/atlas"
+)
+
+// Mode determines what happens when a Clip reaches its last frame.
+type Mode int
+
+const (
+	// Loop starts over from the first frame.
+	Loop Mode = iota
+	// PingPong plays the frames backwards down to the first one, then forwards again, and so on.
+	PingPong
+	// Once stops on the last frame.
+	Once
+)
+
+// String returns a human-readable string describing the Mode.
+func (m Mode) String() string {
+	switch m {
+	case Loop:
+		return "Loop"
+	case PingPong:
+		return "PingPong"
+	case Once:
+		return "Once"
+	}
+	return "UnknownMode"
+}
+
+// Frame is a single frame of a Clip.
+type Frame struct {
+	// Index is the frame of the slice for clips made with NewSliceClip.
+	Index uint32
+	// Rect is the part of the picture shown for clips made with NewPictureClip.
+	Rect pixel.Rect
+	// Duration is how long the frame is shown.
+	Duration time.Duration
+	// Event, if not empty, is reported by Animator.Events when the frame is entered.
+	Event string
+}
+
+// Clip is a sequence of frames of a single atlas.SliceId or pixel.Picture.
+//
+// The Frames may be modified freely, e.g. to give individual frames different durations or to
+// attach events to them.
+type Clip struct {
+	Frames []Frame
+	Mode   Mode
+
+	slice   atlas.SliceId
+	picture pixel.Picture
+}
+
+// NewSliceClip creates a Clip showing the given frames of the slice, each for the same duration. If
+// no frames are given, the clip shows all frames of the slice in order.
+func NewSliceClip(slice atlas.SliceId, duration time.Duration, mode Mode, frames ...uint32) *Clip {
+	if len(frames) == 0 {
+		for i := uint32(0); i < slice.Len(); i++ {
+			frames = append(frames, i)
+		}
+	}
+	c := &Clip{Mode: mode, slice: slice}
+	for _, i := range frames {
+		c.Frames = append(c.Frames, Frame{Index: i, Duration: duration})
+	}
+	return c
+}
+
+// NewTagClip creates a Clip from a tag of a sprite sheet exported by Aseprite, with the durations
+// and the direction of the tag.
+func NewTagClip(tag atlas.SheetTag) *Clip {
+	c := &Clip{Mode: Loop, slice: tag.Slice}
+	for i, d := range tag.Durations {
+		c.Frames = append(c.Frames, Frame{Index: uint32(i), Duration: d})
+	}
+	switch tag.Direction {
+	case "pingpong", "pingpong_reverse":
+		c.Mode = PingPong
+	}
+	switch tag.Direction {
+	case "reverse", "pingpong_reverse":
+		slices.Reverse(c.Frames)
+	}
+	return c
+}
+
+// NewPictureClip creates a Clip showing the given parts of the picture, each for the same duration.
+func NewPictureClip(pic pixel.Picture, duration time.Duration, mode Mode, frames ...pixel.Rect) *Clip {
+	c := &Clip{Mode: mode, picture: pic}
+	for _, r := range frames {
+		c.Frames = append(c.Frames, Frame{Rect: r, Duration: duration})
+	}
+	return c
+}
+
+// Picture returns the picture of the Clip, or nil if the Clip was made from an atlas.SliceId.
+func (c *Clip) Picture() pixel.Picture {
+	return c.picture
+}
+
+// Duration returns the time it takes to play all frames of the Clip once.
+func (c *Clip) Duration() time.Duration {
+	var d time.Duration
+	for _, f := range c.Frames {
+		d += f.Duration
+	}
+	return d
+}
+
+// SetEvent sets the event reported when the frame with the given position in Frames is entered.
+func (c *Clip) SetEvent(frame int, event string) {
+	c.Frames[frame].Event = event
+}
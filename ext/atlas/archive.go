@@ -0,0 +1,230 @@
+package atlas
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"sort"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	manifestName    = "manifest.json"
+	manifestVersion = 1
+)
+
+// manifest describes the contents of an atlas archive.
+type manifest struct {
+	Version  int               `json:"version"`
+	NextId   uint32            `json:"nextId"`
+	Options  Options           `json:"options"`
+	Sheets   []string          `json:"sheets"`
+	Free     [][]manifestRect  `json:"free,omitempty"`
+	Textures []manifestTexture `json:"textures"`
+	Slices   []manifestSlice   `json:"slices,omitempty"`
+}
+
+type manifestTexture struct {
+	Id      uint32 `json:"id"`
+	Sheet   int    `json:"sheet"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	W       int    `json:"w"`
+	H       int    `json:"h"`
+	Rotated bool   `json:"rotated,omitempty"`
+	Name    string `json:"name,omitempty"`
+	// Trim is the rectangle of a trimmed texture within its original size
+	Trim *manifestTrim `json:"trim,omitempty"`
+}
+
+type manifestTrim struct {
+	manifestRect
+	SourceW int `json:"sourceW"`
+	SourceH int `json:"sourceH"`
+}
+
+type manifestRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type manifestSlice struct {
+	Id  uint32 `json:"id"`
+	Len uint32 `json:"len"`
+}
+
+// Save writes the packed atlas to w as a zip archive, containing the internal textures as PNG files
+// and a manifest with the location of every texture. The atlas can be restored with Load, without
+// the cost of decoding the individual images and packing them again.
+func (a *Atlas) Save(w io.Writer) error {
+	if !a.clean {
+		panic("Atlas is dirty, call atlas.Pack() first")
+	}
+
+	m := manifest{
+		Version: manifestVersion,
+		NextId:  a.id,
+		Options: a.options,
+	}
+	for id, l := range a.idMap {
+		t := manifestTexture{
+			Id:      id,
+			Sheet:   l.index,
+			X:       l.rect.Min.X,
+			Y:       l.rect.Min.Y,
+			W:       l.rect.Dx(),
+			H:       l.rect.Dy(),
+			Rotated: l.rotated,
+			Name:    a.names[id],
+		}
+		if tr, ok := a.trims[id]; ok {
+			r := tr.rect
+			t.Trim = &manifestTrim{
+				manifestRect: manifestRect{X: r.Min.X, Y: r.Min.Y, W: r.Dx(), H: r.Dy()},
+				SourceW:      tr.size.X,
+				SourceH:      tr.size.Y,
+			}
+		}
+		m.Textures = append(m.Textures, t)
+	}
+	sort.Slice(m.Textures, func(i, j int) bool {
+		return m.Textures[i].Id < m.Textures[j].Id
+	})
+	for id, n := range a.slices {
+		m.Slices = append(m.Slices, manifestSlice{Id: id, Len: n})
+	}
+	sort.Slice(m.Slices, func(i, j int) bool {
+		return m.Slices[i].Id < m.Slices[j].Id
+	})
+
+	// the free space of the sheets is kept, so that more textures can be packed into them
+	for _, sh := range a.sheets {
+		free := []manifestRect{}
+		for _, r := range sh.spaces {
+			free = append(free, manifestRect{X: r.Min.X, Y: r.Min.Y, W: r.Dx(), H: r.Dy()})
+		}
+		m.Free = append(m.Free, free)
+	}
+
+	z := zip.NewWriter(w)
+	for i, t := range a.internal {
+		name := fmt.Sprintf("%v.png", i)
+		m.Sheets = append(m.Sheets, name)
+
+		f, err := z.Create(name)
+		if err != nil {
+			return err
+		}
+		if err := png.Encode(f, t.Image()); err != nil {
+			return errors.Wrapf(err, "failed to encode atlas sheet %v", i)
+		}
+	}
+
+	f, err := z.Create(manifestName)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+
+	return z.Close()
+}
+
+// Load reads an atlas written by Atlas.Save. The loaded atlas is packed and its textures have the
+// same ids as in the saved atlas; they belong to the default group. More textures can be added to
+// it as usual.
+func Load(r io.Reader) (*Atlas, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read atlas archive")
+	}
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read atlas archive")
+	}
+
+	var m manifest
+	if err := readManifest(z, &m); err != nil {
+		return nil, err
+	}
+	if m.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported atlas archive version %v", m.Version)
+	}
+
+	a := &Atlas{
+		id:      m.NextId,
+		idMap:   make(map[uint32]loc, len(m.Textures)),
+		clean:   true,
+		options: m.Options,
+	}
+	for i, name := range m.Sheets {
+		img, err := readSheet(z, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read atlas sheet %v", i)
+		}
+		a.internal = append(a.internal, pixel.PictureDataFromImage(img))
+
+		sh := sheet{size: img.Bounds()}
+		if i < len(m.Free) {
+			for _, r := range m.Free[i] {
+				sh.spaces = append(sh.spaces, rect(r.X, r.Y, r.W, r.H))
+			}
+		}
+		a.sheets = append(a.sheets, sh)
+	}
+
+	group := a.DefaultGroup()
+	for _, t := range m.Textures {
+		rect := rect(t.X, t.Y, t.W, t.H)
+		if t.Sheet < 0 || t.Sheet >= len(a.internal) || t.Id >= m.NextId ||
+			!rect.In(image2Rect(a.internal[t.Sheet].Bounds())) {
+			return nil, fmt.Errorf("invalid atlas texture %v", t.Id)
+		}
+		a.idMap[t.Id] = loc{index: t.Sheet, rect: rect, rotated: t.Rotated}
+		if t.Name != "" {
+			a.setName(t.Id, t.Name)
+		}
+		if t.Trim != nil {
+			r := t.Trim
+			a.setTrim(t.Id, trim{
+				rect: image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H),
+				size: image.Pt(t.Trim.SourceW, t.Trim.SourceH),
+			})
+		}
+		group.textures = append(group.textures, TextureId{id: t.Id, atlas: a})
+	}
+	for _, sl := range m.Slices {
+		a.setSlice(sl.Id, sl.Len)
+	}
+
+	return a, nil
+}
+
+func readManifest(z *zip.Reader, m *manifest) error {
+	f, err := z.Open(manifestName)
+	if err != nil {
+		return errors.Wrap(err, "failed to open atlas manifest")
+	}
+	defer f.Close()
+	return errors.Wrap(json.NewDecoder(f).Decode(m), "failed to decode atlas manifest")
+}
+
+func readSheet(z *zip.Reader, name string) (image.Image, error) {
+	f, err := z.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
@@ -0,0 +1,70 @@
+package atlas
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtlas_SaveLoad(t *testing.T) {
+	i1 := generateImageGradient(image.Rect(0, 0, 10, 10), color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255})
+	i2 := generateImageGradient(image.Rect(0, 0, 4, 8), color.RGBA{0, 0, 255, 255}, color.RGBA{255, 255, 0, 255})
+
+	var a Atlas
+	t1 := a.AddImage(i1)
+	slice := a.SliceImage(i2, pixel.V(2, 2))
+	sheet := a.AddSheet([]byte(asepriteHash), sheetImage())
+	a.Pack()
+
+	var buf bytes.Buffer
+	require.NoError(t, a.Save(&buf))
+
+	loaded, err := Load(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	assert.Equal(t, a.idMap, loaded.idMap)
+	assert.Equal(t, a.names, loaded.names)
+	assert.Equal(t, a.byName, loaded.byName)
+	assert.Equal(t, a.slices, loaded.slices)
+	assert.Equal(t, a.trims, loaded.trims)
+	assert.Equal(t, "b", loaded.names[sheet.Frames[1].Texture.id])
+	require.Len(t, loaded.internal, len(a.internal))
+	for i := range a.internal {
+		assert.Equal(t, a.internal[i].Image(), loaded.internal[i].Image())
+	}
+
+	for _, id := range []uint32{t1.id, slice.Frame(3).id, sheet.Frames[1].Texture.id} {
+		assert.Equal(t, a.Get(id).Frame(), loaded.Get(id).Frame())
+		assert.Equal(t, textureImage(a.Get(id)), textureImage(loaded.Get(id)))
+	}
+
+	// new textures don't collide with the loaded ones
+	t2 := loaded.AddImage(i1)
+	assert.Equal(t, a.id, t2.id)
+	loaded.Pack()
+	assert.Equal(t, textureImage(t1), textureImage(loaded.Get(t1.id)))
+	assert.Equal(t, textureImage(t1), textureImage(t2))
+
+	// loaded textures belong to the default group
+	loaded.Clear(*loaded.DefaultGroup())
+	assert.Empty(t, loaded.idMap)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := Load(bytes.NewReader([]byte("garbage")))
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	f, _ := z.Create(manifestName)
+	f.Write([]byte(`{"version": 1, "nextId": 1, "sheets": [], "textures": [{"id": 0, "sheet": 0, "w": 1, "h": 1}]}`))
+	z.Close()
+	_, err = Load(&buf)
+	assert.Error(t, err)
+}
@@ -20,8 +20,15 @@ const (
 )
 
 type loc struct {
-	index int
-	rect  image.Rectangle
+	index   int
+	rect    image.Rectangle
+	rotated bool
+}
+
+// packItem is a single texture to be packed.
+type packItem struct {
+	id  uint32
+	img image.Image
 }
 
 type spaces []image.Rectangle
@@ -29,6 +36,10 @@ type spaces []image.Rectangle
 type sheet struct {
 	size   image.Rectangle
 	spaces spaces
+	// freed are the spaces freed since the sheet's texture was last updated
+	freed spaces
+	// dirty is set when the sheet's texture needs to be updated
+	dirty bool
 }
 
 type Atlas struct {
@@ -38,16 +49,28 @@ type Atlas struct {
 	idMap        map[uint32]loc
 	id           uint32
 	defaultGroup Group
+	options      Options
+	sheets       []sheet
+	// repack is set when all textures need to be packed from scratch
+	repack bool
+	// generation is incremented every time the atlas is packed, so that textures know when to
+	// update their sprites.
+	generation uint32
+
+	names  map[uint32]string
+	byName map[string]uint32
+	slices map[uint32]uint32
+	files  map[uint32]*watchedFile
+	trims  map[uint32]trim
 }
 
-// Dump writes out the internal textures to disk as PNG files.
+// Dump writes out the internal textures to disk as PNG files. Use Save to write an archive that can
+// be loaded back with Load.
 func (a *Atlas) Dump(dir string) {
 	if !a.clean {
 		panic("Atlas is dirty, call atlas.Pack() first")
 	}
 
-	// TODO improve dump so that the resulting file(s) can be directly loaded into the atlas from disk.
-	//		Could be something like a zip file with the images and a file with the locations of the individual frames.
 	for i, t := range a.internal {
 		f, err := os.Create(path.Join(dir, fmt.Sprintf("%v.png", i)))
 		if err != nil {
@@ -126,76 +149,126 @@ func (a *Atlas) SliceEmbed(fs embed.FS, path string, cellSize pixel.Vec, decoder
 // Pack takes all of the added textures and adds them to the atlas largest to smallest,
 // trying to waste as little space as possible. After this call, the textures added
 // to the atlas can be used.
+//
+// Packing is incremental: the added textures are placed into the free space of the internal
+// textures, which includes the space freed by Clear, and only when they don't fit a new internal
+// texture is made. The textures already in the atlas stay where they are, use Repack to pack all
+// textures from scratch.
+//
+// The textures are packed according to the options of the atlas, see SetOptions.
 func (a *Atlas) Pack() {
 	// If there's nothing to do, don't do anything
 	if a.clean {
 		return
 	}
 
-	// If we've already packed the textures, we need to convert them back to images to repack them
-	if len(a.internal) > 0 {
+	var items []packItem
+
+	a.eraseFreed()
+
+	// If we need to start over, we need to cut out the packed textures to pack them again
+	if a.repack || len(a.sheets) != len(a.internal) {
 		images := make([]*image.RGBA, len(a.internal))
 		for i, data := range a.internal {
 			images[i] = data.Image()
 		}
-
-		for id, loc := range a.idMap {
-			bounds := image.Rect(0, 0, loc.rect.Dx(), loc.rect.Dy())
-			rgba := image.NewRGBA(bounds)
-			i := images[loc.index]
-
-			for y := 0; y < bounds.Dy(); y++ {
-				for x := 0; x < bounds.Dx(); x++ {
-					rgba.Set(x, y, i.At(loc.rect.Min.X+x, loc.rect.Min.Y+y))
-				}
+		for id, l := range a.idMap {
+			img := images[l.index].SubImage(l.rect)
+			if l.rotated {
+				img = rotateCCW(img)
 			}
+			items = append(items, packItem{id: id, img: img})
+		}
 
-			entry := imageEntry{data: rgba}
-			entry.id = id
-			entry.bounds = bounds
-
-			a.adding = append(a.adding, entry)
+		// reset internal stuff
+		a.internal = nil
+		a.sheets = nil
+		if a.idMap == nil {
+			a.idMap = make(map[uint32]loc)
+		} else {
+			clear(a.idMap)
 		}
 	}
 
-	if len(a.adding) == 0 {
-		return
+	// Load the added textures, slicing up the sliced ones into their frames
+	for _, add := range a.adding {
+		var (
+			err    error
+			sprite image.Image
+		)
+
+		switch add := add.(type) {
+		case iImageEntry:
+			sprite = add.Data()
+		case iEmbedEntry:
+			sprite, err = pixel.ImageFromEmbed(add.FS(), add.Path(), add.DecoderFunc())
+			err = errors.Wrapf(err, "failed to load embed sprite: %v", add.Path())
+		case iFileEntry:
+			sprite, err = pixel.ImageFromFile(add.Path(), add.DecoderFunc())
+			err = errors.Wrapf(err, "failed to load sprite file: %v", add.Path())
+		}
+		if err != nil {
+			panic(err)
+		}
+
+		slice, ok := add.(iSliceEntry)
+		if !ok {
+			items = append(items, packItem{id: add.Id(), img: sprite})
+			continue
+		}
+		id := add.Id()
+		bounds := sprite.Bounds()
+		for y := bounds.Min.Y; y < bounds.Max.Y; y += slice.Frame().Y {
+			for x := bounds.Min.X; x < bounds.Max.X; x += slice.Frame().X {
+				items = append(items, packItem{id: id, img: subImage(sprite, rect(x, y, slice.Frame().X, slice.Frame().Y))})
+				id++
+			}
+		}
 	}
 
-	// reset internal stuff
-	a.internal = a.internal[:0]
 	if a.idMap == nil {
 		a.idMap = make(map[uint32]loc)
-	} else {
-		clear(a.idMap)
 	}
 
-	sort.Slice(a.adding, func(i, j int) bool {
-		return area(a.adding[i].Bounds()) >= area(a.adding[j].Bounds())
+	sort.SliceStable(items, func(i, j int) bool {
+		if ai, aj := area(items[i].img.Bounds()), area(items[j].img.Bounds()); ai != aj {
+			return ai > aj
+		}
+		return items[i].id < items[j].id
 	})
 
-	sheets := make([]sheet, 1)
-	for i := range sheets {
-		sheets[i] = sheet{
-			spaces: []image.Rectangle{image.Rect(0, 0, MaxTextureSize, MaxTextureSize)},
-		}
-	}
+	var (
+		maxSize = a.maxSize()
+		padding = a.options.Padding
+		extrude = a.options.Extrude
+		// The padding is added to the right and bottom of every texture, the free space of a sheet is
+		// grown by the padding so the textures at the edges don't need it.
+		empty = image.Rect(0, 0, maxSize+padding, maxSize+padding)
+	)
 
-	for _, add := range a.adding {
-		bw, bh := add.Bounds().Dx(), add.Bounds().Dy()
+	placed := make([]image.Rectangle, len(items))
+	rotated := make([]bool, len(items))
+
+	for k, item := range items {
+		w, h := item.img.Bounds().Dx(), item.img.Bounds().Dy()
+		bw, bh := w+2*extrude+padding, h+2*extrude+padding
 
 		found := image.Rectangle{}
 		foundI := -1
 
 	Loop:
-		for i := range sheets {
-			for j := range sheets[i].spaces {
-				found, sheets[i].spaces = split(sheets[i].spaces, j, bw, bh)
+		for i := range a.sheets {
+			for j := range a.sheets[i].spaces {
+				found, a.sheets[i].spaces = split(a.sheets[i].spaces, j, bw, bh)
+				if found.Empty() && a.options.AllowRotation && bw != bh {
+					found, a.sheets[i].spaces = split(a.sheets[i].spaces, j, bh, bw)
+					rotated[k] = !found.Empty()
+				}
 				if found.Empty() {
 					continue
 				}
-				sort.Slice(sheets[i].spaces, func(a, b int) bool {
-					return area(sheets[i].spaces[a]) < area(sheets[i].spaces[b])
+				sort.Slice(a.sheets[i].spaces, func(x, y int) bool {
+					return area(a.sheets[i].spaces[x]) < area(a.sheets[i].spaces[y])
 				})
 				foundI = i
 				break Loop
@@ -203,82 +276,149 @@ func (a *Atlas) Pack() {
 		}
 
 		if foundI == -1 {
-			foundI = len(sheets)
-			sheets = append(sheets, sheet{})
-			found, sheets[foundI].spaces = split([]image.Rectangle{image.Rect(0, 0, MaxTextureSize, MaxTextureSize)}, 0, bw, bh)
+			foundI = len(a.sheets)
+			a.sheets = append(a.sheets, sheet{})
+			found, a.sheets[foundI].spaces = split(spaces{empty}, 0, bw, bh)
+			if found.Empty() && a.options.AllowRotation {
+				found, a.sheets[foundI].spaces = split(spaces{empty}, 0, bh, bw)
+				rotated[k] = true
+			}
+			if found.Empty() {
+				panic(fmt.Errorf("Texture is larger (%v, %v) than the maximum allowed texture (%v, %v)", w, h, maxSize, maxSize))
+			}
 		}
 
 		// Increase the size of the Atlas so we can allocate the minimum-sized
 		// 	texture later.
-		if found.Min.X == 0 {
-			sheets[foundI].size.Max.Y += found.Dy()
-		}
-		if found.Min.Y == 0 {
-			sheets[foundI].size.Max.X += found.Dx()
-		}
+		sh := &a.sheets[foundI]
+		sh.size.Max.X = max(sh.size.Max.X, found.Max.X-padding)
+		sh.size.Max.Y = max(sh.size.Max.Y, found.Max.Y-padding)
+		sh.dirty = true
 
-		switch add := add.(type) {
-		case iSliceEntry:
-			// If we have a frame, that means we just added a sprite sheet to the sprite sheet
-			// 	so we need to add id entries for each of the sprites
-			id := add.Id()
-			for y := 0; y < add.Bounds().Dy(); y += add.Frame().Y {
-				for x := 0; x < add.Bounds().Dx(); x += add.Frame().X {
-					a.idMap[id] = loc{
-						index: foundI,
-						rect:  rect(found.Min.X+x, found.Min.Y+y, add.Frame().X, add.Frame().Y),
-					}
-					id++
-				}
-			}
-		default:
-			// Found a spot, add it to the map
-			a.idMap[add.Id()] = loc{
-				index: foundI,
-				rect:  found,
-			}
+		if rotated[k] {
+			w, h = h, w
+		}
+		placed[k] = rect(found.Min.X+extrude, found.Min.Y+extrude, w, h)
+		a.idMap[item.id] = loc{
+			index:   foundI,
+			rect:    placed[k],
+			rotated: rotated[k],
 		}
 	}
 
-	// Create internal textures
-	sprites := make([]*image.RGBA, len(sheets))
-	for i := range sheets {
-		if !sheets[i].size.Empty() {
-			sprites[i] = image.NewRGBA(sheets[i].size)
+	// Create or grow the internal textures that changed
+	sprites := a.dirtySheets()
+
+	// Copy individual sprite data into internal textures
+	for k, item := range items {
+		s := a.idMap[item.id]
+		img := item.img
+		if rotated[k] {
+			img = rotateCW(img)
 		}
+		draw.Draw(sprites[s.index], placed[k], img, img.Bounds().Min, draw.Src)
+		extrudeEdges(sprites[s.index], placed[k], extrude)
 	}
 
-	// Copy individual sprite data into internal textures
-	for _, add := range a.adding {
-		var (
-			err    error
-			sprite image.Image
-			s      = a.idMap[add.Id()]
-		)
+	a.commitSheets(sprites)
+	a.adding = nil
+	a.clean = true
+	a.repack = false
+}
 
-		switch add := add.(type) {
-		case iImageEntry:
-			sprite = add.Data()
-		case iEmbedEntry:
-			sprite, err = pixel.ImageFromEmbed(add.FS(), add.Path(), add.DecoderFunc())
-			err = errors.Wrapf(err, "failed to load embed sprite: %v", add.Path())
-		case iFileEntry:
-			sprite, err = pixel.ImageFromFile(add.Path(), add.DecoderFunc())
-			err = errors.Wrapf(err, "failed to load sprite file: %v", add.Path())
+// Repack packs all of the textures of the atlas from scratch, which may take less space than
+// packing them incrementally after textures have been cleared.
+func (a *Atlas) Repack() {
+	a.repack = true
+	a.clean = false
+	a.Pack()
+}
+
+// dirtySheets returns the images of the internal textures that changed, grown to the size of their
+// sheets, to draw the changes into. The images of unchanged sheets are nil.
+func (a *Atlas) dirtySheets() []*image.RGBA {
+	sprites := make([]*image.RGBA, len(a.sheets))
+	for i := range a.sheets {
+		if !a.sheets[i].dirty {
+			continue
 		}
-		if err != nil {
-			panic(err)
+		size := a.sheets[i].size
+		if a.options.PowerOfTwo {
+			size.Max = image.Pt(nextPowerOfTwo(size.Max.X), nextPowerOfTwo(size.Max.Y))
+		}
+		if i >= len(a.internal) {
+			sprites[i] = image.NewRGBA(size)
+			continue
+		}
+		sprites[i] = a.internal[i].Image()
+		if grown := sprites[i].Bounds().Union(size); grown != sprites[i].Bounds() {
+			img := image.NewRGBA(grown)
+			draw.Draw(img, sprites[i].Bounds(), sprites[i], image.Point{}, draw.Src)
+			sprites[i] = img
 		}
-		draw.Draw(sprites[s.index], rect(s.rect.Min.X, s.rect.Min.Y, add.Bounds().Dx(), add.Bounds().Dy()), sprite, image.Point{}, draw.Src)
 	}
+	return sprites
+}
 
-	// Make the internal Textures
-	a.internal = make([]*pixel.PictureData, len(sprites))
+// commitSheets makes new internal textures of the changed images, so that the targets drawing them
+// upload them again, and lets the textures know they need to update their sprites.
+func (a *Atlas) commitSheets(sprites []*image.RGBA) {
 	for i, sprite := range sprites {
+		if sprite == nil {
+			continue
+		}
 		data := pixel.PictureDataFromImage(sprite)
-		a.internal[i] = data
+		if i < len(a.internal) {
+			a.internal[i] = data
+		} else {
+			a.internal = append(a.internal, data)
+		}
+		a.sheets[i].dirty = false
 	}
+	a.generation++
+}
 
-	a.adding = nil
-	a.clean = true
+// free returns the space of a packed texture to its sheet and erases it.
+func (a *Atlas) free(id uint32) {
+	l, ok := a.idMap[id]
+	if !ok {
+		return
+	}
+	delete(a.idMap, id)
+	if l.index >= len(a.sheets) {
+		return
+	}
+
+	cell := l.rect.Inset(-a.options.Extrude)
+	cell.Max = cell.Max.Add(image.Pt(a.options.Padding, a.options.Padding))
+	sh := &a.sheets[l.index]
+	sh.spaces = merge(append(sh.spaces, cell))
+	sort.Slice(sh.spaces, func(x, y int) bool {
+		return area(sh.spaces[x]) < area(sh.spaces[y])
+	})
+	sh.freed = append(sh.freed, cell)
+}
+
+// eraseFreed clears the pixels of the freed textures.
+func (a *Atlas) eraseFreed() {
+	var dirty bool
+	for i := range a.sheets {
+		dirty = dirty || len(a.sheets[i].freed) > 0
+	}
+	if !dirty {
+		return
+	}
+
+	sprites := make([]*image.RGBA, len(a.sheets))
+	for i := range a.sheets {
+		if len(a.sheets[i].freed) == 0 || i >= len(a.internal) {
+			continue
+		}
+		sprites[i] = a.internal[i].Image()
+		for _, r := range a.sheets[i].freed {
+			draw.Draw(sprites[i], r, image.Transparent, image.Point{}, draw.Src)
+		}
+		a.sheets[i].freed = nil
+	}
+	a.commitSheets(sprites)
 }
@@ -32,19 +32,26 @@ func (a *Atlas) DefaultGroup() *Group {
 
 // Clear removes the given texture groups from the atlas.
 // If no groups are given, all textures are removed.
+//
+// The space of the removed textures is reused by the textures packed later on, the other textures
+// stay where they are.
 func (a *Atlas) Clear(groups ...Group) {
 	if len(groups) == 0 {
 		maps.Clear(a.idMap)
+		maps.Clear(a.names)
+		maps.Clear(a.byName)
+		maps.Clear(a.slices)
+		maps.Clear(a.files)
+		a.internal = nil
+		a.sheets = nil
 	}
 
 	for _, group := range groups {
 		for _, texture := range group.textures {
-			delete(a.idMap, texture.id)
+			a.remove(texture.id)
 		}
 		for _, slice := range group.slices {
-			for i := uint32(0); i < slice.len; i++ {
-				delete(a.idMap, slice.start.id+i)
-			}
+			a.remove(slice.start.id)
 		}
 	}
 
@@ -54,18 +61,29 @@ func (a *Atlas) Clear(groups ...Group) {
 }
 
 func (g *Group) addEntry(entry iEntry) (id TextureId) {
-	if bw, bh := entry.Bounds().Dx(), entry.Bounds().Dy(); bw > MaxTextureSize || bh > MaxTextureSize {
-		panic(fmt.Errorf("Texture is larger (%v, %v) than the maximum allowed texture (%v, %v)", bw, bh, MaxTextureSize, MaxTextureSize))
+	if bw, bh, size := entry.Bounds().Dx(), entry.Bounds().Dy(), g.atlas.maxSize(); bw > size || bh > size {
+		panic(fmt.Errorf("Texture is larger (%v, %v) than the maximum allowed texture (%v, %v)", bw, bh, size, size))
 	}
 
 	id = TextureId{id: g.atlas.id, atlas: g.atlas}
 	g.textures = append(g.textures, id)
 	switch entry := entry.(type) {
 	case iSliceEntry:
-		g.atlas.id += uint32((entry.Bounds().Dx() / entry.Frame().X) * (entry.Bounds().Dy() / entry.Frame().Y))
+		n := uint32((entry.Bounds().Dx() / entry.Frame().X) * (entry.Bounds().Dy() / entry.Frame().Y))
+		g.atlas.setSlice(id.id, n)
+		g.atlas.id += n
 	default:
 		g.atlas.id++
 	}
+
+	// Textures loaded from files are named after their path, and files on disk can be reloaded
+	switch entry := entry.(type) {
+	case iEmbedEntry:
+		g.atlas.setName(id.id, entry.Path())
+	case iFileEntry:
+		g.atlas.setName(id.id, entry.Path())
+		g.atlas.watchFile(id.id, entry)
+	}
 	g.atlas.adding = append(g.atlas.adding, entry)
 	g.atlas.clean = false
 	return
@@ -40,8 +40,15 @@ func TestAtlas_Clear(t *testing.T) {
 	// Remove one of the images through its group
 	a.Clear(g1)
 
-	// Now the atlas texture should be the same as the first image
+	// Its space is freed, but the atlas isn't repacked
 	tex := a.internal[a.idMap[s1.id].index].Image()
+	require.Equal(t, image.Rect(0, 0, 20, 10), tex.Bounds())
+	require.Equal(t, i1.Pix, textureImage(s1).Pix)
+	require.Equal(t, color.RGBA{}, tex.RGBAAt(15, 5))
+
+	// Now the atlas texture should be the same as the first image
+	a.Repack()
+	tex = a.internal[a.idMap[s1.id].index].Image()
 	require.Equal(t, i1.Bounds(), tex.Bounds())
 
 	for i := range i1.Pix {
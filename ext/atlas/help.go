@@ -2,6 +2,7 @@ package atlas
 
 import (
 	"image"
+	"image/draw"
 
 	"github.com/gopxl/pixel/v2"
 	"golang.org/x/exp/constraints"
@@ -63,3 +64,83 @@ func split(spcs spaces, j int, bw, bh int) (found image.Rectangle, newSpcs space
 	newSpcs = spcs
 	return
 }
+
+func image2Rect(r pixel.Rect) image.Rectangle {
+	return image.Rect(int(r.Min.X), int(r.Min.Y), int(r.Max.X), int(r.Max.Y))
+}
+
+// subImage returns the part of the image within r, without copying it if possible.
+func subImage(img image.Image, r image.Rectangle) image.Image {
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(r)
+	}
+	out := image.NewRGBA(r)
+	draw.Draw(out, r, img, r.Min, draw.Src)
+	return out
+}
+
+// rotateCW returns a copy of the image rotated by 90 degrees clockwise.
+func rotateCW(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			out.Set(b.Dy()-1-y, x, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return out
+}
+
+// rotateCCW returns a copy of the image rotated by 90 degrees counter-clockwise.
+func rotateCCW(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			out.Set(y, b.Dx()-1-x, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return out
+}
+
+// extrudeEdges repeats the edge pixels of r n times around it.
+func extrudeEdges(img *image.RGBA, r image.Rectangle, n int) {
+	if n <= 0 || r.Empty() {
+		return
+	}
+	outer := r.Inset(-n).Intersect(img.Bounds())
+	for y := outer.Min.Y; y < outer.Max.Y; y++ {
+		for x := outer.Min.X; x < outer.Max.X; x++ {
+			if (image.Point{x, y}).In(r) {
+				continue
+			}
+			sx := min(max(x, r.Min.X), r.Max.X-1)
+			sy := min(max(y, r.Min.Y), r.Max.Y-1)
+			img.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+}
+
+// merge joins the spaces that share a whole edge, so that freed spaces can fit larger textures.
+func merge(spcs spaces) spaces {
+	for merged := true; merged; {
+		merged = false
+	Loop:
+		for i := range spcs {
+			for j := i + 1; j < len(spcs); j++ {
+				a, b := spcs[i], spcs[j]
+				sameRow := a.Min.Y == b.Min.Y && a.Max.Y == b.Max.Y && (a.Max.X == b.Min.X || b.Max.X == a.Min.X)
+				sameColumn := a.Min.X == b.Min.X && a.Max.X == b.Max.X && (a.Max.Y == b.Min.Y || b.Max.Y == a.Min.Y)
+				if sameRow || sameColumn {
+					spcs[i] = a.Union(b)
+					spcs = append(spcs[:j], spcs[j+1:]...)
+					merged = true
+					break Loop
+				}
+			}
+		}
+	}
+	return spcs
+}
@@ -0,0 +1,81 @@
+package atlas
+
+// Lookup returns the texture with the given name. Textures loaded from files are named after their
+// path, frames of sprite sheets after the frame, other textures can be named with SetName.
+//
+// If the name refers to a slice, the first frame of the slice is returned.
+func (a *Atlas) Lookup(name string) (TextureId, bool) {
+	id, ok := a.byName[name]
+	if !ok {
+		return TextureId{}, false
+	}
+	return a.Get(id), true
+}
+
+// LookupSlice returns the slice with the given name, see Lookup.
+func (a *Atlas) LookupSlice(name string) (SliceId, bool) {
+	id, ok := a.byName[name]
+	if !ok {
+		return SliceId{}, false
+	}
+	n, ok := a.slices[id]
+	if !ok {
+		return SliceId{}, false
+	}
+	return SliceId{start: a.Get(id), len: n}, true
+}
+
+// SetName names the texture or the slice starting with the texture with the given ID, replacing its
+// previous name. If another texture already has the name, it loses it.
+func (a *Atlas) SetName(id uint32, name string) {
+	a.setName(id, name)
+}
+
+// Name returns the name of the texture, or an empty string if it has no name.
+func (t TextureId) Name() string {
+	return t.atlas.names[t.id]
+}
+
+func (a *Atlas) setName(id uint32, name string) {
+	if a.names == nil {
+		a.names = make(map[uint32]string)
+		a.byName = make(map[string]uint32)
+	}
+	if old, ok := a.names[id]; ok && a.byName[old] == id {
+		delete(a.byName, old)
+	}
+	if other, ok := a.byName[name]; ok {
+		delete(a.names, other)
+	}
+	a.names[id] = name
+	a.byName[name] = id
+}
+
+func (a *Atlas) setSlice(start, n uint32) {
+	if a.slices == nil {
+		a.slices = make(map[uint32]uint32)
+	}
+	a.slices[start] = n
+}
+
+// remove removes the texture, or all frames of the slice starting with it, from the atlas.
+func (a *Atlas) remove(id uint32) {
+	n, ok := a.slices[id]
+	if !ok {
+		n = 1
+	}
+	for i := id; i < id+n; i++ {
+		a.free(i)
+		if name, ok := a.names[i]; ok {
+			delete(a.names, i)
+			if a.byName[name] == i {
+				delete(a.byName, name)
+			}
+		}
+	}
+	delete(a.slices, id)
+	delete(a.files, id)
+	for i := id; i < id+n; i++ {
+		delete(a.trims, i)
+	}
+}
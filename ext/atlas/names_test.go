@@ -0,0 +1,158 @@
+package atlas
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePNG(t *testing.T, path string, img image.Image) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, png.Encode(f, img))
+}
+
+func TestAtlas_Lookup(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "red.png")
+	sheetFile := filepath.Join(dir, "sheet.png")
+	writePNG(t, file, solidImage(2, 2, red))
+	writePNG(t, sheetFile, solidImage(4, 2, blue))
+
+	var a Atlas
+	g := a.MakeGroup()
+	fromFile := a.AddFile(file, nil)
+	slice := g.SliceFile(sheetFile, pixel.V(2, 2), nil)
+	img := a.AddImage(solidImage(1, 1, green))
+	a.SetName(img.ID(), "green")
+	a.Pack()
+
+	tex, ok := a.Lookup(file)
+	assert.True(t, ok)
+	assert.Equal(t, fromFile.ID(), tex.ID())
+	assert.Equal(t, file, tex.Name())
+
+	tex, ok = a.Lookup("green")
+	assert.True(t, ok)
+	assert.Equal(t, img.ID(), tex.ID())
+
+	s, ok := a.LookupSlice(sheetFile)
+	assert.True(t, ok)
+	assert.Equal(t, slice.Len(), s.Len())
+	assert.Equal(t, slice.Frame(1).ID(), s.Frame(1).ID())
+
+	_, ok = a.LookupSlice("green")
+	assert.False(t, ok, "not a slice")
+	_, ok = a.Lookup("blue")
+	assert.False(t, ok)
+
+	// renaming moves the name
+	a.SetName(fromFile.ID(), "green")
+	tex, _ = a.Lookup("green")
+	assert.Equal(t, fromFile.ID(), tex.ID())
+	assert.Equal(t, "", img.Name())
+	_, ok = a.Lookup(file)
+	assert.False(t, ok)
+
+	// clearing a group removes its names and all frames of its slices
+	a.Clear(g)
+	_, ok = a.LookupSlice(sheetFile)
+	assert.False(t, ok)
+	assert.NotContains(t, a.idMap, slice.Frame(1).ID())
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "tex.png")
+	sheetFile := filepath.Join(dir, "sheet.png")
+	writePNG(t, file, solidImage(2, 2, red))
+	writePNG(t, sheetFile, solidImage(2, 1, red))
+
+	var a Atlas
+	tex := a.AddFile(file, nil)
+	other := a.AddImage(solidImage(1, 1, green))
+	slice := a.SliceFile(sheetFile, pixel.V(1, 1), nil)
+	a.Pack()
+	assertPixels(t, textureImage(tex), red, red, red, red)
+
+	w := a.Watch(0)
+	reloaded, err := w.Poll()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// the file may change size, but keeps its id
+	later := time.Now().Add(time.Minute)
+	writePNG(t, file, solidImage(1, 2, blue))
+	require.NoError(t, os.Chtimes(file, later, later))
+	sheet := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	sheet.Set(0, 0, blue)
+	sheet.Set(1, 0, white)
+	writePNG(t, sheetFile, sheet)
+	require.NoError(t, os.Chtimes(sheetFile, later, later))
+
+	reloaded, err = w.Poll()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, pixel.R(0, 0, 1, 2), tex.Bounds())
+	assertPixels(t, textureImage(tex), blue, blue)
+	assertPixels(t, textureImage(other), green)
+	assertPixels(t, textureImage(slice.Frame(0)), blue)
+	assertPixels(t, textureImage(slice.Frame(1)), white)
+
+	// a broken file keeps the old texture and is retried
+	later = later.Add(time.Minute)
+	require.NoError(t, os.WriteFile(file, []byte("not a png"), 0o644))
+	require.NoError(t, os.Chtimes(file, later, later))
+	reloaded, err = w.Poll()
+	assert.Error(t, err)
+	assert.False(t, reloaded)
+	assertPixels(t, textureImage(tex), blue, blue)
+
+	writePNG(t, file, solidImage(1, 1, white))
+	require.NoError(t, os.Chtimes(file, later, later))
+	reloaded, err = w.Poll()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	assertPixels(t, textureImage(tex), white)
+
+	// the interval is respected
+	w = a.Watch(time.Hour)
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(file, later, later))
+	reloaded, _ = w.Poll()
+	assert.False(t, reloaded)
+}
+
+func TestTextureId_DrawAfterRepack(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tex.png")
+	writePNG(t, file, solidImage(1, 1, red))
+
+	var a Atlas
+	tex := a.AddFile(file, nil)
+	a.Pack()
+
+	draw := func() pixel.RGBA {
+		c := software.NewCanvas(pixel.R(0, 0, 1, 1))
+		tex.Draw(c, pixel.IM.Moved(c.Bounds().Center()))
+		return c.Color(pixel.ZV)
+	}
+	assert.Equal(t, pixel.RGB(1, 0, 0), draw())
+
+	later := time.Now().Add(time.Minute)
+	writePNG(t, file, solidImage(1, 1, blue))
+	require.NoError(t, os.Chtimes(file, later, later))
+	_, err := a.Watch(0).Poll()
+	require.NoError(t, err)
+
+	// the sprite of the texture is made again after the atlas is packed
+	assert.Equal(t, pixel.RGB(0, 0, 1), draw())
+}
@@ -0,0 +1,50 @@
+package atlas
+
+// Options control how the textures of an Atlas are packed into its internal textures.
+//
+// The zero value packs textures edge to edge into sheets of up to MaxTextureSize pixels, which is
+// how an Atlas packs without options.
+type Options struct {
+	// MaxSize is the maximum width and height of an internal texture. Defaults to MaxTextureSize.
+	MaxSize int `json:"maxSize,omitempty"`
+	// Padding is the number of transparent pixels between two textures.
+	Padding int `json:"padding,omitempty"`
+	// Extrude repeats the edge pixels of every texture this many times around it, so that sampling
+	// just outside of a texture (e.g. when drawn scaled with smoothing on) picks its own edge color
+	// instead of the neighbouring texture's.
+	Extrude int `json:"extrude,omitempty"`
+	// PowerOfTwo rounds the size of the internal textures up to powers of two.
+	PowerOfTwo bool `json:"powerOfTwo,omitempty"`
+	// AllowRotation allows textures to be stored rotated by 90 degrees when they don't fit otherwise.
+	// Rotated textures are drawn upright, but their Frame is rotated.
+	AllowRotation bool `json:"allowRotation,omitempty"`
+}
+
+// SetOptions sets the packing options of the atlas. The atlas needs to be packed again before its
+// textures can be used, and all of its textures are packed from scratch.
+func (a *Atlas) SetOptions(opts Options) {
+	a.options = opts
+	a.clean = false
+	a.repack = true
+}
+
+// Options returns the packing options of the atlas.
+func (a *Atlas) Options() Options {
+	return a.options
+}
+
+func (a *Atlas) maxSize() int {
+	if a.options.MaxSize > 0 {
+		return a.options.MaxSize
+	}
+	return MaxTextureSize
+}
+
+// nextPowerOfTwo returns the smallest power of two greater than or equal to x.
+func nextPowerOfTwo(x int) int {
+	p := 1
+	for p < x {
+		p <<= 1
+	}
+	return p
+}
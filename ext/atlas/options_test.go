@@ -0,0 +1,124 @@
+package atlas
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func solidImage(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestAtlas_PaddingAndExtrude(t *testing.T) {
+	var a Atlas
+	a.SetOptions(Options{Padding: 1, Extrude: 1})
+	t1 := a.AddImage(solidImage(2, 2, red))
+	t2 := a.AddImage(solidImage(2, 2, blue))
+	a.Pack()
+
+	require.Len(t, a.internal, 1)
+	sheet := a.internal[0].Image()
+	l1, l2 := a.idMap[t1.id], a.idMap[t2.id]
+
+	// two extruded textures and the padding between them
+	assert.Equal(t, image.Rect(0, 0, 9, 4), sheet.Bounds())
+	assert.Equal(t, 1, l2.rect.Min.X-l1.rect.Max.X-2)
+
+	for _, l := range []loc{l1, l2} {
+		edge := sheet.RGBAAt(l.rect.Min.X, l.rect.Min.Y)
+		outer := l.rect.Inset(-1)
+		assert.Equal(t, edge, sheet.RGBAAt(outer.Min.X, outer.Min.Y))
+		assert.Equal(t, edge, sheet.RGBAAt(outer.Max.X-1, outer.Max.Y-1))
+	}
+	assert.Equal(t, transparent, sheet.RGBAAt(l1.rect.Max.X+1, 0))
+
+	assertPixels(t, textureImage(t1), red, red, red, red)
+	assertPixels(t, textureImage(t2), blue, blue, blue, blue)
+}
+
+func TestAtlas_ExtrudeSlice(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, red)
+	img.Set(1, 0, blue)
+
+	var a Atlas
+	a.SetOptions(Options{Extrude: 1})
+	slice := a.SliceImage(img, pixel.V(1, 1))
+	a.Pack()
+
+	// the frames of a slice don't touch each other anymore
+	sheet := a.internal[0].Image()
+	for i := uint32(0); i < slice.Len(); i++ {
+		l := a.idMap[slice.Frame(i).id]
+		c := sheet.RGBAAt(l.rect.Min.X, l.rect.Min.Y)
+		outer := l.rect.Inset(-1)
+		for y := outer.Min.Y; y < outer.Max.Y; y++ {
+			for x := outer.Min.X; x < outer.Max.X; x++ {
+				assert.Equal(t, c, sheet.RGBAAt(x, y))
+			}
+		}
+	}
+	assertPixels(t, textureImage(slice.Frame(0)), red)
+	assertPixels(t, textureImage(slice.Frame(1)), blue)
+}
+
+func TestAtlas_MaxSizeAndPowerOfTwo(t *testing.T) {
+	var a Atlas
+	a.SetOptions(Options{MaxSize: 4})
+	for i := 0; i < 3; i++ {
+		a.AddImage(solidImage(4, 4, red))
+	}
+	a.Pack()
+	assert.Len(t, a.internal, 3)
+
+	assert.Panics(t, func() { a.AddImage(solidImage(5, 1, red)) })
+
+	var b Atlas
+	b.SetOptions(Options{PowerOfTwo: true})
+	tex := b.AddImage(solidImage(3, 5, red))
+	b.Pack()
+	assert.Equal(t, pixel.R(0, 0, 4, 8), b.internal[0].Bounds())
+	assert.Equal(t, pixel.R(0, 0, 3, 5), tex.Bounds())
+}
+
+func TestAtlas_Rotation(t *testing.T) {
+	tall := image.NewRGBA(image.Rect(0, 0, 1, 4))
+	for y, c := range []color.RGBA{red, green, blue, white} {
+		tall.Set(0, y, c)
+	}
+
+	var a Atlas
+	a.SetOptions(Options{MaxSize: 4, AllowRotation: true})
+	a.AddImage(solidImage(4, 3, red))
+	tex := a.AddImage(tall)
+	a.Pack()
+
+	require.Len(t, a.internal, 1, "the tall texture fits below the wide one when rotated")
+	assert.True(t, a.idMap[tex.id].rotated)
+	assert.Equal(t, pixel.R(0, 0, 1, 4), tex.Bounds())
+	assert.Equal(t, 4.0, tex.Frame().W())
+	assertPixels(t, textureImage(tex), red, green, blue, white)
+
+	var buf bytes.Buffer
+	require.NoError(t, a.Save(&buf))
+	loaded, err := Load(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, a.Options(), loaded.Options())
+	assertPixels(t, textureImage(loaded.Get(tex.id)), red, green, blue, white)
+
+	// rotated textures survive repacking
+	a.SetOptions(Options{})
+	a.Pack()
+	assert.False(t, a.idMap[tex.id].rotated)
+	assertPixels(t, textureImage(a.Get(tex.id)), red, green, blue, white)
+}
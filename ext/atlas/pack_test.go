@@ -0,0 +1,85 @@
+package atlas
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func repeat(c color.RGBA, n int) []color.RGBA {
+	colors := make([]color.RGBA, n)
+	for i := range colors {
+		colors[i] = c
+	}
+	return colors
+}
+
+func TestAtlas_IncrementalPack(t *testing.T) {
+	var a Atlas
+	a.SetOptions(Options{MaxSize: 8})
+	t1 := a.AddImage(solidImage(4, 4, red))
+	a.Pack()
+	l1 := a.idMap[t1.id]
+	assert.Equal(t, image.Rect(0, 0, 4, 4), a.internal[0].Image().Bounds())
+
+	// the sheet grows to fit the new texture, the old one stays put
+	t2 := a.AddImage(solidImage(4, 4, blue))
+	a.Pack()
+	require.Len(t, a.internal, 1)
+	assert.Equal(t, l1, a.idMap[t1.id])
+	assertPixels(t, textureImage(t1), repeat(red, 16)...)
+	assertPixels(t, textureImage(t2), repeat(blue, 16)...)
+
+	// a texture that doesn't fit anymore goes to a new sheet
+	a.AddImage(solidImage(8, 8, green))
+	a.Pack()
+	assert.Len(t, a.internal, 2)
+	assert.Equal(t, l1, a.idMap[t1.id])
+}
+
+func TestAtlas_ClearReusesSpace(t *testing.T) {
+	var a Atlas
+	a.SetOptions(Options{MaxSize: 16, Padding: 1, Extrude: 1})
+	g := a.MakeGroup()
+	t1 := a.AddImage(solidImage(2, 2, red))
+	g.AddImage(solidImage(2, 2, blue))
+	g.AddImage(solidImage(2, 2, blue))
+	a.Pack()
+	require.Len(t, a.internal, 1)
+	l1 := a.idMap[t1.id]
+
+	a.Clear(g)
+	assert.Equal(t, l1, a.idMap[t1.id])
+	assert.Len(t, a.idMap, 1)
+
+	// the two freed neighbouring textures are merged to fit a wider one
+	t2 := a.AddImage(solidImage(6, 2, green))
+	a.Pack()
+	require.Len(t, a.internal, 1)
+	assert.Equal(t, l1, a.idMap[t1.id])
+	assertPixels(t, textureImage(t1), repeat(red, 4)...)
+	assertPixels(t, textureImage(t2), repeat(green, 12)...)
+}
+
+func TestAtlas_LoadThenPack(t *testing.T) {
+	var a Atlas
+	a.SetOptions(Options{MaxSize: 8})
+	t1 := a.AddImage(solidImage(4, 8, red))
+	a.Pack()
+
+	var buf bytes.Buffer
+	require.NoError(t, a.Save(&buf))
+	loaded, err := Load(&buf)
+	require.NoError(t, err)
+
+	// the free space of the loaded sheet is used
+	t2 := loaded.AddImage(solidImage(4, 4, blue))
+	loaded.Pack()
+	assert.Len(t, loaded.internal, 1)
+	assertPixels(t, textureImage(loaded.Get(t1.id)), repeat(red, 32)...)
+	assertPixels(t, textureImage(t2), repeat(blue, 16)...)
+}
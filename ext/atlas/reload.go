@@ -0,0 +1,112 @@
+package atlas
+
+import (
+	"image"
+	"os"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/pkg/errors"
+)
+
+// watchedFile is the source of a texture loaded from a file on disk.
+type watchedFile struct {
+	path    string
+	decoder pixel.DecoderFunc
+	modTime time.Time
+	// frame is the cell size of sliced textures
+	frame image.Point
+	size  image.Rectangle
+}
+
+func (a *Atlas) watchFile(id uint32, entry iFileEntry) {
+	if a.files == nil {
+		a.files = make(map[uint32]*watchedFile)
+	}
+	f := &watchedFile{
+		path:    entry.Path(),
+		decoder: entry.DecoderFunc(),
+		size:    entry.Bounds(),
+	}
+	if slice, ok := entry.(iSliceEntry); ok {
+		f.frame = slice.Frame()
+	}
+	if info, err := os.Stat(f.path); err == nil {
+		f.modTime = info.ModTime()
+	}
+	a.files[id] = f
+}
+
+// Watcher reloads the textures of an atlas loaded from files when the files change, so that changes
+// to the images show up without restarting the game. See Atlas.Watch.
+type Watcher struct {
+	atlas    *Atlas
+	interval time.Duration
+	last     time.Time
+}
+
+// Watch returns a Watcher checking the files of the textures added with AddFile and SliceFile at
+// most once per interval. Call Watcher.Poll every frame to reload the changed files.
+func (a *Atlas) Watch(interval time.Duration) *Watcher {
+	return &Watcher{
+		atlas:    a,
+		interval: interval,
+		last:     time.Now(),
+	}
+}
+
+// Poll checks the files for changes, if the interval has passed since the last check, and reloads
+// the changed ones. Only the changed files are decoded again and packed in place of the old images,
+// after which the textures are drawn with the new images.
+//
+// A file that fails to load (e.g. because it's being written to) keeps its old image and is retried
+// on the next check. A sliced file must keep its size.
+func (w *Watcher) Poll() (reloaded bool, err error) {
+	if time.Since(w.last) < w.interval {
+		return false, nil
+	}
+	w.last = time.Now()
+	return w.atlas.reload()
+}
+
+// reload reloads the textures whose files have changed.
+func (a *Atlas) reload() (reloaded bool, err error) {
+	if !a.clean {
+		panic("Atlas is dirty, call atlas.Pack() first")
+	}
+
+	for id, f := range a.files {
+		info, statErr := os.Stat(f.path)
+		if statErr != nil || info.ModTime().Equal(f.modTime) {
+			continue
+		}
+
+		img, loadErr := pixel.ImageFromFile(f.path, f.decoder)
+		if loadErr == nil && f.frame != (image.Point{}) && img.Bounds().Size() != f.size.Size() {
+			loadErr = errors.Errorf("size changed from %v to %v", f.size.Size(), img.Bounds().Size())
+		}
+		if loadErr != nil {
+			if err == nil {
+				err = errors.Wrapf(loadErr, "failed to reload sprite file: %v", f.path)
+			}
+			continue
+		}
+		f.modTime = info.ModTime()
+
+		e := imageEntry{entry: entry{id: id, bounds: img.Bounds()}, data: img}
+		if f.frame == (image.Point{}) {
+			a.free(id)
+			a.adding = append(a.adding, e)
+		} else {
+			for i := id; i < id+a.slices[id]; i++ {
+				a.free(i)
+			}
+			a.adding = append(a.adding, sliceImageEntry{imageEntry: e, sliceEntry: sliceEntry{frame: f.frame}})
+		}
+		a.clean = false
+		reloaded = true
+	}
+
+	a.Pack()
+	return reloaded, err
+}
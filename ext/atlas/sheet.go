@@ -0,0 +1,288 @@
+package atlas
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/pkg/errors"
+)
+
+// SpriteSheet is a packed sprite sheet imported into the atlas by one of the AddSheet* methods.
+type SpriteSheet struct {
+	// Frames are the frames of the sheet in the order they are listed in the sheet's data.
+	Frames []SheetFrame
+	// Tags are the frame tags (animations) of the sheet, only exported by Aseprite.
+	Tags []SheetTag
+
+	names map[string]int
+	tags  map[string]int
+}
+
+// SheetFrame is a single frame of a SpriteSheet.
+type SheetFrame struct {
+	Name    string
+	Texture TextureId
+	// Duration is the duration of the frame exported by Aseprite, zero otherwise.
+	Duration time.Duration
+}
+
+// SheetTag is a named, contiguous range of frames of a SpriteSheet.
+type SheetTag struct {
+	Name string
+	// Slice holds the frames of the tag, the first frame of the tag being frame 0 of the slice.
+	Slice SliceId
+	// Direction is the direction the tag should be played in: "forward", "reverse" or "pingpong".
+	Direction string
+	// Durations are the durations of the frames of the tag.
+	Durations []time.Duration
+}
+
+// Frame returns the texture of the frame with the given name.
+func (s *SpriteSheet) Frame(name string) (TextureId, bool) {
+	i, ok := s.names[name]
+	if !ok {
+		return TextureId{}, false
+	}
+	return s.Frames[i].Texture, true
+}
+
+// Tag returns the tag with the given name.
+func (s *SpriteSheet) Tag(name string) (SheetTag, bool) {
+	i, ok := s.tags[name]
+	if !ok {
+		return SheetTag{}, false
+	}
+	return s.Tags[i], true
+}
+
+// AddSheet loads a sprite sheet exported by Aseprite or TexturePacker to the atlas, given the
+// sheet's JSON data and image.
+func (a *Atlas) AddSheet(data []byte, img image.Image) *SpriteSheet {
+	return a.DefaultGroup().AddSheet(data, img)
+}
+
+// AddSheetFile loads a sprite sheet exported by Aseprite or TexturePacker to the atlas. The path is
+// the sheet's JSON file, the image is loaded relative to it.
+func (a *Atlas) AddSheetFile(path string, decoder pixel.DecoderFunc) *SpriteSheet {
+	return a.DefaultGroup().AddSheetFile(path, decoder)
+}
+
+// AddSheetEmbed loads an embedded sprite sheet exported by Aseprite or TexturePacker to the atlas.
+// The path is the sheet's JSON file, the image is loaded relative to it.
+func (a *Atlas) AddSheetEmbed(fs embed.FS, path string, decoder pixel.DecoderFunc) *SpriteSheet {
+	return a.DefaultGroup().AddSheetEmbed(fs, path, decoder)
+}
+
+// AddSheet loads a sprite sheet exported by Aseprite or TexturePacker to the atlas, given the
+// sheet's JSON data and image.
+//
+// Both the hash and the array JSON formats are supported. Every frame becomes a texture of its
+// own, so the frames can be packed tightly. Trimmed frames are packed trimmed, but have the bounds
+// of their original size and are drawn offset within it, and rotated frames are rotated back, so
+// they can be drawn just like untrimmed ones.
+func (g *Group) AddSheet(data []byte, img image.Image) *SpriteSheet {
+	sd, err := parseSheet(data)
+	if err != nil {
+		panic(errors.Wrap(err, "failed to parse sprite sheet"))
+	}
+
+	sheet := &SpriteSheet{
+		names: make(map[string]int),
+		tags:  make(map[string]int),
+	}
+	for i, f := range sd.Frames {
+		tex := g.AddImage(f.extract(img))
+		g.atlas.setName(tex.id, f.Filename)
+		if f.Trimmed && f.SourceSize.W > 0 && f.SourceSize.H > 0 {
+			r := f.SpriteSourceSize
+			g.atlas.setTrim(tex.id, trim{
+				rect: rect(r.X, r.Y, r.W, r.H),
+				size: image.Pt(f.SourceSize.W, f.SourceSize.H),
+			})
+		}
+		sheet.names[f.Filename] = i
+		sheet.Frames = append(sheet.Frames, SheetFrame{
+			Name:     f.Filename,
+			Texture:  tex,
+			Duration: time.Duration(f.Duration) * time.Millisecond,
+		})
+	}
+
+	for _, t := range sd.Meta.FrameTags {
+		if t.From < 0 || t.To < t.From || t.To >= len(sheet.Frames) {
+			panic(fmt.Errorf("sprite sheet tag %q has invalid frame range [%v, %v]", t.Name, t.From, t.To))
+		}
+		tag := SheetTag{
+			Name:      t.Name,
+			Slice:     SliceId{start: sheet.Frames[t.From].Texture, len: uint32(t.To - t.From + 1)},
+			Direction: t.Direction,
+		}
+		for _, f := range sheet.Frames[t.From : t.To+1] {
+			tag.Durations = append(tag.Durations, f.Duration)
+		}
+		sheet.tags[t.Name] = len(sheet.Tags)
+		sheet.Tags = append(sheet.Tags, tag)
+	}
+
+	return sheet
+}
+
+// AddSheetFile loads a sprite sheet exported by Aseprite or TexturePacker to the atlas. The path is
+// the sheet's JSON file, the image is loaded relative to it.
+func (g *Group) AddSheetFile(path string, decoder pixel.DecoderFunc) *SpriteSheet {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	sd, err := parseSheet(data)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to parse sprite sheet: %v", path))
+	}
+	img, err := pixel.ImageFromFile(filepath.Join(filepath.Dir(path), sd.Meta.Image), decoder)
+	if err != nil {
+		panic(err)
+	}
+	return g.AddSheet(data, img)
+}
+
+// AddSheetEmbed loads an embedded sprite sheet exported by Aseprite or TexturePacker to the atlas.
+// The path is the sheet's JSON file, the image is loaded relative to it.
+func (g *Group) AddSheetEmbed(fs embed.FS, p string, decoder pixel.DecoderFunc) *SpriteSheet {
+	data, err := fs.ReadFile(p)
+	if err != nil {
+		panic(err)
+	}
+	sd, err := parseSheet(data)
+	if err != nil {
+		panic(errors.Wrapf(err, "failed to parse sprite sheet: %v", p))
+	}
+	img, err := pixel.ImageFromEmbed(fs, path.Join(path.Dir(p), sd.Meta.Image), decoder)
+	if err != nil {
+		panic(err)
+	}
+	return g.AddSheet(data, img)
+}
+
+type sheetRect struct {
+	X, Y, W, H int
+}
+
+type sheetSize struct {
+	W, H int
+}
+
+// sheetFrame is a frame as exported by both Aseprite and TexturePacker.
+type sheetFrame struct {
+	Filename         string
+	Frame            sheetRect
+	Rotated          bool
+	Trimmed          bool
+	SpriteSourceSize sheetRect
+	SourceSize       sheetSize
+	Duration         int
+}
+
+type sheetData struct {
+	Frames []sheetFrame
+	Meta   struct {
+		Image     string
+		FrameTags []struct {
+			Name      string
+			From, To  int
+			Direction string
+		}
+	}
+}
+
+// parseSheet parses the JSON data of a sprite sheet. The frames are either an array, or an object
+// keyed by the frame names, in which case their order is kept.
+func parseSheet(data []byte) (*sheetData, error) {
+	var raw struct {
+		Frames json.RawMessage
+		Meta   json.RawMessage
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	sd := &sheetData{}
+	if len(raw.Meta) > 0 {
+		if err := json.Unmarshal(raw.Meta, &sd.Meta); err != nil {
+			return nil, errors.Wrap(err, "meta")
+		}
+	}
+
+	switch frames := bytes.TrimSpace(raw.Frames); {
+	case len(frames) == 0:
+		return nil, errors.New("no frames")
+	case frames[0] == '[':
+		if err := json.Unmarshal(frames, &sd.Frames); err != nil {
+			return nil, errors.Wrap(err, "frames")
+		}
+	default:
+		dec := json.NewDecoder(bytes.NewReader(frames))
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+			return nil, errors.New("frames must be an array or an object")
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, errors.Wrap(err, "frames")
+			}
+			var f sheetFrame
+			if err := dec.Decode(&f); err != nil {
+				return nil, errors.Wrapf(err, "frame %q", tok)
+			}
+			f.Filename = tok.(string)
+			sd.Frames = append(sd.Frames, f)
+		}
+	}
+
+	for _, f := range sd.Frames {
+		if f.Frame.W <= 0 || f.Frame.H <= 0 {
+			return nil, fmt.Errorf("frame %q is empty", f.Filename)
+		}
+	}
+	return sd, nil
+}
+
+// extract copies the frame out of the sheet's image, undoing the rotation.
+func (f sheetFrame) extract(img image.Image) image.Image {
+	min := img.Bounds().Min.Add(image.Pt(f.Frame.X, f.Frame.Y))
+	if f.Rotated {
+		// rotated frames are stored rotated 90 degrees clockwise, taking up h by w pixels
+		return rotateCCW(subImage(img, rect(min.X, min.Y, f.Frame.H, f.Frame.W)))
+	}
+	return subImage(img, rect(min.X, min.Y, f.Frame.W, f.Frame.H))
+}
+
+// trim is the original size of a texture trimmed by a sprite sheet, and the rectangle of the
+// texture within it, with the y axis pointing down.
+type trim struct {
+	rect image.Rectangle
+	size image.Point
+}
+
+func (a *Atlas) setTrim(id uint32, t trim) {
+	if a.trims == nil {
+		a.trims = make(map[uint32]trim)
+	}
+	a.trims[id] = t
+}
+
+// offset returns how far the center of the trimmed texture is from the center of its original
+// size.
+func (t trim) offset() pixel.Vec {
+	return pixel.V(
+		float64(t.rect.Min.X+t.rect.Max.X-t.size.X)/2,
+		-float64(t.rect.Min.Y+t.rect.Max.Y-t.size.Y)/2,
+	)
+}
@@ -0,0 +1,180 @@
+package atlas
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	red         = color.RGBA{255, 0, 0, 255}
+	green       = color.RGBA{0, 255, 0, 255}
+	blue        = color.RGBA{0, 0, 255, 255}
+	white       = color.RGBA{255, 255, 255, 255}
+	transparent = color.RGBA{}
+)
+
+// sheetImage is a sheet with a 2x2 red frame, a green 2x2 frame trimmed to its right column and a
+// 1x2 blue over white frame rotated clockwise.
+func sheetImage() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 5, 2))
+	for _, p := range []image.Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		img.Set(p.X, p.Y, red)
+	}
+	img.Set(2, 0, green)
+	img.Set(2, 1, green)
+	img.Set(3, 0, white)
+	img.Set(4, 0, blue)
+	return img
+}
+
+const asepriteHash = `{
+	"frames": {
+		"a": {"frame": {"x": 0, "y": 0, "w": 2, "h": 2}, "sourceSize": {"w": 2, "h": 2}, "duration": 100},
+		"b": {
+			"frame": {"x": 2, "y": 0, "w": 1, "h": 2}, "trimmed": true,
+			"spriteSourceSize": {"x": 1, "y": 0, "w": 1, "h": 2}, "sourceSize": {"w": 2, "h": 2},
+			"duration": 150
+		},
+		"c": {"frame": {"x": 3, "y": 0, "w": 1, "h": 2}, "rotated": true, "duration": 200}
+	},
+	"meta": {
+		"image": "sheet.png",
+		"frameTags": [{"name": "walk", "from": 1, "to": 2, "direction": "pingpong"}]
+	}
+}`
+
+const texturePackerArray = `{
+	"frames": [
+		{"filename": "a", "frame": {"x": 0, "y": 0, "w": 2, "h": 2}},
+		{
+			"filename": "b", "frame": {"x": 2, "y": 0, "w": 1, "h": 2}, "trimmed": true,
+			"spriteSourceSize": {"x": 1, "y": 0, "w": 1, "h": 2}, "sourceSize": {"w": 2, "h": 2}
+		},
+		{"filename": "c", "frame": {"x": 3, "y": 0, "w": 1, "h": 2}, "rotated": true}
+	],
+	"meta": {"image": "sheet.png"}
+}`
+
+// textureImage draws the texture to a canvas of the same size.
+func textureImage(tex TextureId) *image.RGBA {
+	c := software.NewCanvas(tex.Bounds())
+	tex.Draw(c, pixel.IM.Moved(c.Bounds().Center()))
+	return c.Image()
+}
+
+func assertPixels(t *testing.T, img *image.RGBA, want ...color.RGBA) {
+	t.Helper()
+	var got []color.RGBA
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			got = append(got, img.RGBAAt(x, y))
+		}
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestAtlas_AddSheet(t *testing.T) {
+	for name, data := range map[string]string{"aseprite": asepriteHash, "texturepacker": texturePackerArray} {
+		t.Run(name, func(t *testing.T) {
+			var a Atlas
+			sheet := a.AddSheet([]byte(data), sheetImage())
+			a.Pack()
+
+			require.Len(t, sheet.Frames, 3)
+			for i, name := range []string{"a", "b", "c"} {
+				assert.Equal(t, name, sheet.Frames[i].Name)
+				tex, ok := sheet.Frame(name)
+				assert.True(t, ok)
+				assert.Equal(t, sheet.Frames[i].Texture, tex)
+			}
+			_, ok := sheet.Frame("d")
+			assert.False(t, ok)
+
+			a0, _ := sheet.Frame("a")
+			b, _ := sheet.Frame("b")
+			c, _ := sheet.Frame("c")
+			assertPixels(t, textureImage(a0), red, red, red, red)
+			assertPixels(t, textureImage(b), transparent, green, transparent, green)
+			assertPixels(t, textureImage(c), blue, white)
+
+			// the trimmed frame is packed without its transparent column
+			assert.Equal(t, pixel.R(0, 0, 2, 2), b.Bounds())
+			assert.Equal(t, 1.0, b.Frame().W())
+		})
+	}
+}
+
+func TestAtlas_AddSheetTrimmed(t *testing.T) {
+	// a 3x3 frame trimmed to its top right pixel, and a 2x3 frame trimmed to its bottom left column
+	// and rotated
+	img := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	img.Set(0, 0, red)
+	img.Set(1, 0, blue)
+	img.Set(2, 0, green)
+	data := `{"frames": {
+		"a": {
+			"frame": {"x": 0, "y": 0, "w": 1, "h": 1}, "trimmed": true,
+			"spriteSourceSize": {"x": 2, "y": 0, "w": 1, "h": 1}, "sourceSize": {"w": 3, "h": 3}
+		},
+		"b": {
+			"frame": {"x": 1, "y": 0, "w": 1, "h": 2}, "trimmed": true, "rotated": true,
+			"spriteSourceSize": {"x": 0, "y": 1, "w": 1, "h": 2}, "sourceSize": {"w": 2, "h": 3}
+		}
+	}}`
+
+	var a Atlas
+	sheet := a.AddSheet([]byte(data), img)
+	a.Pack()
+
+	tex := sheet.Frames[0].Texture
+	assert.Equal(t, pixel.R(0, 0, 3, 3), tex.Bounds())
+	assert.Equal(t, 1.0, tex.Frame().W())
+	assertPixels(t, textureImage(tex),
+		transparent, transparent, red,
+		transparent, transparent, transparent,
+		transparent, transparent, transparent,
+	)
+
+	tex = sheet.Frames[1].Texture
+	assert.Equal(t, pixel.R(0, 0, 2, 3), tex.Bounds())
+	assertPixels(t, textureImage(tex),
+		transparent, transparent,
+		green, transparent,
+		blue, transparent,
+	)
+}
+
+func TestAtlas_AddSheetTags(t *testing.T) {
+	var a Atlas
+	sheet := a.AddSheet([]byte(asepriteHash), sheetImage())
+	a.Pack()
+
+	assert.Equal(t, 100*time.Millisecond, sheet.Frames[0].Duration)
+
+	walk, ok := sheet.Tag("walk")
+	require.True(t, ok)
+	assert.Equal(t, "pingpong", walk.Direction)
+	assert.Equal(t, []time.Duration{150 * time.Millisecond, 200 * time.Millisecond}, walk.Durations)
+	assert.Equal(t, uint32(2), walk.Slice.Len())
+	assert.Equal(t, sheet.Frames[1].Texture.ID(), walk.Slice.Frame(0).ID())
+	assert.Equal(t, sheet.Frames[2].Texture.ID(), walk.Slice.Frame(1).ID())
+
+	_, ok = sheet.Tag("run")
+	assert.False(t, ok)
+}
+
+func TestAtlas_AddSheetInvalid(t *testing.T) {
+	var a Atlas
+	assert.Panics(t, func() { a.AddSheet([]byte(`{"frames": 1}`), sheetImage()) })
+	assert.Panics(t, func() { a.AddSheet([]byte(`{"frames": {"a": {}}}`), sheetImage()) })
+	assert.Panics(t, func() {
+		a.AddSheet([]byte(`{"frames": [{"frame": {"w": 1, "h": 1}}], "meta": {"frameTags": [{"from": 0, "to": 1}]}}`), sheetImage())
+	})
+}
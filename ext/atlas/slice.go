@@ -9,6 +9,11 @@ type SliceId struct {
 	len   uint32
 }
 
+// Len returns the number of frames in the slice.
+func (s SliceId) Len() uint32 {
+	return s.len
+}
+
 // Frame returns a TextureId representing the given frame of the slice
 func (s SliceId) Frame(frame uint32) TextureId {
 	if frame >= s.len {
@@ -2,6 +2,7 @@ package atlas
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/gopxl/pixel/v2"
 )
@@ -16,9 +17,10 @@ func (a *Atlas) Get(id uint32) TextureId {
 
 // TextureId is a reference to a texture in an atlas.
 type TextureId struct {
-	id     uint32
-	atlas  *Atlas
-	sprite *pixel.Sprite
+	id         uint32
+	atlas      *Atlas
+	sprite     *pixel.Sprite
+	generation uint32
 }
 
 // ID returns the ID of the texture in the atlas.
@@ -26,7 +28,8 @@ func (t TextureId) ID() uint32 {
 	return t.id
 }
 
-// Frame returns the frame of the texture in the atlas.
+// Frame returns the frame of the texture in the atlas. If the texture is stored rotated, so is the
+// frame.
 func (t TextureId) Frame() pixel.Rect {
 	if !t.atlas.clean {
 		panic("Atlas is dirty, call atlas.Pack() first")
@@ -43,7 +46,8 @@ func (t TextureId) Frame() pixel.Rect {
 	return r
 }
 
-// Bounds returns the bounds of the texture in the atlas.
+// Bounds returns the bounds of the texture in the atlas. The bounds of a trimmed frame of a sprite
+// sheet are those of its original size.
 func (t TextureId) Bounds() pixel.Rect {
 	if !t.atlas.clean {
 		panic("Atlas is dirty, call atlas.Pack() first")
@@ -53,6 +57,12 @@ func (t TextureId) Bounds() pixel.Rect {
 	if !has {
 		panic(fmt.Sprintf("id: %v does not exist in atlas", t.id))
 	}
+	if tr, ok := t.atlas.trims[t.id]; ok {
+		return pixelRect(0, 0, tr.size.X, tr.size.Y)
+	}
+	if s.rotated {
+		return pixelRect(0, 0, s.rect.Dy(), s.rect.Dx())
+	}
 	return pixelRect(0, 0, s.rect.Dx(), s.rect.Dy())
 }
 
@@ -67,9 +77,19 @@ func (t *TextureId) Draw(target pixel.Target, m pixel.Matrix) {
 		panic(fmt.Sprintf("id [%v] does not exist in packer", t.id))
 	}
 
-	if t.sprite == nil {
+	// the atlas may have been packed again since the sprite was made
+	if t.sprite == nil || t.generation != t.atlas.generation {
 		frame := t.Frame()
 		t.sprite = pixel.NewSprite(t.atlas.internal[l.index], frame)
+		t.generation = t.atlas.generation
+	}
+	if tr, ok := t.atlas.trims[t.id]; ok {
+		// trimmed textures are drawn where they are in their original size
+		m = pixel.IM.Moved(tr.offset()).Chained(m)
+	}
+	if l.rotated {
+		// rotated textures are stored rotated clockwise
+		m = pixel.IM.Rotated(pixel.ZV, math.Pi/2).Chained(m)
 	}
 	t.sprite.Draw(target, m)
 }
@@ -4,14 +4,14 @@ import (
 	"errors"
 	"time"
 
-	"github.com/gopxl/pixel/v2/backends/opengl"
+	"github.com/gopxl/pixel/v2"
 )
 
 type EasyWindow interface {
-	Win() *opengl.Window // get underlying GLFW window
-	Setup() error        // setup window
-	Update() error       // update window
-	Draw() error         // draw to window
+	Win() pixel.Window // get underlying window
+	Setup() error      // setup window
+	Update() error     // update window
+	Draw() error       // draw to window
 }
 
 type WindowManager struct {
@@ -93,7 +93,7 @@ func (wm *WindowManager) Loop() error {
 			return err
 		}
 
-		// update GFLW window
+		// update windows
 		for _, win := range wm.Windows {
 			win.Win().Update()
 		}
@@ -0,0 +1,238 @@
+package imdraw
+
+import (
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// FillRule specifies which parts of a polygon are filled if its contours intersect or contain each
+// other.
+type FillRule int
+
+const (
+	// NonZero fills the parts of a polygon its contours wind around in total, clockwise or
+	// counterclockwise. A contour inside of another one is a hole if it goes in the opposite
+	// direction.
+	NonZero FillRule = iota
+
+	// EvenOdd fills the parts of a polygon inside of an odd number of its contours. A contour inside
+	// of another one is always a hole.
+	EvenOdd
+)
+
+// edge is a non-horizontal edge of a polygon, from the lower point a to the upper point b.
+type edge struct {
+	a, b point
+	dir  int
+}
+
+// x returns the horizontal position of the edge at height y.
+func (e edge) x(y float64) float64 {
+	return e.a.pos.X + (y-e.a.pos.Y)/(e.b.pos.Y-e.a.pos.Y)*(e.b.pos.X-e.a.pos.X)
+}
+
+// at returns the point of the edge at height y, interpolating its properties.
+func (e edge) at(y float64) point {
+	t := (y - e.a.pos.Y) / (e.b.pos.Y - e.a.pos.Y)
+	return point{
+		pos: pixel.V(e.x(y), y),
+		col: e.a.col.Add(e.b.col.Sub(e.a.col).Scaled(t)),
+		pic: pixel.Lerp(e.a.pic, e.b.pic, t),
+		in:  e.a.in + t*(e.b.in-e.a.in),
+	}
+}
+
+// splitContours splits the points at the ends of the contours.
+func splitContours(points []point, contours []int) [][]point {
+	var split [][]point
+	start := 0
+	for _, end := range append(contours, len(points)) {
+		if end > start {
+			split = append(split, points[start:end])
+		}
+		start = end
+	}
+	return split
+}
+
+// isConvex reports whether the points form a single convex polygon, which can be drawn as a fan of
+// triangles.
+func isConvex(points []point) bool {
+	total := 0.0
+	var first, prev pixel.Vec
+	for i := 0; i <= len(points); i++ {
+		dir := first
+		if i < len(points) {
+			dir = points[i].pos.To(points[(i+1)%len(points)].pos)
+		}
+		if dir == pixel.ZV {
+			continue
+		}
+		if prev == pixel.ZV {
+			first = dir
+		} else {
+			angle := math.Atan2(prev.Cross(dir), prev.Dot(dir))
+			if angle*total < 0 {
+				return false
+			}
+			total += angle
+		}
+		prev = dir
+	}
+	// a star turns in one direction, but more than once
+	return math.Abs(math.Abs(total)-2*math.Pi) < 1e-6
+}
+
+// fillContours fills the polygon with the given contours according to the fill rule. The polygon is
+// cut into horizontal bands at the heights of all vertices and intersections of edges, so that the
+// edges don't cross within a band. The filled spans between the edges of each band are trapezoids,
+// extended over the following bands as long as they're bounded by the same edges.
+func (imd *IMDraw) fillContours(contours [][]point, rule FillRule) {
+	var edges []edge
+	var ys []float64
+	for _, c := range contours {
+		for i := range c {
+			a, b := c[i], c[(i+1)%len(c)]
+			ys = append(ys, a.pos.Y)
+			switch {
+			case a.pos.Y < b.pos.Y:
+				edges = append(edges, edge{a: a, b: b, dir: 1})
+			case a.pos.Y > b.pos.Y:
+				edges = append(edges, edge{a: b, b: a, dir: -1})
+			}
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].a.pos.Y < edges[j].a.pos.Y })
+
+	// edges can only cross if they overlap vertically, so each edge is intersected with the edges
+	// that started below it and haven't ended yet
+	var active []int
+	for i, e := range edges {
+		active = slices.DeleteFunc(active, func(f int) bool { return edges[f].b.pos.Y <= e.a.pos.Y })
+		for _, f := range active {
+			if y, ok := intersectEdges(edges[f], e); ok {
+				ys = append(ys, y)
+			}
+		}
+		active = append(active, i)
+	}
+	sort.Float64s(ys)
+	ys = slices.Compact(ys)
+
+	// a span is filled between two edges from some height on; as the spans of a band don't overlap,
+	// they're identified by their left edge
+	active = active[:0]
+	var (
+		xs      = make([]float64, len(edges))
+		next    int
+		open    []int
+		current []int
+		right   = make([]int, len(edges))
+		bottom  = make([]float64, len(edges))
+		seen    = make([]int, len(edges))
+	)
+	for i := range right {
+		right[i] = -1
+		seen[i] = -1
+	}
+	off := imd.tri.Len()
+	closeSpan := func(left int, y float64) {
+		l, r, y0 := edges[left], edges[right[left]], bottom[left]
+		imd.appendTriangle(l.at(y0), r.at(y0), r.at(y))
+		imd.appendTriangle(l.at(y0), r.at(y), l.at(y))
+		right[left] = -1
+	}
+
+	for i := 0; i+1 < len(ys); i++ {
+		y0, y1 := ys[i], ys[i+1]
+		active = slices.DeleteFunc(active, func(e int) bool { return edges[e].b.pos.Y <= y0 })
+		for next < len(edges) && edges[next].a.pos.Y <= y0 {
+			if edges[next].b.pos.Y > y0 {
+				active = append(active, next)
+			}
+			next++
+		}
+		// the order of the edges changes little from band to band, which suits insertion sort
+		for j, e := range active {
+			xs[e] = edges[e].x((y0 + y1) / 2)
+			for ; j > 0 && xs[active[j-1]] > xs[e]; j-- {
+				active[j] = active[j-1]
+			}
+			active[j] = e
+		}
+
+		current = current[:0]
+		winding := 0
+		for j, e := range active {
+			inside := winding != 0
+			if rule == EvenOdd {
+				inside = winding%2 != 0
+			}
+			if inside {
+				left := active[j-1]
+				if right[left] == e {
+					seen[left] = i
+				}
+				current = append(current, left, e)
+			}
+			winding += edges[e].dir
+		}
+
+		// the spans bounded by other edges than in the band below end here
+		for _, left := range open {
+			if seen[left] != i {
+				closeSpan(left, y0)
+			}
+		}
+		open = open[:0]
+		for j := 0; j < len(current); j += 2 {
+			left := current[j]
+			if seen[left] != i {
+				right[left], bottom[left] = current[j+1], y0
+			}
+			open = append(open, left)
+		}
+	}
+	for _, left := range open {
+		closeSpan(left, ys[len(ys)-1])
+	}
+
+	imd.applyMatrixAndMask(off)
+	imd.batch.Dirty()
+}
+
+// appendTriangle adds a triangle between the points without transforming it.
+func (imd *IMDraw) appendTriangle(a, b, c point) {
+	n := imd.tri.Len()
+	imd.tri.SetLen(n + 3)
+	for i, p := range [...]point{a, b, c} {
+		tri := &(*imd.tri)[n+i]
+		tri.Position = p.pos
+		tri.Color = p.col
+		tri.Picture = p.pic
+		tri.Intensity = p.in
+	}
+}
+
+// intersectEdges returns the height at which the edges cross, if they cross strictly between their
+// ends.
+func intersectEdges(e, f edge) (float64, bool) {
+	if e.b.pos.Y <= f.a.pos.Y || f.b.pos.Y <= e.a.pos.Y {
+		return 0, false
+	}
+	p, r := e.a.pos, e.a.pos.To(e.b.pos)
+	q, s := f.a.pos, f.a.pos.To(f.b.pos)
+	denom := r.Cross(s)
+	if denom == 0 {
+		return 0, false
+	}
+	t := p.To(q).Cross(s) / denom
+	u := p.To(q).Cross(r) / denom
+	if t <= 0 || t >= 1 || u <= 0 || u >= 1 {
+		return 0, false
+	}
+	return p.Y + t*r.Y, true
+}
@@ -0,0 +1,162 @@
+package imdraw
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+)
+
+// area returns the total area of the drawn triangles.
+func area(imd *IMDraw) float64 {
+	total := 0.0
+	for i := 0; i+2 < imd.tri.Len(); i += 3 {
+		a, b, c := (*imd.tri)[i].Position, (*imd.tri)[i+1].Position, (*imd.tri)[i+2].Position
+		total += math.Abs(a.To(b).Cross(a.To(c))) / 2
+	}
+	return total
+}
+
+func square(min, max float64) []pixel.Vec {
+	return []pixel.Vec{pixel.V(min, min), pixel.V(max, min), pixel.V(max, max), pixel.V(min, max)}
+}
+
+func reversed(pts []pixel.Vec) []pixel.Vec {
+	r := make([]pixel.Vec, len(pts))
+	for i, p := range pts {
+		r[len(pts)-1-i] = p
+	}
+	return r
+}
+
+func TestIMDraw_PolygonFill(t *testing.T) {
+	tests := []struct {
+		name     string
+		contours [][]pixel.Vec
+		rule     FillRule
+		area     float64
+	}{
+		{
+			name: "concave",
+			contours: [][]pixel.Vec{{
+				pixel.V(0, 0), pixel.V(30, 0), pixel.V(30, 20), pixel.V(20, 20),
+				pixel.V(20, 10), pixel.V(10, 10), pixel.V(10, 20), pixel.V(0, 20),
+			}},
+			area: 500,
+		},
+		{
+			name:     "self-intersecting",
+			contours: [][]pixel.Vec{{pixel.V(0, 0), pixel.V(10, 10), pixel.V(10, 0), pixel.V(0, 10)}},
+			area:     50,
+		},
+		{
+			name:     "hole",
+			contours: [][]pixel.Vec{square(0, 10), reversed(square(3, 7))},
+			area:     84,
+		},
+		{
+			name:     "nonzero inner contour in the same direction",
+			contours: [][]pixel.Vec{square(0, 10), square(3, 7)},
+			area:     100,
+		},
+		{
+			name:     "even-odd inner contour in the same direction",
+			contours: [][]pixel.Vec{square(0, 10), square(3, 7)},
+			rule:     EvenOdd,
+			area:     84,
+		},
+		{
+			name:     "nonzero overlap",
+			contours: [][]pixel.Vec{square(0, 10), square(5, 15)},
+			area:     175,
+		},
+		{
+			name:     "even-odd overlap",
+			contours: [][]pixel.Vec{square(0, 10), square(5, 15)},
+			rule:     EvenOdd,
+			area:     150,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			imd := New(nil)
+			imd.FillRule = test.rule
+			for _, c := range test.contours {
+				imd.Push(c...)
+				imd.ClosePath()
+			}
+			imd.Polygon(0)
+			assert.InDelta(t, test.area, area(imd), 1e-9)
+			assert.Empty(t, imd.points)
+			assert.Empty(t, imd.contours)
+		})
+	}
+}
+
+func TestIMDraw_PolygonFillStar(t *testing.T) {
+	star := make([]pixel.Vec, 5)
+	for i := range star {
+		star[i] = pixel.V(10, 0).Rotated(math.Pi/2 + float64(i)*4*math.Pi/5).Add(pixel.V(20, 20))
+	}
+
+	for _, rule := range []FillRule{NonZero, EvenOdd} {
+		imd := New(nil)
+		imd.FillRule = rule
+		imd.Push(star...)
+		imd.Polygon(0)
+
+		c := software.NewCanvas(pixel.R(0, 0, 40, 40))
+		imd.Draw(c)
+		assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(20.5, 27.5)), "tip")
+		assert.Equal(t, rule == NonZero, c.Color(pixel.V(20.5, 20.5)) == pixel.Alpha(1), "center")
+	}
+}
+
+func TestIMDraw_PolygonFillConvex(t *testing.T) {
+	imd := New(nil)
+	for i := 0; i < 6; i++ {
+		imd.Push(pixel.V(10, 0).Rotated(float64(i) * math.Pi / 3))
+	}
+	imd.Polygon(0)
+	// convex polygons are still drawn as a fan of triangles
+	assert.Equal(t, 3*4, imd.tri.Len())
+	assert.InDelta(t, 3*math.Sqrt(3)/2*100, area(imd), 1e-9)
+}
+
+func TestIMDraw_PolygonFillColors(t *testing.T) {
+	imd := New(nil)
+	imd.Color = pixel.RGB(1, 0, 0)
+	imd.Push(pixel.V(0, 0), pixel.V(10, 0))
+	imd.Color = pixel.RGB(0, 0, 1)
+	imd.Push(pixel.V(10, 10), pixel.V(5, 5), pixel.V(0, 10))
+	imd.Polygon(0)
+
+	// the colors are interpolated along the edges
+	for _, v := range *imd.tri {
+		if v.Position.X == 0 || v.Position.X == 10 {
+			assert.InDelta(t, v.Position.Y/10, v.Color.B, 1e-9)
+			assert.InDelta(t, 1-v.Position.Y/10, v.Color.R, 1e-9)
+		}
+	}
+	assert.InDelta(t, 75, area(imd), 1e-9)
+}
+
+func TestIMDraw_PolygonOutlineContours(t *testing.T) {
+	imd := New(nil)
+	imd.Push(square(2, 8)...)
+	imd.ClosePath()
+	imd.Push(square(12, 18)...)
+	imd.ClosePath()
+	imd.Polygon(2)
+
+	c := software.NewCanvas(pixel.R(0, 0, 20, 20))
+	imd.Draw(c)
+	assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(2.5, 5.5)))
+	assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(17.5, 15.5)))
+	// the contours aren't connected
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(10.5, 10.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(5.5, 5.5)))
+}
@@ -0,0 +1,255 @@
+package imdraw
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// GradientStop is the color of a gradient at an offset, which goes from 0 at the start of the
+// gradient to 1 at its end.
+type GradientStop struct {
+	Offset float64
+	Color  pixel.RGBA
+}
+
+// Gradient is a paint source coloring shapes by the positions of their vertices. The colors of a
+// gradient are multiplied by the colors of the Pushed points.
+//
+// The colors are interpolated between the stops of a gradient, which need to be sorted by their
+// offsets. Before the first stop and after the last one, the color of the stop is used. Two stops
+// at the same offset make a sharp edge. A gradient without stops is transparent.
+type Gradient interface {
+	// At returns the color of the gradient at the position.
+	At(pos pixel.Vec) pixel.RGBA
+
+	// offset returns the offset of the gradient at the position.
+	offset(pos pixel.Vec) float64
+
+	// split splits the convex polygon into pieces, between the vertices of which the offset of the
+	// gradient changes linearly, or close to it, and appends them to dst.
+	split(dst [][]point, poly []point, precision int) [][]point
+
+	// stops returns the stops of the gradient.
+	stops() []GradientStop
+}
+
+// LinearGradient changes its color along the line from Start to End, and is the same along the
+// lines perpendicular to it.
+type LinearGradient struct {
+	Start, End pixel.Vec
+	Stops      []GradientStop
+}
+
+var _ Gradient = (*LinearGradient)(nil)
+
+// At returns the color of the gradient at the position.
+func (lg *LinearGradient) At(pos pixel.Vec) pixel.RGBA {
+	t := lg.offset(pos)
+	return gradientColor(lg.Stops, t, t)
+}
+
+func (lg *LinearGradient) offset(pos pixel.Vec) float64 {
+	axis := lg.Start.To(lg.End)
+	if axis == pixel.ZV {
+		// as in SVG, the color of the last stop is used everywhere
+		return math.Inf(1)
+	}
+	return lg.Start.To(pos).Dot(axis) / axis.Dot(axis)
+}
+
+func (lg *LinearGradient) split(dst [][]point, poly []point, precision int) [][]point {
+	axis := lg.Start.To(lg.End)
+	if axis == pixel.ZV {
+		return append(dst, poly)
+	}
+	normal := axis.Unit()
+	var cuts []float64
+	for _, s := range lg.Stops {
+		cuts = append(cuts, lg.Start.Dot(normal)+s.Offset*axis.Len())
+	}
+	return cutPolygon(dst, poly, normal, cuts)
+}
+
+func (lg *LinearGradient) stops() []GradientStop {
+	return lg.Stops
+}
+
+// RadialGradient changes its color from Center, where the offset is 0, to an ellipse of the given
+// radius in each axis around it, where the offset is 1.
+//
+// Shapes are split into sectors around the Center to approximate the circular changes of the color.
+// Their number is the Precision of the IMDraw at the time of drawing.
+type RadialGradient struct {
+	Center pixel.Vec
+	Radius pixel.Vec
+	Stops  []GradientStop
+}
+
+var _ Gradient = (*RadialGradient)(nil)
+
+// At returns the color of the gradient at the position.
+func (rg *RadialGradient) At(pos pixel.Vec) pixel.RGBA {
+	t := rg.offset(pos)
+	return gradientColor(rg.Stops, t, t)
+}
+
+func (rg *RadialGradient) offset(pos pixel.Vec) float64 {
+	if rg.Radius.X == 0 || rg.Radius.Y == 0 {
+		return math.Inf(1)
+	}
+	return rg.normalize(pos).Len()
+}
+
+// normalize maps the ellipse of the gradient to the unit circle.
+func (rg *RadialGradient) normalize(pos pixel.Vec) pixel.Vec {
+	d := rg.Center.To(pos)
+	return pixel.V(d.X/rg.Radius.X, d.Y/rg.Radius.Y)
+}
+
+func (rg *RadialGradient) split(dst [][]point, poly []point, precision int) [][]point {
+	if rg.Radius.X == 0 || rg.Radius.Y == 0 {
+		return append(dst, poly)
+	}
+
+	// the polygon is split in the space of the unit circle, as lines stay lines in it
+	pieces := [][]point{make([]point, len(poly))}
+	for i, p := range poly {
+		pieces[0][i] = p
+		pieces[0][i].pos = rg.normalize(p.pos)
+	}
+
+	// split it into sectors by lines through the center
+	n := max(precision, 4)
+	n += n % 2
+	sector := 2 * math.Pi / float64(n)
+	for i := 0; i < n/2; i++ {
+		normal := pixel.V(0, 1).Rotated(float64(i) * sector)
+		var split [][]point
+		for _, piece := range pieces {
+			split = cutPolygon(split, piece, normal, []float64{0})
+		}
+		pieces = split
+	}
+
+	// within a sector, the circles of the stops are close to lines perpendicular to its bisector
+	cuts := make([]float64, len(rg.Stops))
+	for i, s := range rg.Stops {
+		cuts[i] = s.Offset
+	}
+	off := len(dst)
+	for _, piece := range pieces {
+		angle := centroid(piece).Angle()
+		bisector := (math.Floor(angle/sector) + 0.5) * sector
+		dst = cutPolygon(dst, piece, pixel.V(1, 0).Rotated(bisector), cuts)
+	}
+
+	for _, piece := range dst[off:] {
+		for i := range piece {
+			piece[i].pos = rg.Center.Add(piece[i].pos.ScaledXY(rg.Radius))
+		}
+	}
+	return dst
+}
+
+func (rg *RadialGradient) stops() []GradientStop {
+	return rg.Stops
+}
+
+// gradientColor returns the color of the stops at the offset t. The stops to interpolate between
+// are chosen by the center offset, so that the pieces of shapes on each side of a sharp edge get
+// the colors of their side.
+func gradientColor(stops []GradientStop, t, center float64) pixel.RGBA {
+	if len(stops) == 0 {
+		return pixel.RGBA{}
+	}
+	i := sort.Search(len(stops), func(i int) bool { return stops[i].Offset > center })
+	if i == 0 {
+		return stops[0].Color
+	}
+	if i == len(stops) {
+		return stops[len(stops)-1].Color
+	}
+	a, b := stops[i-1], stops[i]
+	s := pixel.Clamp((t-a.Offset)/(b.Offset-a.Offset), 0, 1)
+	return a.Color.Add(b.Color.Sub(a.Color).Scaled(s))
+}
+
+// paint splits the triangles drawn from the offset on by the Gradient and colors their vertices.
+func (imd *IMDraw) paint(off int) {
+	var triangles []point
+	for _, v := range (*imd.tri)[off:] {
+		triangles = append(triangles, point{
+			pos: v.Position,
+			col: v.Color,
+			pic: v.Picture,
+			in:  v.Intensity,
+		})
+	}
+	imd.tri.SetLen(off)
+
+	stops := imd.Gradient.stops()
+	var pieces [][]point
+	for i := 0; i+2 < len(triangles); i += 3 {
+		pieces = imd.Gradient.split(pieces[:0], triangles[i:i+3], imd.Precision)
+		for _, piece := range pieces {
+			// the piece lies between two stops, except for the errors of approximation
+			center := imd.Gradient.offset(centroid(piece))
+			for j := range piece {
+				t := imd.Gradient.offset(piece[j].pos)
+				piece[j].col = piece[j].col.Mul(gradientColor(stops, t, center))
+			}
+			for j := 1; j+1 < len(piece); j++ {
+				imd.appendTriangle(piece[0], piece[j], piece[j+1])
+			}
+		}
+	}
+}
+
+// cutPolygon cuts the convex polygon by the lines of the points p with p·normal equal to each of
+// the cuts, which are sorted, and appends the pieces to dst.
+func cutPolygon(dst [][]point, poly []point, normal pixel.Vec, cuts []float64) [][]point {
+	for _, c := range cuts {
+		var below []point
+		below, poly = splitPolygon(poly, normal, c)
+		if len(below) >= 3 {
+			dst = append(dst, below)
+		}
+		if len(poly) < 3 {
+			return dst
+		}
+	}
+	return append(dst, poly)
+}
+
+// splitPolygon splits the convex polygon by the line of the points p with p·normal equal to c.
+// Vertices on the line belong to both parts.
+func splitPolygon(poly []point, normal pixel.Vec, c float64) (below, above []point) {
+	const epsilon = 1e-9
+	for i, p := range poly {
+		q := poly[(i+1)%len(poly)]
+		sp, sq := p.pos.Dot(normal)-c, q.pos.Dot(normal)-c
+		if sp <= epsilon {
+			below = append(below, p)
+		}
+		if sp >= -epsilon {
+			above = append(above, p)
+		}
+		if (sp < -epsilon && sq > epsilon) || (sp > epsilon && sq < -epsilon) {
+			x := lerpPoint(p, q, sp/(sp-sq))
+			below = append(below, x)
+			above = append(above, x)
+		}
+	}
+	return below, above
+}
+
+// centroid returns the average position of the vertices of the polygon.
+func centroid(poly []point) pixel.Vec {
+	var sum pixel.Vec
+	for _, p := range poly {
+		sum = sum.Add(p.pos)
+	}
+	return sum.Scaled(1 / float64(len(poly)))
+}
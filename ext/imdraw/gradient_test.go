@@ -0,0 +1,150 @@
+package imdraw
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/gopxl/pixel/v2/pixeltest"
+)
+
+var (
+	red   = pixel.RGB(1, 0, 0)
+	green = pixel.RGB(0, 1, 0)
+	blue  = pixel.RGB(0, 0, 1)
+)
+
+func TestLinearGradient_At(t *testing.T) {
+	lg := &LinearGradient{
+		Start: pixel.V(10, 0),
+		End:   pixel.V(30, 0),
+		Stops: []GradientStop{{0, red}, {0.5, green}, {0.5, blue}, {1, red}},
+	}
+	assert.Equal(t, red, lg.At(pixel.V(0, 5)))
+	assert.Equal(t, red.Add(green).Scaled(0.5), lg.At(pixel.V(15, -5)))
+	assert.Equal(t, blue, lg.At(pixel.V(20, 0)))
+	assert.Equal(t, red, lg.At(pixel.V(40, 0)))
+
+	lg.End = lg.Start
+	assert.Equal(t, red, lg.At(pixel.V(0, 0)))
+	lg.Stops = nil
+	assert.Equal(t, pixel.RGBA{}, lg.At(pixel.V(0, 0)))
+}
+
+func TestRadialGradient_At(t *testing.T) {
+	rg := &RadialGradient{
+		Center: pixel.V(10, 10),
+		Radius: pixel.V(20, 10),
+		Stops:  []GradientStop{{0, red}, {1, blue}},
+	}
+	assert.Equal(t, red, rg.At(pixel.V(10, 10)))
+	pixeltest.AssertColor(t, red.Add(blue).Scaled(0.5), rg.At(pixel.V(20, 10)), 1e-9)
+	pixeltest.AssertColor(t, red.Add(blue).Scaled(0.5), rg.At(pixel.V(10, 5)), 1e-9)
+	assert.Equal(t, blue, rg.At(pixel.V(10, 30)))
+}
+
+func TestIMDraw_LinearGradient(t *testing.T) {
+	imd := New(nil)
+	imd.Gradient = &LinearGradient{
+		Start: pixel.V(0, 0),
+		End:   pixel.V(40, 0),
+		Stops: []GradientStop{{0.25, red}, {0.5, green}, {0.5, blue}, {0.75, red}},
+	}
+	imd.Push(pixel.V(0, 0), pixel.V(40, 10))
+	imd.Rectangle(0)
+	assert.InDelta(t, 400, area(imd), 1e-9)
+
+	// the vertices are colored exactly, so the colors between them are too
+	for _, v := range *imd.tri {
+		if v.Position.X != 20 {
+			pixeltest.AssertColor(t, imd.Gradient.At(v.Position), v.Color, 1e-9)
+		}
+	}
+	c := software.NewCanvas(pixel.R(0, 0, 40, 10))
+	imd.Draw(c)
+	for x := 0.5; x < 40; x++ {
+		// the canvas stores 8 bits per channel
+		want := imd.Gradient.At(pixel.V(x, 5))
+		pixeltest.AssertColor(t, want, c.Color(pixel.V(x, 5.5)), 1.0/255, "x=%v", x)
+	}
+
+	// the colors of the points are multiplied
+	imd.Clear()
+	imd.Color = pixel.Alpha(0.5)
+	imd.Push(pixel.V(0, 0), pixel.V(40, 10))
+	imd.Rectangle(0)
+	for _, v := range *imd.tri {
+		if v.Position.X != 20 {
+			pixeltest.AssertColor(t, imd.Gradient.At(v.Position).Mul(pixel.Alpha(0.5)), v.Color, 1e-9)
+		}
+	}
+}
+
+func TestIMDraw_RadialGradient(t *testing.T) {
+	imd := New(nil)
+	imd.Gradient = &RadialGradient{
+		Center: pixel.V(20, 20),
+		Radius: pixel.V(20, 20),
+		Stops:  []GradientStop{{0, red}, {0.5, green}, {1, blue}},
+	}
+	imd.Push(pixel.V(20, 20))
+	imd.Circle(20, 0)
+	imd.Push(pixel.V(0, 0), pixel.V(10, 0), pixel.V(0, 10))
+	imd.Polygon(0)
+
+	// the vertices on the lines approximating the circles of the stops are a bit off
+	for _, v := range *imd.tri {
+		pixeltest.AssertColor(t, imd.Gradient.At(v.Position), v.Color, 0.005)
+	}
+	c := software.NewCanvas(pixel.R(0, 0, 40, 40))
+	imd.Draw(c)
+	for _, pos := range []pixel.Vec{
+		pixel.V(20.5, 20.5), pixel.V(27.5, 24.5), pixel.V(5.5, 29.5), pixel.V(2.5, 2.5),
+	} {
+		pixeltest.AssertColor(t, imd.Gradient.At(pos), c.Color(pos), 0.02, "pos=%v", pos)
+	}
+}
+
+func TestIMDraw_GradientShapes(t *testing.T) {
+	rg := &RadialGradient{
+		Center: pixel.V(15, 20),
+		Radius: pixel.V(20, 10),
+		Stops:  []GradientStop{{0, red}, {0.5, green}, {1, blue}},
+	}
+	tests := []struct {
+		name string
+		draw func(imd *IMDraw)
+		area float64
+	}{
+		{"ellipse", func(imd *IMDraw) {
+			imd.Push(pixel.V(20, 20))
+			imd.Ellipse(pixel.V(20, 10), 0)
+		}, 200 * math.Pi},
+		{"concave polygon", func(imd *IMDraw) {
+			imd.Push(
+				pixel.V(0, 0), pixel.V(30, 0), pixel.V(30, 20), pixel.V(20, 20),
+				pixel.V(20, 10), pixel.V(10, 10), pixel.V(10, 20), pixel.V(0, 20),
+			)
+			imd.Polygon(0)
+		}, 500},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			imd := New(nil)
+			test.draw(imd)
+			want := area(imd)
+			triangles := imd.tri.Len()
+
+			imd.Clear()
+			imd.Gradient = rg
+			test.draw(imd)
+			// the shape is split, but it covers the same area
+			assert.Greater(t, imd.tri.Len(), triangles)
+			assert.InDelta(t, want, area(imd), 1e-9)
+			assert.InDelta(t, test.area, area(imd), test.area*0.01)
+		})
+	}
+}
@@ -30,11 +30,35 @@ import (
 //	imd.Circle(400, 0)
 //
 // Here is the list of all available point properties (need to be set before Pushing a point):
-//   - Color     - applies to all
-//   - Picture   - coordinates, only applies to filled polygons
-//   - Intensity - picture intensity, only applies to filled polygons
-//   - Precision - curve drawing precision, only applies to circles and ellipses
-//   - EndShape  - shape of the end of a line, only applies to lines and outlines
+//   - Color      - applies to all
+//   - Picture    - coordinates, only applies to filled polygons
+//   - Intensity  - picture intensity, only applies to filled polygons
+//   - Precision  - curve drawing precision, only applies to circles, ellipses and curves
+//   - EndShape   - shape of the end of a line, only applies to lines and outlines
+//   - JoinShape  - shape of the joints of a line, only applies to lines and outlines
+//   - MiterLimit - longest miter joint relative to the thickness of a line, 4 by default
+//
+// FillRule is not a point property, it applies to the polygons filled after setting it. Neither
+// are Dash and DashOffset, which split the lines and outlines drawn after setting them into dashes.
+// Dash alternates the lengths of the dashes and of the gaps between them, DashOffset is the
+// distance into Dash at which each line starts:
+//
+//	imd.Dash = []float64{10, 5} // 10 units long dashes with gaps of 5 units
+//	imd.Rectangle(2)
+//
+// Gradient isn't a point property either. A LinearGradient or a RadialGradient set to it colors
+// the shapes drawn afterwards:
+//
+//	imd.Gradient = &imdraw.LinearGradient{
+//		Start: pixel.V(0, 0),
+//		End:   pixel.V(0, 100),
+//		Stops: []imdraw.GradientStop{
+//			{Offset: 0, Color: pixel.RGB(0, 0, 0.5)},
+//			{Offset: 1, Color: pixel.RGB(0.5, 0.8, 1)},
+//		},
+//	}
+//	imd.Push(pixel.V(0, 0), pixel.V(200, 100))
+//	imd.Rectangle(0)
 //
 // And here's the list of all shapes that can be drawn (all, except for line, can be filled or
 // outlined):
@@ -44,17 +68,35 @@ import (
 //   - Circle arc
 //   - Ellipse
 //   - Ellipse arc
+//
+// Points along curves can be Pushed with QuadTo, CubicTo and ArcTo, which continue from the last
+// Pushed point, to build paths drawn by Line or Polygon:
+//
+//	imd.Push(pixel.V(100, 100))
+//	imd.CubicTo(pixel.V(100, 200), pixel.V(300, 200), pixel.V(300, 100))
+//	imd.ArcTo(pixel.V(100, 50), 0, false, false, pixel.V(100, 100))
+//	imd.Polygon(0)
+//
+// ClosePath ends a contour of the path, the following points start a new one. Polygon fills all of
+// the contours together, so that contours inside of others make holes, as decided by FillRule.
 type IMDraw struct {
-	Color     color.Color
-	Picture   pixel.Vec
-	Intensity float64
-	Precision int
-	EndShape  EndShape
-
-	points []point
-	pool   [][]point
-	matrix pixel.Matrix
-	mask   pixel.RGBA
+	Color      color.Color
+	Picture    pixel.Vec
+	Intensity  float64
+	Precision  int
+	EndShape   EndShape
+	JoinShape  JoinShape
+	MiterLimit float64
+	FillRule   FillRule
+	Dash       []float64
+	DashOffset float64
+	Gradient   Gradient
+
+	points   []point
+	contours []int
+	pool     [][]point
+	matrix   pixel.Matrix
+	mask     pixel.RGBA
 
 	tri   *pixel.TrianglesData
 	batch *pixel.Batch
@@ -63,12 +105,14 @@ type IMDraw struct {
 var _ pixel.BasicTarget = (*IMDraw)(nil)
 
 type point struct {
-	pos       pixel.Vec
-	col       pixel.RGBA
-	pic       pixel.Vec
-	in        float64
-	precision int
-	endshape  EndShape
+	pos        pixel.Vec
+	col        pixel.RGBA
+	pic        pixel.Vec
+	in         float64
+	precision  int
+	endshape   EndShape
+	joinshape  JoinShape
+	miterlimit float64
 }
 
 // EndShape specifies the shape of an end of a line or a curve.
@@ -83,6 +127,9 @@ const (
 
 	// RoundEndShape is a circular end shape.
 	RoundEndShape
+
+	// SquareEndShape extends the end of a line by half of its thickness.
+	SquareEndShape
 )
 
 // New creates a new empty IMDraw. An optional Picture can be used to draw with a Picture.
@@ -111,11 +158,18 @@ func (imd *IMDraw) Clear() {
 // This does not affect matrix and color mask set by SetMatrix and SetColorMask.
 func (imd *IMDraw) Reset() {
 	imd.points = imd.points[:0]
+	imd.contours = imd.contours[:0]
 	imd.Color = pixel.Alpha(1)
 	imd.Picture = pixel.ZV
 	imd.Intensity = 0
 	imd.Precision = 64
 	imd.EndShape = NoEndShape
+	imd.JoinShape = DefaultJoinShape
+	imd.MiterLimit = 4
+	imd.FillRule = NonZero
+	imd.Dash = nil
+	imd.DashOffset = 0
+	imd.Gradient = nil
 }
 
 // Draw draws all currently drawn shapes inside the IM onto another Target.
@@ -134,11 +188,13 @@ func (imd *IMDraw) Push(pts ...pixel.Vec) {
 		imd.Color = pixel.ToRGBA(imd.Color)
 	}
 	opts := point{
-		col:       imd.Color.(pixel.RGBA),
-		pic:       imd.Picture,
-		in:        imd.Intensity,
-		precision: imd.Precision,
-		endshape:  imd.EndShape,
+		col:        imd.Color.(pixel.RGBA),
+		pic:        imd.Picture,
+		in:         imd.Intensity,
+		precision:  imd.Precision,
+		endshape:   imd.EndShape,
+		joinshape:  imd.JoinShape,
+		miterlimit: imd.MiterLimit,
 	}
 	for _, pt := range pts {
 		imd.pushPt(pt, opts)
@@ -172,9 +228,10 @@ func (imd *IMDraw) MakePicture(p pixel.Picture) pixel.TargetPicture {
 	return imd.batch.MakePicture(p)
 }
 
-// Line draws a polyline of the specified thickness between the Pushed points.
+// Line draws a polyline of the specified thickness between the Pushed points. Each contour ended by
+// ClosePath is drawn as a separate polyline.
 func (imd *IMDraw) Line(thickness float64) {
-	imd.polyline(thickness, false)
+	imd.strokeContours(thickness, false)
 }
 
 // Rectangle draws a rectangle between each two subsequent Pushed points. Drawing a rectangle
@@ -191,18 +248,17 @@ func (imd *IMDraw) Rectangle(thickness float64) {
 	}
 }
 
-// Polygon draws a polygon from the Pushed points. If the thickness is 0, the convex polygon will be
-// filled. Otherwise, an outline of the specified thickness will be drawn. The outline does not have
-// to be convex.
+// Polygon draws a polygon from the Pushed points. If the thickness is 0, the polygon will be
+// filled. Otherwise, an outline of the specified thickness will be drawn.
 //
-// Note, that the filled polygon does not have to be strictly convex. The way it's drawn is that a
-// triangle is drawn between each two adjacent points and the first Pushed point. You can use this
-// property to draw certain kinds of concave polygons.
+// The polygon may be concave and self-intersecting, and it may consist of several contours ended
+// by ClosePath, e.g. an outer one and holes. Which parts of it are filled is decided by FillRule.
+// Each contour gets a separate outline.
 func (imd *IMDraw) Polygon(thickness float64) {
 	if thickness == 0 {
 		imd.fillPolygon()
 	} else {
-		imd.polyline(thickness, true)
+		imd.strokeContours(thickness, true)
 	}
 }
 
@@ -269,6 +325,7 @@ func (imd *IMDraw) getAndClearPoints() []point {
 	} else {
 		imd.points = nil
 	}
+	imd.contours = imd.contours[:0]
 	return points
 }
 
@@ -278,6 +335,10 @@ func (imd *IMDraw) restorePoints(points []point) {
 }
 
 func (imd *IMDraw) applyMatrixAndMask(off int) {
+	// the gradient is in the coordinates of the points, so it goes first
+	if imd.Gradient != nil {
+		imd.paint(off)
+	}
 	for i := range (*imd.tri)[off:] {
 		(*imd.tri)[off+i].Position = imd.matrix.Project((*imd.tri)[off+i].Position)
 		(*imd.tri)[off+i].Color = imd.mask.Mul((*imd.tri)[off+i].Color)
@@ -349,13 +410,33 @@ func (imd *IMDraw) outlineRectangle(thickness float64) {
 }
 
 func (imd *IMDraw) fillPolygon() {
+	contours := imd.contours
+	imd.contours = nil
 	points := imd.getAndClearPoints()
 
-	if len(points) < 3 {
-		imd.restorePoints(points)
-		return
+	switch {
+	case len(points) < 3:
+	case len(contours) > 0 || !isConvex(points):
+		imd.fillContours(splitContours(points, contours), imd.FillRule)
+	default:
+		imd.fillFan(points)
 	}
 
+	imd.restorePoints(points)
+}
+
+// fillConvexPolygon fills the Pushed points as a convex polygon without checking it, for the
+// triangles and quads making up lines, their joints and ends.
+func (imd *IMDraw) fillConvexPolygon() {
+	points := imd.getAndClearPoints()
+	if len(points) >= 3 {
+		imd.fillFan(points)
+	}
+	imd.restorePoints(points)
+}
+
+// fillFan fills a convex polygon by a fan of triangles around its first point.
+func (imd *IMDraw) fillFan(points []point) {
 	off := imd.tri.Len()
 	imd.tri.SetLen(imd.tri.Len() + 3*(len(points)-2))
 
@@ -371,8 +452,6 @@ func (imd *IMDraw) fillPolygon() {
 
 	imd.applyMatrixAndMask(off)
 	imd.batch.Dirty()
-
-	imd.restorePoints(points)
 }
 
 func (imd *IMDraw) fillEllipseArc(radius pixel.Vec, low, high float64) {
@@ -419,6 +498,11 @@ func (imd *IMDraw) fillEllipseArc(radius pixel.Vec, low, high float64) {
 }
 
 func (imd *IMDraw) outlineEllipseArc(radius pixel.Vec, low, high, thickness float64, doEndShape bool) {
+	if validDash(imd.Dash) {
+		imd.dashedEllipseArc(radius, low, high, thickness, !doEndShape)
+		return
+	}
+
 	points := imd.getAndClearPoints()
 
 	for _, pt := range points {
@@ -500,12 +584,27 @@ func (imd *IMDraw) outlineEllipseArc(radius pixel.Vec, low, high, thickness floa
 				imd.pushPt(lowCenter.Add(thick), pt)
 				imd.pushPt(lowCenter.Sub(thick), pt)
 				imd.pushPt(lowCenter.Sub(thick.Normal().Scaled(orientation)), pt)
-				imd.fillPolygon()
+				imd.fillConvexPolygon()
 				thick = pixel.V(thickness/2, 0).Rotated(normalHigh)
 				imd.pushPt(highCenter.Add(thick), pt)
 				imd.pushPt(highCenter.Sub(thick), pt)
 				imd.pushPt(highCenter.Add(thick.Normal().Scaled(orientation)), pt)
-				imd.fillPolygon()
+				imd.fillConvexPolygon()
+			case SquareEndShape:
+				thick := pixel.V(thickness/2, 0).Rotated(normalLow)
+				tip := thick.Normal().Scaled(-orientation)
+				imd.pushPt(lowCenter.Add(thick), pt)
+				imd.pushPt(lowCenter.Sub(thick), pt)
+				imd.pushPt(lowCenter.Sub(thick).Add(tip), pt)
+				imd.pushPt(lowCenter.Add(thick).Add(tip), pt)
+				imd.fillConvexPolygon()
+				thick = pixel.V(thickness/2, 0).Rotated(normalHigh)
+				tip = thick.Normal().Scaled(orientation)
+				imd.pushPt(highCenter.Add(thick), pt)
+				imd.pushPt(highCenter.Sub(thick), pt)
+				imd.pushPt(highCenter.Sub(thick).Add(tip), pt)
+				imd.pushPt(highCenter.Add(thick).Add(tip), pt)
+				imd.fillConvexPolygon()
 			case RoundEndShape:
 				imd.pushPt(lowCenter, pt)
 				imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), normalLow, normalLow-math.Pi*orientation)
@@ -518,7 +617,28 @@ func (imd *IMDraw) outlineEllipseArc(radius pixel.Vec, low, high, thickness floa
 	imd.restorePoints(points)
 }
 
-func (imd *IMDraw) polyline(thickness float64, closed bool) {
+// strokeContours draws a polyline along each contour of the Pushed points.
+func (imd *IMDraw) strokeContours(thickness float64, closed bool) {
+	contours := imd.contours
+	imd.contours = nil
+	if len(contours) == 0 {
+		imd.polyline(thickness, closed)
+		return
+	}
+
+	points := imd.getAndClearPoints()
+	for _, c := range splitContours(points, contours) {
+		// the closing point of a contour is joined to the first one anyway
+		if closed && len(c) > 1 && c[len(c)-1].pos == c[0].pos {
+			c = c[:len(c)-1]
+		}
+		imd.points = append(imd.points, c...)
+		imd.polyline(thickness, closed)
+	}
+	imd.restorePoints(points)
+}
+
+func (imd *IMDraw) solidPolyline(thickness float64, closed bool) {
 	points := imd.getAndClearPoints()
 
 	if len(points) == 0 {
@@ -542,7 +662,13 @@ func (imd *IMDraw) polyline(thickness float64, closed bool) {
 			imd.pushPt(points[j].pos.Add(ijNormal), points[j])
 			imd.pushPt(points[j].pos.Sub(ijNormal), points[j])
 			imd.pushPt(points[j].pos.Add(ijNormal.Normal()), points[j])
-			imd.fillPolygon()
+			imd.fillConvexPolygon()
+		case SquareEndShape:
+			imd.pushPt(points[j].pos.Add(ijNormal), points[j])
+			imd.pushPt(points[j].pos.Sub(ijNormal), points[j])
+			imd.pushPt(points[j].pos.Sub(ijNormal).Add(ijNormal.Normal()), points[j])
+			imd.pushPt(points[j].pos.Add(ijNormal).Add(ijNormal.Normal()), points[j])
+			imd.fillConvexPolygon()
 		case RoundEndShape:
 			imd.pushPt(points[j].pos, points[j])
 			imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), ijNormal.Angle(), ijNormal.Angle()+math.Pi)
@@ -577,17 +703,17 @@ func (imd *IMDraw) polyline(thickness float64, closed bool) {
 
 		imd.pushPt(points[j].pos.Sub(ijNormal), points[j])
 		imd.pushPt(points[j].pos.Add(ijNormal), points[j])
-		imd.fillPolygon()
+		imd.fillConvexPolygon()
 
-		switch points[j].endshape {
-		case NoEndShape:
-			// nothing
-		case SharpEndShape:
+		switch {
+		case points[j].joinshape != DefaultJoinShape:
+			imd.join(points[j], ijNormal.Scaled(orientation), jkNormal.Scaled(orientation), thickness)
+		case points[j].endshape == SharpEndShape:
 			imd.pushPt(points[j].pos, points[j])
 			imd.pushPt(points[j].pos.Add(ijNormal.Scaled(orientation)), points[j])
 			imd.pushPt(points[j].pos.Add(jkNormal.Scaled(orientation)), points[j])
-			imd.fillPolygon()
-		case RoundEndShape:
+			imd.fillConvexPolygon()
+		case points[j].endshape == RoundEndShape:
 			imd.pushPt(points[j].pos, points[j])
 			imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), ijNormal.Angle(), ijNormal.Angle()-math.Pi)
 			imd.pushPt(points[j].pos, points[j])
@@ -608,7 +734,7 @@ func (imd *IMDraw) polyline(thickness float64, closed bool) {
 
 	imd.pushPt(points[j].pos.Sub(ijNormal), points[j])
 	imd.pushPt(points[j].pos.Add(ijNormal), points[j])
-	imd.fillPolygon()
+	imd.fillConvexPolygon()
 
 	if !closed {
 		switch points[j].endshape {
@@ -618,7 +744,13 @@ func (imd *IMDraw) polyline(thickness float64, closed bool) {
 			imd.pushPt(points[j].pos.Add(ijNormal), points[j])
 			imd.pushPt(points[j].pos.Sub(ijNormal), points[j])
 			imd.pushPt(points[j].pos.Add(ijNormal.Normal().Scaled(-1)), points[j])
-			imd.fillPolygon()
+			imd.fillConvexPolygon()
+		case SquareEndShape:
+			imd.pushPt(points[j].pos.Add(ijNormal), points[j])
+			imd.pushPt(points[j].pos.Sub(ijNormal), points[j])
+			imd.pushPt(points[j].pos.Sub(ijNormal).Sub(ijNormal.Normal()), points[j])
+			imd.pushPt(points[j].pos.Add(ijNormal).Sub(ijNormal.Normal()), points[j])
+			imd.fillConvexPolygon()
 		case RoundEndShape:
 			imd.pushPt(points[j].pos, points[j])
 			imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), ijNormal.Angle(), ijNormal.Angle()-math.Pi)
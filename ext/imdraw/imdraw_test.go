@@ -2,6 +2,7 @@ package imdraw_test
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 
@@ -69,6 +70,27 @@ func BenchmarkPolygon(b *testing.B) {
 	}
 }
 
+func BenchmarkPolygonConcave(b *testing.B) {
+	for _, n := range []int{10, 100, 1000, 10000} {
+		// a simple polygon with n spikes, like the outline of a gear
+		pts := make([]pixel.Vec, 2*n)
+		for i := range pts {
+			r := 1000.0
+			if i%2 == 1 {
+				r = 900
+			}
+			pts[i] = pixel.V(r, 0).Rotated(float64(i) * math.Pi / float64(n))
+		}
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			imd := imdraw.New(nil)
+			for i := 0; i < b.N; i++ {
+				imd.Push(pts...)
+				imd.Polygon(0)
+			}
+		})
+	}
+}
+
 func BenchmarkEllipseFill(b *testing.B) {
 	lists := pointLists(1, 10, 100, 1000)
 	for _, pts := range lists {
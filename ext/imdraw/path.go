@@ -0,0 +1,163 @@
+package imdraw
+
+import (
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// maxFlattenDepth limits the subdivision of curves.
+const maxFlattenDepth = 16
+
+// QuadTo Pushes points along a quadratic Bézier curve from the last Pushed point to the point to,
+// bent towards the control point. If no point has been Pushed, only to is Pushed.
+//
+// Like for circles, the number of points depends on Precision, which is the number of points used
+// for a full turn of the curve. Nearly straight curves are approximated by a few points only.
+//
+//	imd.Push(pixel.V(100, 100))
+//	imd.QuadTo(pixel.V(200, 300), pixel.V(300, 100))
+//	imd.Line(4)
+func (imd *IMDraw) QuadTo(control, to pixel.Vec) {
+	if len(imd.points) == 0 {
+		imd.Push(to)
+		return
+	}
+	from := imd.points[len(imd.points)-1].pos
+	// elevate the curve to a cubic one
+	control1 := from.Add(from.To(control).Scaled(2.0 / 3))
+	control2 := to.Add(to.To(control).Scaled(2.0 / 3))
+	imd.CubicTo(control1, control2, to)
+}
+
+// CubicTo Pushes points along a cubic Bézier curve from the last Pushed point to the point to, with
+// the control points control1 and control2. If no point has been Pushed, only to is Pushed.
+//
+// The number of points depends on Precision, see QuadTo.
+func (imd *IMDraw) CubicTo(control1, control2, to pixel.Vec) {
+	if len(imd.points) == 0 {
+		imd.Push(to)
+		return
+	}
+	from := imd.points[len(imd.points)-1].pos
+	maxTurn := 2 * math.Pi / float64(max(imd.Precision, 1))
+	imd.pushPath(flattenCubic(nil, from, control1, control2, to, maxTurn, 0))
+}
+
+// ArcTo Pushes points along an elliptical arc from the last Pushed point to the point to, like the
+// arc command of SVG paths. If no point has been Pushed, only to is Pushed.
+//
+// The ellipse has the given radius in each axis and is rotated by rotation radians. Of the four
+// arcs of such ellipses between the two points, largeArc selects one spanning more than 180
+// degrees, and sweep one going counterclockwise. Radii too small to reach the point are scaled up,
+// zero radii make the arc a straight line.
+//
+// The number of points depends on Precision, like for EllipseArc.
+func (imd *IMDraw) ArcTo(radius pixel.Vec, rotation float64, largeArc, sweep bool, to pixel.Vec) {
+	if len(imd.points) == 0 {
+		imd.Push(to)
+		return
+	}
+	from := imd.points[len(imd.points)-1].pos
+	if from == to {
+		return
+	}
+	rx, ry := math.Abs(radius.X), math.Abs(radius.Y)
+	if rx == 0 || ry == 0 {
+		imd.Push(to)
+		return
+	}
+
+	// conversion from the endpoint to the center parameterization, as described in the appendix
+	// of the SVG specification
+	half := to.To(from).Scaled(0.5).Rotated(-rotation)
+	if lambda := half.X*half.X/(rx*rx) + half.Y*half.Y/(ry*ry); lambda > 1 {
+		rx *= math.Sqrt(lambda)
+		ry *= math.Sqrt(lambda)
+	}
+	num := rx*rx*ry*ry - rx*rx*half.Y*half.Y - ry*ry*half.X*half.X
+	den := rx*rx*half.Y*half.Y + ry*ry*half.X*half.X
+	coef := math.Sqrt(math.Max(num/den, 0))
+	if largeArc == sweep {
+		coef = -coef
+	}
+	center := pixel.V(coef*rx*half.Y/ry, -coef*ry*half.X/rx)
+
+	start := pixel.V((half.X-center.X)/rx, (half.Y-center.Y)/ry).Angle()
+	end := pixel.V((-half.X-center.X)/rx, (-half.Y-center.Y)/ry).Angle()
+	delta := end - start
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+	center = center.Rotated(rotation).Add(from.Add(to).Scaled(0.5))
+
+	n := math.Ceil(math.Abs(delta) / (2 * math.Pi) * float64(max(imd.Precision, 1)))
+	pts := make([]pixel.Vec, 0, int(n))
+	for i := 1.0; i < n; i++ {
+		sin, cos := math.Sincos(start + i/n*delta)
+		pts = append(pts, center.Add(pixel.V(rx*cos, ry*sin).Rotated(rotation)))
+	}
+	imd.pushPath(append(pts, to))
+}
+
+// ClosePath ends the current contour of the path by Pushing its first point again, unless it's the
+// last one already. The points Pushed after it start a new contour, such as a hole of a polygon.
+func (imd *IMDraw) ClosePath() {
+	start := 0
+	if n := len(imd.contours); n > 0 {
+		start = imd.contours[n-1]
+	}
+	if len(imd.points) == start {
+		return
+	}
+	first := imd.points[start]
+	if imd.points[len(imd.points)-1].pos != first.pos {
+		imd.pushPt(first.pos, first)
+	}
+	imd.contours = append(imd.contours, len(imd.points))
+}
+
+// pushPath Pushes the points, skipping the ones equal to the previous point, which would make
+// segments of lines without a direction.
+func (imd *IMDraw) pushPath(pts []pixel.Vec) {
+	for _, pt := range pts {
+		if pt != imd.points[len(imd.points)-1].pos {
+			imd.Push(pt)
+		}
+	}
+}
+
+// flattenCubic appends points approximating the cubic Bézier curve p0, p1, p2, p3 to dst, without
+// p0. The curve is subdivided until its control polygon turns by at most maxTurn radians.
+func flattenCubic(dst []pixel.Vec, p0, p1, p2, p3 pixel.Vec, maxTurn float64, depth int) []pixel.Vec {
+	if depth >= maxFlattenDepth || turn(p0, p1, p2, p3) <= maxTurn {
+		return append(dst, p3)
+	}
+
+	// de Casteljau's subdivision at the middle of the curve
+	p01, p12, p23 := pixel.Lerp(p0, p1, 0.5), pixel.Lerp(p1, p2, 0.5), pixel.Lerp(p2, p3, 0.5)
+	p012, p123 := pixel.Lerp(p01, p12, 0.5), pixel.Lerp(p12, p23, 0.5)
+	mid := pixel.Lerp(p012, p123, 0.5)
+
+	dst = flattenCubic(dst, p0, p01, p012, mid, maxTurn, depth+1)
+	return flattenCubic(dst, mid, p123, p23, p3, maxTurn, depth+1)
+}
+
+// turn returns the total angle the polyline turns by, ignoring segments of zero length.
+func turn(pts ...pixel.Vec) float64 {
+	total := 0.0
+	var prev pixel.Vec
+	for i := 1; i < len(pts); i++ {
+		dir := pts[i-1].To(pts[i])
+		if dir == pixel.ZV {
+			continue
+		}
+		if prev != pixel.ZV {
+			total += math.Abs(math.Atan2(prev.Cross(dir), prev.Dot(dir)))
+		}
+		prev = dir
+	}
+	return total
+}
@@ -0,0 +1,138 @@
+package imdraw
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+func positions(imd *IMDraw) []pixel.Vec {
+	var pts []pixel.Vec
+	for _, p := range imd.points {
+		pts = append(pts, p.pos)
+	}
+	return pts
+}
+
+func TestIMDraw_QuadTo(t *testing.T) {
+	imd := New(nil)
+	imd.Push(pixel.V(0, 0))
+	imd.QuadTo(pixel.V(50, 100), pixel.V(100, 0))
+	pts := positions(imd)
+
+	assert.Equal(t, pixel.V(100, 0), pts[len(pts)-1])
+	assert.Greater(t, len(pts), 8)
+	assert.Less(t, len(pts), 64)
+	// the points lie on the parabola y = 2x - x²/50
+	for _, p := range pts {
+		assert.InDelta(t, 2*p.X-p.X*p.X/50, p.Y, 1e-9)
+	}
+
+	// more precision, more points
+	imd.Clear()
+	imd.Reset()
+	imd.Precision = 256
+	imd.Push(pixel.V(0, 0))
+	imd.QuadTo(pixel.V(50, 100), pixel.V(100, 0))
+	assert.Greater(t, len(imd.points), len(pts))
+}
+
+func TestIMDraw_CubicTo(t *testing.T) {
+	imd := New(nil)
+
+	// there's no point to start from
+	imd.CubicTo(pixel.V(0, 10), pixel.V(10, 10), pixel.V(10, 0))
+	assert.Equal(t, []pixel.Vec{pixel.V(10, 0)}, positions(imd))
+
+	// straight curves need no points in between
+	imd.CubicTo(pixel.V(20, 0), pixel.V(30, 0), pixel.V(40, 0))
+	assert.Equal(t, []pixel.Vec{pixel.V(10, 0), pixel.V(40, 0)}, positions(imd))
+
+	// neither do curves of zero length
+	imd.CubicTo(pixel.V(40, 0), pixel.V(40, 0), pixel.V(40, 0))
+	assert.Len(t, imd.points, 2)
+
+	// symmetric S-curve
+	imd.CubicTo(pixel.V(60, 20), pixel.V(60, -20), pixel.V(80, 0))
+	pts := positions(imd)[1:]
+	assert.Equal(t, pixel.V(80, 0), pts[len(pts)-1])
+	for i := range pts {
+		mirrored := pixel.V(120-pts[i].X, -pts[i].Y)
+		assert.InDelta(t, mirrored.X, pts[len(pts)-1-i].X, 1e-9)
+		assert.InDelta(t, mirrored.Y, pts[len(pts)-1-i].Y, 1e-9)
+	}
+}
+
+func TestIMDraw_ArcTo(t *testing.T) {
+	tests := []struct {
+		name            string
+		radius          pixel.Vec
+		largeArc, sweep bool
+		center          pixel.Vec
+		below           bool
+	}{
+		{"counterclockwise", pixel.V(1, 1), false, true, pixel.V(1, 0), true},
+		{"clockwise", pixel.V(1, 1), false, false, pixel.V(1, 0), false},
+		{"radius scaled up", pixel.V(0.5, 0.5), false, true, pixel.V(1, 0), true},
+		{"small arc", pixel.V(2, 2), false, true, pixel.V(1, math.Sqrt(3)), true},
+		{"large arc", pixel.V(2, 2), true, true, pixel.V(1, -math.Sqrt(3)), true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			imd := New(nil)
+			imd.Push(pixel.V(0, 0))
+			imd.ArcTo(test.radius, 0, test.largeArc, test.sweep, pixel.V(2, 0))
+			pts := positions(imd)
+
+			assert.Equal(t, pixel.V(2, 0), pts[len(pts)-1])
+			for _, p := range pts[1 : len(pts)-1] {
+				assert.InDelta(t, math.Max(test.radius.X, 1), p.To(test.center).Len(), 1e-9)
+				assert.Equal(t, test.below, p.Y < 0)
+			}
+		})
+	}
+
+	// a half circle takes half of the points of a full one
+	imd := New(nil)
+	imd.Push(pixel.V(0, 0))
+	imd.ArcTo(pixel.V(1, 1), 0, false, true, pixel.V(2, 0))
+	assert.Len(t, imd.points, 1+imd.Precision/2)
+
+	// the ellipse is rotated, its radius of 2 is vertical
+	imd = New(nil)
+	imd.Push(pixel.V(0, 0))
+	imd.ArcTo(pixel.V(2, 1), math.Pi/2, false, true, pixel.V(0, 4))
+	pts := positions(imd)
+	assert.InDelta(t, 1, pts[len(pts)/2].X, 1e-9)
+	for _, p := range pts {
+		assert.GreaterOrEqual(t, p.X, -1e-9)
+		assert.LessOrEqual(t, p.X, 1+1e-9)
+	}
+
+	// zero radii make a line
+	imd = New(nil)
+	imd.Push(pixel.V(0, 0))
+	imd.ArcTo(pixel.V(0, 1), 0, false, true, pixel.V(2, 0))
+	assert.Equal(t, []pixel.Vec{pixel.V(0, 0), pixel.V(2, 0)}, positions(imd))
+}
+
+func TestIMDraw_ClosePath(t *testing.T) {
+	imd := New(nil)
+	imd.Color = pixel.RGB(1, 0, 0)
+	imd.Push(pixel.V(0, 0))
+	imd.Color = pixel.RGB(0, 1, 0)
+	imd.QuadTo(pixel.V(10, 10), pixel.V(20, 0))
+	imd.ClosePath()
+	imd.ClosePath()
+
+	last := imd.points[len(imd.points)-1]
+	assert.Equal(t, imd.points[0], last)
+	assert.NotEqual(t, imd.points[len(imd.points)-2].pos, last.pos)
+
+	imd.Line(2)
+	assert.Empty(t, imd.points)
+	assert.NotZero(t, imd.tri.Len())
+}
@@ -0,0 +1,192 @@
+package imdraw
+
+import (
+	"math"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// JoinShape specifies the shape of a joint between two segments of a line.
+type JoinShape int
+
+const (
+	// DefaultJoinShape joins the segments by the EndShape of the point between them: no shape, a
+	// bevel for SharpEndShape or a circle for RoundEndShape.
+	DefaultJoinShape JoinShape = iota
+
+	// MiterJoinShape extends the outer edges of the segments until they meet, unless the miter
+	// would get longer than MiterLimit times the thickness of the line, in which case the joint is
+	// beveled.
+	MiterJoinShape
+
+	// BevelJoinShape connects the outer corners of the segments by a straight edge.
+	BevelJoinShape
+
+	// RoundJoinShape rounds the outer side of the joint.
+	RoundJoinShape
+)
+
+// polyline draws a polyline between the Pushed points, split into dashes if Dash is set.
+func (imd *IMDraw) polyline(thickness float64, closed bool) {
+	if len(imd.points) < 2 || !validDash(imd.Dash) {
+		imd.solidPolyline(thickness, closed)
+		return
+	}
+
+	points := imd.getAndClearPoints()
+	for _, dash := range dashes(points, closed, imd.Dash, imd.DashOffset) {
+		imd.points = append(imd.points, dash...)
+		imd.solidPolyline(thickness, false)
+	}
+	imd.restorePoints(points)
+}
+
+// dashedEllipseArc draws the outline of the ellipse arc around each Pushed point split into dashes.
+func (imd *IMDraw) dashedEllipseArc(radius pixel.Vec, low, high, thickness float64, closed bool) {
+	points := imd.getAndClearPoints()
+
+	for _, pt := range points {
+		num := math.Ceil(math.Abs(high-low) / (2 * math.Pi) * float64(pt.precision))
+		// outlines of ellipses aren't pictured, and the segments of the arc join smoothly
+		pt.pic, pt.in = pixel.ZV, 0
+		pt.joinshape = MiterJoinShape
+
+		n := num
+		if closed {
+			n--
+		}
+		for i := 0.0; i <= n; i++ {
+			sin, cos := math.Sincos(low + i/num*(high-low))
+			imd.pushPt(pt.pos.Add(pixel.V(radius.X*cos, radius.Y*sin)), pt)
+		}
+		imd.polyline(thickness, closed)
+	}
+
+	imd.restorePoints(points)
+}
+
+// join draws the outer side of the joint of two segments at the point, given the normals of the
+// segments pointing outwards of the turn, each half of the thickness long.
+func (imd *IMDraw) join(pt point, ijNormal, jkNormal pixel.Vec, thickness float64) {
+	switch pt.joinshape {
+	case MiterJoinShape:
+		// the miter is 1/cos(θ/2) times longer than the thickness, θ being the angle between the
+		// normals
+		miter := ijNormal.Add(jkNormal)
+		if thickness <= pt.miterlimit*miter.Len() {
+			imd.pushPt(pt.pos, pt)
+			imd.pushPt(pt.pos.Add(ijNormal), pt)
+			imd.pushPt(pt.pos.Add(miter.Scaled(thickness*thickness/2/miter.Dot(miter))), pt)
+			imd.pushPt(pt.pos.Add(jkNormal), pt)
+			imd.fillConvexPolygon()
+			return
+		}
+		fallthrough
+	case BevelJoinShape:
+		imd.pushPt(pt.pos, pt)
+		imd.pushPt(pt.pos.Add(ijNormal), pt)
+		imd.pushPt(pt.pos.Add(jkNormal), pt)
+		imd.fillConvexPolygon()
+	case RoundJoinShape:
+		low := ijNormal.Angle()
+		angle := math.Atan2(ijNormal.Cross(jkNormal), ijNormal.Dot(jkNormal))
+		imd.pushPt(pt.pos, pt)
+		imd.fillEllipseArc(pixel.V(thickness/2, thickness/2), low, low+angle)
+	}
+}
+
+// validDash reports whether the dash pattern splits lines, which requires non-negative lengths of
+// a positive sum.
+func validDash(pattern []float64) bool {
+	total := 0.0
+	for _, length := range pattern {
+		if length < 0 {
+			return false
+		}
+		total += length
+	}
+	return total > 0
+}
+
+// dashes splits the polyline into dashes by the pattern of alternating lengths of dashes and gaps,
+// starting at offset in the pattern. A pattern of an odd number of lengths is repeated twice, as in
+// SVG.
+func dashes(points []point, closed bool, pattern []float64, offset float64) [][]point {
+	if len(pattern)%2 == 1 {
+		pattern = append(pattern[:len(pattern):len(pattern)], pattern...)
+	}
+	total := 0.0
+	for _, length := range pattern {
+		total += length
+	}
+
+	// find the part of the pattern the polyline starts in
+	offset = math.Mod(offset, total)
+	if offset < 0 {
+		offset += total
+	}
+	i := 0
+	for offset >= pattern[i] {
+		offset -= pattern[i]
+		i = (i + 1) % len(pattern)
+	}
+	remaining := pattern[i] - offset
+
+	if closed {
+		points = append(points[:len(points):len(points)], points[0])
+	}
+	var (
+		split   [][]point
+		dash    []point
+		started = i%2 == 0
+	)
+	if started {
+		dash = append(dash, points[0])
+	}
+	for j := 0; j+1 < len(points); j++ {
+		a, b := points[j], points[j+1]
+		length := a.pos.To(b.pos).Len()
+		t := 0.0
+		for length-t > remaining {
+			t += remaining
+			p := lerpPoint(a, b, t/length)
+			if i%2 == 0 {
+				if len(dash) == 0 || dash[len(dash)-1].pos != p.pos {
+					dash = append(dash, p)
+				}
+				split = append(split, dash)
+				dash = nil
+			} else {
+				dash = []point{p}
+			}
+			i = (i + 1) % len(pattern)
+			remaining = pattern[i]
+		}
+		remaining -= length - t
+		if i%2 == 0 && (len(dash) == 0 || dash[len(dash)-1].pos != b.pos) {
+			dash = append(dash, b)
+		}
+	}
+	if len(dash) > 1 {
+		split = append(split, dash)
+	}
+
+	// a dash going through the first point of a closed polyline isn't split there
+	if closed && started && len(dash) > 1 && len(split) > 1 {
+		last := split[len(split)-1]
+		split[0] = append(last, split[0][1:]...)
+		split = split[:len(split)-1]
+	}
+	return split
+}
+
+// lerpPoint returns the point at t between the points a and b, interpolating their properties.
+// The other properties are taken from a.
+func lerpPoint(a, b point, t float64) point {
+	p := a
+	p.pos = pixel.Lerp(a.pos, b.pos, t)
+	p.col = a.col.Add(b.col.Sub(a.col).Scaled(t))
+	p.pic = pixel.Lerp(a.pic, b.pic, t)
+	p.in = a.in + t*(b.in-a.in)
+	return p
+}
@@ -0,0 +1,205 @@
+package imdraw
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+)
+
+func dashPositions(dashes [][]point) [][]pixel.Vec {
+	var pts [][]pixel.Vec
+	for _, dash := range dashes {
+		var d []pixel.Vec
+		for _, p := range dash {
+			d = append(d, p.pos)
+		}
+		pts = append(pts, d)
+	}
+	return pts
+}
+
+func TestDashes(t *testing.T) {
+	line := []point{{pos: pixel.V(0, 0)}, {pos: pixel.V(20, 0)}, {pos: pixel.V(20, 30)}}
+	// along the closing segment
+	closing := func(d float64) pixel.Vec {
+		return pixel.V(20, 30).Add(pixel.V(-20, -30).Unit().Scaled(d))
+	}
+	tests := []struct {
+		name    string
+		pattern []float64
+		offset  float64
+		closed  bool
+		want    [][]pixel.Vec
+	}{
+		{
+			name:    "around a corner",
+			pattern: []float64{15, 10},
+			want: [][]pixel.Vec{
+				{pixel.V(0, 0), pixel.V(15, 0)},
+				{pixel.V(20, 5), pixel.V(20, 20)},
+			},
+		},
+		{
+			name:    "offset into a gap",
+			pattern: []float64{15, 10},
+			offset:  20,
+			want: [][]pixel.Vec{
+				{pixel.V(5, 0), pixel.V(20, 0)},
+				{pixel.V(20, 10), pixel.V(20, 25)},
+			},
+		},
+		{
+			name:    "negative offset",
+			pattern: []float64{15, 10},
+			offset:  -5,
+			want: [][]pixel.Vec{
+				{pixel.V(5, 0), pixel.V(20, 0)},
+				{pixel.V(20, 10), pixel.V(20, 25)},
+			},
+		},
+		{
+			name:    "odd pattern",
+			pattern: []float64{10},
+			want: [][]pixel.Vec{
+				{pixel.V(0, 0), pixel.V(10, 0)},
+				{pixel.V(20, 0), pixel.V(20, 10)},
+				{pixel.V(20, 20), pixel.V(20, 30)},
+			},
+		},
+		{
+			name:    "closed",
+			pattern: []float64{20, 15},
+			closed:  true,
+			want: [][]pixel.Vec{
+				// the dash through the first point isn't split
+				{closing(20), pixel.V(0, 0), pixel.V(20, 0)},
+				{pixel.V(20, 15), pixel.V(20, 30), closing(5)},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := dashPositions(dashes(line, test.closed, test.pattern, test.offset))
+			assert.Equal(t, len(test.want), len(got))
+			for i := range got {
+				assert.Equal(t, len(test.want[i]), len(got[i]))
+				for j := range got[i] {
+					assert.InDelta(t, test.want[i][j].X, got[i][j].X, 1e-9)
+					assert.InDelta(t, test.want[i][j].Y, got[i][j].Y, 1e-9)
+				}
+			}
+		})
+	}
+}
+
+func TestIMDraw_JoinShape(t *testing.T) {
+	tests := []struct {
+		name       string
+		join       JoinShape
+		miterLimit float64
+		corner     bool
+		rounded    bool
+		beveled    bool
+	}{
+		// with NoEndShape, the segments aren't joined at all
+		{"default", DefaultJoinShape, 4, false, false, false},
+		{"miter", MiterJoinShape, 4, true, true, true},
+		{"miter limit", MiterJoinShape, 1.4, false, false, true},
+		{"bevel", BevelJoinShape, 4, false, false, true},
+		{"round", RoundJoinShape, 4, false, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			imd := New(nil)
+			imd.JoinShape = test.join
+			imd.MiterLimit = test.miterLimit
+			imd.Push(pixel.V(5, 5), pixel.V(15, 5), pixel.V(15, 15))
+			imd.Line(6)
+
+			c := software.NewCanvas(pixel.R(0, 0, 20, 20))
+			imd.Draw(c)
+			assert.Equal(t, test.corner, c.Color(pixel.V(17.5, 2.5)) == pixel.Alpha(1), "corner")
+			assert.Equal(t, test.rounded, c.Color(pixel.V(17.5, 3.5)) == pixel.Alpha(1), "rounded")
+			assert.Equal(t, test.beveled, c.Color(pixel.V(15.5, 3.5)) == pixel.Alpha(1), "beveled")
+			assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(13.5, 6.5)), "inner")
+		})
+	}
+}
+
+func TestIMDraw_Dash(t *testing.T) {
+	imd := New(nil)
+	imd.Dash = []float64{4, 2}
+	imd.DashOffset = 1
+	imd.Push(pixel.V(0, 5), pixel.V(20, 5))
+	imd.Line(2)
+
+	c := software.NewCanvas(pixel.R(0, 0, 20, 10))
+	imd.Draw(c)
+	for x := 0; x < 20; x++ {
+		dash := (x+1)%6 < 4
+		assert.Equal(t, dash, c.Color(pixel.V(float64(x)+0.5, 5.5)) == pixel.Alpha(1), "x=%d", x)
+	}
+
+	// patterns without dashes draw solid lines
+	for _, pattern := range [][]float64{{0, 0}, {4, -2}} {
+		imd.Clear()
+		imd.Dash = pattern
+		imd.Push(pixel.V(0, 5), pixel.V(20, 5))
+		imd.Line(2)
+		assert.Equal(t, 6, imd.tri.Len())
+	}
+
+	// Reset clears the pattern
+	imd.Reset()
+	assert.Nil(t, imd.Dash)
+}
+
+func TestIMDraw_DashedOutlines(t *testing.T) {
+	imd := New(nil)
+	imd.Dash = []float64{10, 10}
+	imd.Push(pixel.V(5, 5), pixel.V(35, 35))
+	imd.Rectangle(2)
+	// quarters of the circumference
+	imd.Dash = []float64{5 * math.Pi, 5 * math.Pi}
+	imd.Push(pixel.V(20, 20))
+	imd.Circle(10, 2)
+
+	c := software.NewCanvas(pixel.R(0, 0, 40, 40))
+	imd.Draw(c)
+
+	// the outline of the rectangle is dashed from its first corner
+	assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(5.5, 9.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(5.5, 19.5)))
+	assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(5.5, 29.5)))
+
+	// the outline of the circle is dashed from the angle 0 counterclockwise
+	assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(27.5, 27.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(12.5, 27.5)))
+	assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(12.5, 12.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(27.5, 12.5)))
+}
+
+func TestIMDraw_SquareEndShape(t *testing.T) {
+	imd := New(nil)
+	imd.EndShape = SquareEndShape
+	imd.Push(pixel.V(5, 10), pixel.V(15, 10))
+	imd.Line(4)
+	imd.Push(pixel.V(30, 10))
+	imd.CircleArc(5, 0, math.Pi, 2)
+
+	c := software.NewCanvas(pixel.R(0, 0, 40, 20))
+	imd.Draw(c)
+	// the ends are extended by 2 units
+	assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(3.5, 11.5)))
+	assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(16.5, 8.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(2.5, 10.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(17.5, 10.5)))
+	// the ends of the arc are extended downwards by 1 unit
+	assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(35.5, 9.5)))
+	assert.Equal(t, pixel.Alpha(1), c.Color(pixel.V(24.5, 9.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(35.5, 8.5)))
+}
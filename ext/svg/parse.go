@@ -0,0 +1,490 @@
+package svg
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/imdraw"
+	"github.com/pkg/errors"
+	"golang.org/x/image/colornames"
+)
+
+// style holds the properties inherited by the elements of a group.
+type style struct {
+	matrix  pixel.Matrix
+	color   pixel.RGBA
+	opacity float64
+
+	fill        *pixel.RGBA // nil is currentColor
+	fillOpacity float64
+	fillRule    imdraw.FillRule
+
+	stroke        *pixel.RGBA // nil is currentColor
+	strokeOpacity float64
+	strokeWidth   float64
+	endShape      imdraw.EndShape
+	joinShape     imdraw.JoinShape
+	miterLimit    float64
+	dash          []float64
+	dashOffset    float64
+}
+
+var (
+	black       = pixel.RGB(0, 0, 0)
+	transparent = pixel.RGBA{}
+)
+
+// defaultStyle is the style of the root element, as specified by SVG.
+var defaultStyle = style{
+	matrix:        pixel.IM,
+	color:         black,
+	opacity:       1,
+	fill:          &black,
+	fillOpacity:   1,
+	stroke:        &transparent,
+	strokeOpacity: 1,
+	strokeWidth:   1,
+	endShape:      imdraw.NoEndShape,
+	joinShape:     imdraw.MiterJoinShape,
+	miterLimit:    4,
+}
+
+type parser struct {
+	img    *Image
+	styles []style
+}
+
+func (p *parser) parse(r io.Reader) error {
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			if p.img == nil {
+				return errors.New("no svg element")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if p.img == nil {
+				if tok.Name.Local != "svg" {
+					return errors.Errorf("root element is %s, not svg", tok.Name.Local)
+				}
+				if err := p.root(tok); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := p.element(dec, tok); err != nil {
+				return errors.Wrap(err, tok.Name.Local)
+			}
+		case xml.EndElement:
+			p.styles = p.styles[:len(p.styles)-1]
+		}
+	}
+}
+
+// root sets up the Image and the transform from the view box of the svg element to it.
+func (p *parser) root(el xml.StartElement) error {
+	attrs := attributes(el)
+	var viewBox []float64
+	if v, ok := attrs["viewBox"]; ok {
+		var err error
+		if viewBox, err = numbers(v); err != nil || len(viewBox) != 4 {
+			return errors.Errorf("invalid viewBox: %q", v)
+		}
+	}
+
+	size := pixel.ZV
+	for i, name := range []string{"width", "height"} {
+		v, ok := attrs[name]
+		if !ok || strings.HasSuffix(v, "%") {
+			// the size of the view box by default
+			if viewBox == nil {
+				return errors.Errorf("%s or viewBox needed", name)
+			}
+			v = strconv.FormatFloat(viewBox[2+i], 'g', -1, 64)
+		}
+		l, err := length(v)
+		if err != nil {
+			return errors.Wrap(err, name)
+		}
+		if i == 0 {
+			size.X = l
+		} else {
+			size.Y = l
+		}
+	}
+	p.img = &Image{Bounds: pixel.R(0, 0, size.X, size.Y)}
+
+	// the view box is scaled uniformly to fit into the image, centered, as by the default
+	// preserveAspectRatio, and the y axis is flipped to point up
+	matrix := pixel.IM
+	if viewBox != nil && viewBox[2] > 0 && viewBox[3] > 0 {
+		scale := math.Min(size.X/viewBox[2], size.Y/viewBox[3])
+		matrix = pixel.IM.
+			Moved(pixel.V(-viewBox[0]-viewBox[2]/2, -viewBox[1]-viewBox[3]/2)).
+			Scaled(pixel.ZV, scale).
+			Moved(size.Scaled(0.5))
+	}
+	matrix = matrix.Chained(pixel.Matrix{1, 0, 0, -1, 0, size.Y})
+
+	s := defaultStyle
+	s.matrix = matrix
+	p.styles = append(p.styles, s.apply(attrs))
+	return nil
+}
+
+// element parses an element inside of the svg element, and skips it if it isn't supported.
+func (p *parser) element(dec *xml.Decoder, el xml.StartElement) error {
+	attrs := attributes(el)
+	s := p.styles[len(p.styles)-1].apply(attrs)
+
+	var path []segment
+	var err error
+	switch el.Name.Local {
+	case "g", "svg":
+		p.styles = append(p.styles, s)
+		return nil
+	case "path":
+		path, err = parsePath(attrs["d"])
+	case "rect":
+		path, err = rect(attrs)
+	case "circle":
+		path, err = ellipse(attrs, "r", "r")
+	case "ellipse":
+		path, err = ellipse(attrs, "rx", "ry")
+	case "line":
+		path, err = line(attrs)
+	case "polyline", "polygon":
+		path, err = poly(attrs["points"], el.Name.Local == "polygon")
+	default:
+		return dec.Skip()
+	}
+	if err != nil {
+		return err
+	}
+
+	// the element ends with the end of the group of its style
+	p.styles = append(p.styles, s)
+	if len(path) > 0 {
+		p.img.Shapes = append(p.img.Shapes, s.shape(path))
+	}
+	return nil
+}
+
+// shape returns the shape of the path in the style.
+func (s style) shape(path []segment) *Shape {
+	paint := func(c *pixel.RGBA, opacity float64) pixel.RGBA {
+		if c == nil {
+			c = &s.color
+		}
+		return c.Scaled(opacity * s.opacity)
+	}
+	return &Shape{
+		Matrix:      s.matrix,
+		Fill:        paint(s.fill, s.fillOpacity),
+		FillRule:    s.fillRule,
+		Stroke:      paint(s.stroke, s.strokeOpacity),
+		StrokeWidth: s.strokeWidth,
+		EndShape:    s.endShape,
+		JoinShape:   s.joinShape,
+		MiterLimit:  s.miterLimit,
+		Dash:        s.dash,
+		DashOffset:  s.dashOffset,
+		path:        path,
+	}
+}
+
+// attributes returns the attributes of the element, overridden by the properties of its style
+// attribute.
+func attributes(el xml.StartElement) map[string]string {
+	attrs := make(map[string]string)
+	for _, a := range el.Attr {
+		attrs[a.Name.Local] = strings.TrimSpace(a.Value)
+	}
+	if style, ok := attrs["style"]; ok {
+		for _, decl := range strings.Split(style, ";") {
+			name, value, ok := strings.Cut(decl, ":")
+			if ok {
+				attrs[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return attrs
+}
+
+// apply returns the style with the properties of the attributes applied. Properties with invalid or
+// unsupported values, such as lengths in relative units, are ignored, as if they weren't set.
+func (s style) apply(attrs map[string]string) style {
+	for name, v := range attrs {
+		if v == "inherit" {
+			continue
+		}
+		prev := s
+		var err error
+		switch name {
+		case "transform":
+			var m pixel.Matrix
+			m, err = transform(v)
+			s.matrix = m.Chained(s.matrix)
+		case "color":
+			var c *pixel.RGBA
+			if c, err = paint(v); err == nil && c != nil {
+				s.color = *c
+			}
+		case "opacity":
+			// the opacity of groups is applied to each of their shapes separately
+			var o float64
+			o, err = opacity(v)
+			s.opacity *= o
+		case "fill":
+			s.fill, err = paint(v)
+		case "fill-opacity":
+			s.fillOpacity, err = opacity(v)
+		case "fill-rule":
+			switch v {
+			case "nonzero":
+				s.fillRule = imdraw.NonZero
+			case "evenodd":
+				s.fillRule = imdraw.EvenOdd
+			default:
+				err = errors.Errorf("unknown fill-rule: %q", v)
+			}
+		case "stroke":
+			s.stroke, err = paint(v)
+		case "stroke-opacity":
+			s.strokeOpacity, err = opacity(v)
+		case "stroke-width":
+			s.strokeWidth, err = length(v)
+		case "stroke-linecap":
+			switch v {
+			case "butt":
+				s.endShape = imdraw.NoEndShape
+			case "round":
+				s.endShape = imdraw.RoundEndShape
+			case "square":
+				s.endShape = imdraw.SquareEndShape
+			default:
+				err = errors.Errorf("unknown stroke-linecap: %q", v)
+			}
+		case "stroke-linejoin":
+			switch v {
+			case "miter", "miter-clip", "arcs":
+				s.joinShape = imdraw.MiterJoinShape
+			case "round":
+				s.joinShape = imdraw.RoundJoinShape
+			case "bevel":
+				s.joinShape = imdraw.BevelJoinShape
+			default:
+				err = errors.Errorf("unknown stroke-linejoin: %q", v)
+			}
+		case "stroke-miterlimit":
+			s.miterLimit, err = strconv.ParseFloat(v, 64)
+		case "stroke-dasharray":
+			s.dash = nil
+			if v != "none" {
+				s.dash, err = lengths(v)
+			}
+		case "stroke-dashoffset":
+			s.dashOffset, err = length(v)
+		}
+		if err != nil {
+			s = prev
+		}
+	}
+	return s
+}
+
+// paint parses a color, which is nil for currentColor. Paint servers referenced by url(), such as
+// gradients, aren't supported, so their fallback color is used, or none if there's no fallback.
+func paint(v string) (*pixel.RGBA, error) {
+	var c pixel.RGBA
+	switch {
+	case v == "none" || v == "transparent":
+	case v == "currentColor":
+		return nil, nil
+	case strings.HasPrefix(v, "url("):
+		end := strings.IndexByte(v, ')')
+		if end < 0 {
+			return nil, errors.Errorf("invalid paint: %q", v)
+		}
+		if fallback := strings.TrimSpace(v[end+1:]); fallback != "" {
+			return paint(fallback)
+		}
+	case strings.HasPrefix(v, "#"):
+		hex := v[1:]
+		if len(hex) == 3 || len(hex) == 4 {
+			long := make([]byte, 0, 8)
+			for i := 0; i < len(hex); i++ {
+				long = append(long, hex[i], hex[i])
+			}
+			hex = string(long)
+		}
+		if len(hex) == 6 {
+			hex += "ff"
+		}
+		rgba, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 8 {
+			return nil, errors.Errorf("invalid color: %q", v)
+		}
+		c = pixel.RGB(
+			float64(rgba>>24)/255,
+			float64(rgba>>16&0xff)/255,
+			float64(rgba>>8&0xff)/255,
+		).Scaled(float64(rgba&0xff) / 255)
+	case (strings.HasPrefix(v, "rgb(") || strings.HasPrefix(v, "rgba(")) && strings.HasSuffix(v, ")"):
+		_, args, _ := strings.Cut(v[:len(v)-1], "(")
+		parts := strings.Split(args, ",")
+		if len(parts) != 3 && len(parts) != 4 {
+			return nil, errors.Errorf("invalid color: %q", v)
+		}
+		rgba := [4]float64{3: 1}
+		for i, part := range parts {
+			part = strings.TrimSpace(part)
+			scale := 255.0
+			if i == 3 {
+				// alpha is a number between 0 and 1
+				scale = 1
+			}
+			if strings.HasSuffix(part, "%") {
+				part, scale = part[:len(part)-1], 100
+			}
+			x, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return nil, errors.Errorf("invalid color: %q", v)
+			}
+			rgba[i] = pixel.Clamp(x/scale, 0, 1)
+		}
+		c = pixel.RGB(rgba[0], rgba[1], rgba[2]).Scaled(rgba[3])
+	default:
+		named, ok := colornames.Map[strings.ToLower(v)]
+		if !ok {
+			return nil, errors.Errorf("unknown color: %q", v)
+		}
+		c = pixel.ToRGBA(named)
+	}
+	return &c, nil
+}
+
+// opacity parses an opacity, clamped between 0 and 1.
+func opacity(v string) (float64, error) {
+	scale := 1.0
+	if strings.HasSuffix(v, "%") {
+		v, scale = v[:len(v)-1], 100
+	}
+	o, err := strconv.ParseFloat(v, 64)
+	return pixel.Clamp(o/scale, 0, 1), err
+}
+
+// units are the sizes of the absolute units of lengths in pixels.
+var units = map[string]float64{
+	"":   1,
+	"px": 1,
+	"pt": 4.0 / 3,
+	"pc": 16,
+	"mm": 96 / 25.4,
+	"cm": 96 / 2.54,
+	"in": 96,
+}
+
+// length parses a length in pixels. Relative units aren't supported.
+func length(v string) (float64, error) {
+	num := strings.TrimRight(v, "abcdefghijklmnopqrstuvwxyz%")
+	unit, ok := units[v[len(num):]]
+	if !ok {
+		return 0, errors.Errorf("unsupported unit: %q", v)
+	}
+	l, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid length: %q", v)
+	}
+	return l * unit, nil
+}
+
+// lengths parses a list of lengths separated by commas or whitespace.
+func lengths(v string) ([]float64, error) {
+	var ls []float64
+	for _, f := range strings.FieldsFunc(v, isSeparator) {
+		l, err := length(f)
+		if err != nil {
+			return nil, err
+		}
+		ls = append(ls, l)
+	}
+	return ls, nil
+}
+
+// numbers parses a list of numbers, as in path data.
+func numbers(v string) ([]float64, error) {
+	sc := scanner{s: v}
+	var nums []float64
+	for sc.skipSpace(); !sc.done(); sc.skipSpace() {
+		x, err := sc.number()
+		if err != nil {
+			return nil, err
+		}
+		nums = append(nums, x)
+	}
+	return nums, nil
+}
+
+func isSeparator(r rune) bool {
+	return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
+
+// transform parses a list of transform functions, applied from the right to the left.
+func transform(v string) (pixel.Matrix, error) {
+	m := pixel.IM
+	const space = " \t\r\n,"
+	for rest := strings.TrimLeft(v, space); rest != ""; rest = strings.TrimLeft(rest, space) {
+		name, args, ok := strings.Cut(rest, "(")
+		if !ok {
+			return m, errors.Errorf("invalid transform: %q", v)
+		}
+		args, rest, ok = strings.Cut(args, ")")
+		if !ok {
+			return m, errors.Errorf("invalid transform: %q", v)
+		}
+		nums, err := numbers(args)
+		if err != nil {
+			return m, errors.Wrapf(err, "invalid transform: %q", v)
+		}
+
+		var f pixel.Matrix
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "matrix" && len(nums) == 6:
+			f = pixel.Matrix{nums[0], nums[1], nums[2], nums[3], nums[4], nums[5]}
+		case name == "translate" && len(nums) == 1:
+			f = pixel.IM.Moved(pixel.V(nums[0], 0))
+		case name == "translate" && len(nums) == 2:
+			f = pixel.IM.Moved(pixel.V(nums[0], nums[1]))
+		case name == "scale" && len(nums) == 1:
+			f = pixel.IM.Scaled(pixel.ZV, nums[0])
+		case name == "scale" && len(nums) == 2:
+			f = pixel.IM.ScaledXY(pixel.ZV, pixel.V(nums[0], nums[1]))
+		case name == "rotate" && len(nums) == 1:
+			f = pixel.IM.Rotated(pixel.ZV, nums[0]*math.Pi/180)
+		case name == "rotate" && len(nums) == 3:
+			f = pixel.IM.Rotated(pixel.V(nums[1], nums[2]), nums[0]*math.Pi/180)
+		case name == "skewX" && len(nums) == 1:
+			f = pixel.Matrix{1, 0, math.Tan(nums[0] * math.Pi / 180), 1, 0, 0}
+		case name == "skewY" && len(nums) == 1:
+			f = pixel.Matrix{1, math.Tan(nums[0] * math.Pi / 180), 0, 1, 0, 0}
+		default:
+			return m, errors.Errorf("invalid transform: %q", v)
+		}
+		// the functions to the right apply first
+		m = f.Chained(m)
+	}
+	return m, nil
+}
@@ -0,0 +1,246 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/pkg/errors"
+)
+
+// segment is a segment of a path in absolute coordinates. Each subpath starts with moveTo.
+type segment struct {
+	op  op
+	to  pixel.Vec
+	c1  pixel.Vec // control point of quadTo and cubicTo
+	c2  pixel.Vec // second control point of cubicTo
+	arc arc
+}
+
+type op int
+
+const (
+	moveTo op = iota
+	lineTo
+	quadTo
+	cubicTo
+	arcTo
+	closePath
+)
+
+// arc holds the parameters of an elliptical arc of arcTo, as in imdraw.IMDraw.ArcTo.
+type arc struct {
+	radius          pixel.Vec
+	rotation        float64
+	largeArc, sweep bool
+}
+
+// parsePath parses the data of a path element.
+func parsePath(d string) ([]segment, error) {
+	var (
+		sc      = scanner{s: d}
+		path    []segment
+		cur     pixel.Vec
+		start   pixel.Vec
+		prevCmd byte
+	)
+	// reflection of the last control point, for the smooth curve commands
+	reflected := func(cmds string) pixel.Vec {
+		if prevCmd == 0 || !containsByte(cmds, prevCmd|0x20) {
+			return cur
+		}
+		last := path[len(path)-1]
+		c := last.c2
+		if last.op == quadTo {
+			c = last.c1
+		}
+		return cur.Add(c.To(cur))
+	}
+
+	for sc.skipSpace(); !sc.done(); sc.skipSpace() {
+		cmd := sc.s[sc.i]
+		if isCommand(cmd) {
+			sc.i++
+		} else if prevCmd == 0 {
+			return nil, errors.Errorf("path must start with a command: %q", d)
+		} else {
+			// the previous command is repeated, move to is followed by line to
+			cmd = prevCmd
+			switch cmd {
+			case 'M':
+				cmd = 'L'
+			case 'm':
+				cmd = 'l'
+			case 'Z', 'z':
+				return nil, errors.Errorf("unexpected number after close path: %q", d)
+			}
+		}
+		if prevCmd == 0 && cmd != 'M' && cmd != 'm' {
+			return nil, errors.Errorf("path must start with move to: %q", d)
+		}
+
+		// relative coordinates are relative to the current point
+		rel := pixel.ZV
+		if cmd >= 'a' {
+			rel = cur
+		}
+		var (
+			args [7]float64
+			err  error
+		)
+		for i := 0; i < argCount(cmd); i++ {
+			if (cmd|0x20) == 'a' && (i == 3 || i == 4) {
+				var flag bool
+				flag, err = sc.flag()
+				if flag {
+					args[i] = 1
+				}
+			} else {
+				args[i], err = sc.number()
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "command %c", cmd)
+			}
+		}
+		point := func(i int) pixel.Vec {
+			return rel.Add(pixel.V(args[i], args[i+1]))
+		}
+
+		var seg segment
+		switch cmd | 0x20 {
+		case 'm':
+			seg = segment{op: moveTo, to: point(0)}
+			start = seg.to
+		case 'l':
+			seg = segment{op: lineTo, to: point(0)}
+		case 'h':
+			seg = segment{op: lineTo, to: pixel.V(rel.X+args[0], cur.Y)}
+		case 'v':
+			seg = segment{op: lineTo, to: pixel.V(cur.X, rel.Y+args[0])}
+		case 'c':
+			seg = segment{op: cubicTo, c1: point(0), c2: point(2), to: point(4)}
+		case 's':
+			seg = segment{op: cubicTo, c1: reflected("cs"), c2: point(0), to: point(2)}
+		case 'q':
+			seg = segment{op: quadTo, c1: point(0), to: point(2)}
+		case 't':
+			seg = segment{op: quadTo, c1: reflected("qt"), to: point(0)}
+		case 'a':
+			seg = segment{op: arcTo, to: point(5), arc: arc{
+				radius:   pixel.V(math.Abs(args[0]), math.Abs(args[1])),
+				rotation: args[2] * math.Pi / 180,
+				largeArc: args[3] != 0,
+				sweep:    args[4] != 0,
+			}}
+		case 'z':
+			seg = segment{op: closePath, to: start}
+		}
+		// a subpath after close path starts at the start of the closed one
+		if len(path) > 0 && path[len(path)-1].op == closePath && seg.op != moveTo {
+			path = append(path, segment{op: moveTo, to: start})
+		}
+		path = append(path, seg)
+		cur = seg.to
+		prevCmd = cmd
+	}
+	return path, nil
+}
+
+func isCommand(c byte) bool {
+	return containsByte("MmLlHhVvCcSsQqTtAaZz", c)
+}
+
+func containsByte(s string, c byte) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			return true
+		}
+	}
+	return false
+}
+
+// argCount returns the number of arguments of the path command.
+func argCount(cmd byte) int {
+	switch cmd | 0x20 {
+	case 'm', 'l', 't':
+		return 2
+	case 'h', 'v':
+		return 1
+	case 'c':
+		return 6
+	case 's', 'q':
+		return 4
+	case 'a':
+		return 7
+	}
+	return 0
+}
+
+// scanner scans the numbers of path data and attributes, separated by whitespace and commas.
+type scanner struct {
+	s string
+	i int
+}
+
+func (sc *scanner) done() bool {
+	return sc.i >= len(sc.s)
+}
+
+func (sc *scanner) skipSpace() {
+	for !sc.done() && containsByte(" \t\r\n,", sc.s[sc.i]) {
+		sc.i++
+	}
+}
+
+// number scans a number, which may be directly followed by another one, as in "1.5.5" or "1-2".
+func (sc *scanner) number() (float64, error) {
+	sc.skipSpace()
+	begin := sc.i
+	if !sc.done() && (sc.s[sc.i] == '+' || sc.s[sc.i] == '-') {
+		sc.i++
+	}
+	digits := sc.digits()
+	if !sc.done() && sc.s[sc.i] == '.' {
+		sc.i++
+		digits += sc.digits()
+	}
+	if digits == 0 {
+		sc.i = begin
+		return 0, sc.errorf("expected a number")
+	}
+	if !sc.done() && (sc.s[sc.i] == 'e' || sc.s[sc.i] == 'E') {
+		exp := sc.i
+		sc.i++
+		if !sc.done() && (sc.s[sc.i] == '+' || sc.s[sc.i] == '-') {
+			sc.i++
+		}
+		if sc.digits() == 0 {
+			// not an exponent after all
+			sc.i = exp
+		}
+	}
+	return strconv.ParseFloat(sc.s[begin:sc.i], 64)
+}
+
+func (sc *scanner) digits() int {
+	n := 0
+	for ; !sc.done() && sc.s[sc.i] >= '0' && sc.s[sc.i] <= '9'; sc.i++ {
+		n++
+	}
+	return n
+}
+
+// flag scans an arc flag, which may be directly followed by another number.
+func (sc *scanner) flag() (bool, error) {
+	sc.skipSpace()
+	if sc.done() || (sc.s[sc.i] != '0' && sc.s[sc.i] != '1') {
+		return false, sc.errorf("expected a flag")
+	}
+	sc.i++
+	return sc.s[sc.i-1] == '1', nil
+}
+
+func (sc *scanner) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("%s at %d of %q", fmt.Sprintf(format, args...), sc.i, sc.s)
+}
@@ -0,0 +1,136 @@
+package svg
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+func TestParsePath(t *testing.T) {
+	v := pixel.V
+	tests := []struct {
+		name string
+		d    string
+		want []segment
+	}{
+		{
+			name: "absolute lines",
+			d:    "M 1 2 L 3 4 H 5 V 6 Z",
+			want: []segment{
+				{op: moveTo, to: v(1, 2)},
+				{op: lineTo, to: v(3, 4)},
+				{op: lineTo, to: v(5, 4)},
+				{op: lineTo, to: v(5, 6)},
+				{op: closePath, to: v(1, 2)},
+			},
+		},
+		{
+			name: "relative lines with implicit commands",
+			d:    "m1,2 2,2h2v2z",
+			want: []segment{
+				{op: moveTo, to: v(1, 2)},
+				{op: lineTo, to: v(3, 4)},
+				{op: lineTo, to: v(5, 4)},
+				{op: lineTo, to: v(5, 6)},
+				{op: closePath, to: v(1, 2)},
+			},
+		},
+		{
+			name: "compact numbers",
+			d:    "M1.5.5L-1-2e1l1e-1.1",
+			want: []segment{
+				{op: moveTo, to: v(1.5, 0.5)},
+				{op: lineTo, to: v(-1, -20)},
+				{op: lineTo, to: v(-0.9, -19.9)},
+			},
+		},
+		{
+			name: "subpath after close path",
+			d:    "M 1 1 L 2 1 Z l 0 1",
+			want: []segment{
+				{op: moveTo, to: v(1, 1)},
+				{op: lineTo, to: v(2, 1)},
+				{op: closePath, to: v(1, 1)},
+				{op: moveTo, to: v(1, 1)},
+				{op: lineTo, to: v(1, 2)},
+			},
+		},
+		{
+			name: "smooth cubic curves",
+			d:    "M 0 0 C 0 1 2 1 2 0 S 4 -1 4 0 s 2 1 2 0",
+			want: []segment{
+				{op: moveTo, to: v(0, 0)},
+				{op: cubicTo, c1: v(0, 1), c2: v(2, 1), to: v(2, 0)},
+				{op: cubicTo, c1: v(2, -1), c2: v(4, -1), to: v(4, 0)},
+				{op: cubicTo, c1: v(4, 1), c2: v(6, 1), to: v(6, 0)},
+			},
+		},
+		{
+			name: "smooth quadratic curves",
+			d:    "M 0 0 T 2 0 Q 3 1 4 0 t 2 0",
+			want: []segment{
+				{op: moveTo, to: v(0, 0)},
+				// without a previous curve, the control point is the current point
+				{op: quadTo, c1: v(0, 0), to: v(2, 0)},
+				{op: quadTo, c1: v(3, 1), to: v(4, 0)},
+				{op: quadTo, c1: v(5, -1), to: v(6, 0)},
+			},
+		},
+		{
+			name: "arcs with compact flags",
+			d:    "M 0 0 A 5 -4 90 1 0 10 0 a5 5 0 0110 0",
+			want: []segment{
+				{op: moveTo, to: v(0, 0)},
+				{op: arcTo, to: v(10, 0), arc: arc{
+					radius:   v(5, 4),
+					rotation: math.Pi / 2,
+					largeArc: true,
+				}},
+				{op: arcTo, to: v(20, 0), arc: arc{radius: v(5, 5), sweep: true}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parsePath(test.d)
+			assert.NoError(t, err)
+			assert.Equal(t, len(test.want), len(got))
+			for i := range got {
+				assert.Equal(t, test.want[i].op, got[i].op, "segment %d", i)
+				for _, p := range [][2]pixel.Vec{
+					{test.want[i].to, got[i].to},
+					{test.want[i].c1, got[i].c1},
+					{test.want[i].c2, got[i].c2},
+				} {
+					assert.InDelta(t, p[0].X, p[1].X, 1e-9, "segment %d", i)
+					assert.InDelta(t, p[0].Y, p[1].Y, 1e-9, "segment %d", i)
+				}
+				assert.InDelta(t, test.want[i].arc.rotation, got[i].arc.rotation, 1e-9)
+				assert.Equal(t, test.want[i].arc.radius, got[i].arc.radius)
+				assert.Equal(t, test.want[i].arc.largeArc, got[i].arc.largeArc)
+				assert.Equal(t, test.want[i].arc.sweep, got[i].arc.sweep)
+			}
+		})
+	}
+}
+
+func TestParsePath_Errors(t *testing.T) {
+	for _, d := range []string{
+		"L 1 1",
+		"1 1",
+		"M 1",
+		"M 1 1 L x",
+		"M 0 0 A 1 1 0 2 0 1 1",
+		"M 0 0 Z 1",
+	} {
+		_, err := parsePath(d)
+		assert.Error(t, err, d)
+	}
+
+	path, err := parsePath("")
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+}
@@ -0,0 +1,122 @@
+package svg
+
+import (
+	"github.com/gopxl/pixel/v2"
+	"github.com/pkg/errors"
+)
+
+// attrLengths parses the lengths of the attributes, which are 0 if they're missing.
+func attrLengths(attrs map[string]string, names ...string) ([]float64, error) {
+	ls := make([]float64, len(names))
+	for i, name := range names {
+		v, ok := attrs[name]
+		if !ok {
+			continue
+		}
+		var err error
+		if ls[i], err = length(v); err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+	}
+	return ls, nil
+}
+
+// rect returns the path of a rect element, with its corners rounded by rx and ry.
+func rect(attrs map[string]string) ([]segment, error) {
+	ls, err := attrLengths(attrs, "x", "y", "width", "height", "rx", "ry")
+	if err != nil {
+		return nil, err
+	}
+	x, y, w, h, rx, ry := ls[0], ls[1], ls[2], ls[3], ls[4], ls[5]
+	if w <= 0 || h <= 0 {
+		return nil, nil
+	}
+
+	// one of the radii is the other one if it's missing
+	_, okX := attrs["rx"]
+	_, okY := attrs["ry"]
+	if okX && !okY {
+		ry = rx
+	} else if okY && !okX {
+		rx = ry
+	}
+	rx = pixel.Clamp(rx, 0, w/2)
+	ry = pixel.Clamp(ry, 0, h/2)
+
+	if rx == 0 || ry == 0 {
+		return []segment{
+			{op: moveTo, to: pixel.V(x, y)},
+			{op: lineTo, to: pixel.V(x+w, y)},
+			{op: lineTo, to: pixel.V(x+w, y+h)},
+			{op: lineTo, to: pixel.V(x, y+h)},
+			{op: closePath, to: pixel.V(x, y)},
+		}, nil
+	}
+	corner := arc{radius: pixel.V(rx, ry), sweep: true}
+	return []segment{
+		{op: moveTo, to: pixel.V(x+rx, y)},
+		{op: lineTo, to: pixel.V(x+w-rx, y)},
+		{op: arcTo, to: pixel.V(x+w, y+ry), arc: corner},
+		{op: lineTo, to: pixel.V(x+w, y+h-ry)},
+		{op: arcTo, to: pixel.V(x+w-rx, y+h), arc: corner},
+		{op: lineTo, to: pixel.V(x+rx, y+h)},
+		{op: arcTo, to: pixel.V(x, y+h-ry), arc: corner},
+		{op: lineTo, to: pixel.V(x, y+ry)},
+		{op: arcTo, to: pixel.V(x+rx, y), arc: corner},
+		{op: closePath, to: pixel.V(x+rx, y)},
+	}, nil
+}
+
+// ellipse returns the path of a circle or an ellipse element, with the radii in the attributes.
+func ellipse(attrs map[string]string, rxName, ryName string) ([]segment, error) {
+	ls, err := attrLengths(attrs, "cx", "cy", rxName, ryName)
+	if err != nil {
+		return nil, err
+	}
+	center, radius := pixel.V(ls[0], ls[1]), pixel.V(ls[2], ls[3])
+	if radius.X <= 0 || radius.Y <= 0 {
+		return nil, nil
+	}
+	// two halves, as an arc can't end where it starts
+	half := arc{radius: radius, sweep: true}
+	return []segment{
+		{op: moveTo, to: center.Add(pixel.V(radius.X, 0))},
+		{op: arcTo, to: center.Sub(pixel.V(radius.X, 0)), arc: half},
+		{op: arcTo, to: center.Add(pixel.V(radius.X, 0)), arc: half},
+		{op: closePath, to: center.Add(pixel.V(radius.X, 0))},
+	}, nil
+}
+
+// line returns the path of a line element.
+func line(attrs map[string]string) ([]segment, error) {
+	ls, err := attrLengths(attrs, "x1", "y1", "x2", "y2")
+	if err != nil {
+		return nil, err
+	}
+	return []segment{
+		{op: moveTo, to: pixel.V(ls[0], ls[1])},
+		{op: lineTo, to: pixel.V(ls[2], ls[3])},
+	}, nil
+}
+
+// poly returns the path of the points of a polyline or a polygon element.
+func poly(points string, closed bool) ([]segment, error) {
+	nums, err := numbers(points)
+	if err != nil {
+		return nil, errors.Wrap(err, "points")
+	}
+	// an odd number of coordinates is an error, but the points before it are still drawn
+	nums = nums[:len(nums)-len(nums)%2]
+	if len(nums) < 4 {
+		return nil, nil
+	}
+	var path []segment
+	for i := 0; i < len(nums); i += 2 {
+		path = append(path, segment{op: lineTo, to: pixel.V(nums[i], nums[i+1])})
+	}
+	path[0].op = moveTo
+	if closed {
+		path = append(path, segment{op: closePath, to: path[0].to})
+	}
+	return path, nil
+}
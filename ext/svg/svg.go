@@ -0,0 +1,194 @@
+// Package svg imports vector images from SVG documents, to be drawn by an imdraw.IMDraw or as
+// pixel.TrianglesData, and scaled by a pixel.Matrix without rasterizing them.
+//
+// A practical subset of SVG is supported: the path, rect, circle, ellipse, line, polyline and
+// polygon elements, grouped by g elements, with solid fills and strokes and transforms. Other
+// elements, such as text, gradients and the contents of defs, are skipped, and paint referencing a
+// gradient is replaced by its fallback color. Properties with invalid or unsupported values, such
+// as lengths in em, are ignored.
+package svg
+
+import (
+	"io"
+	"os"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/imdraw"
+	"github.com/pkg/errors"
+)
+
+// Image is a vector image parsed from an SVG document.
+type Image struct {
+	// Bounds is the area of the image given by the width and the height of the document, with
+	// the origin at the bottom-left corner.
+	Bounds pixel.Rect
+	// Shapes are the shapes of the image, in the order they're drawn.
+	Shapes []*Shape
+}
+
+// Shape is a path of an SVG document, filled and stroked with solid colors.
+type Shape struct {
+	// Matrix transforms the path into the coordinates of the Image.
+	Matrix pixel.Matrix
+
+	// Fill is the color of the inside of the path, transparent if it isn't filled.
+	Fill     pixel.RGBA
+	FillRule imdraw.FillRule
+
+	// Stroke is the color of the outline of the path, transparent if it isn't stroked.
+	Stroke      pixel.RGBA
+	StrokeWidth float64
+	EndShape    imdraw.EndShape
+	JoinShape   imdraw.JoinShape
+	MiterLimit  float64
+	Dash        []float64
+	DashOffset  float64
+
+	path []segment
+}
+
+// Parse parses an SVG document.
+func Parse(r io.Reader) (*Image, error) {
+	p := parser{}
+	if err := p.parse(r); err != nil {
+		return nil, errors.Wrap(err, "failed to parse svg")
+	}
+	return p.img, nil
+}
+
+// ParseFile parses the SVG document in the file.
+func ParseFile(path string) (*Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := Parse(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%v", path)
+	}
+	return img, nil
+}
+
+// Draw draws the shapes of the image into the IMDraw, transformed by the matrix.
+//
+// The matrix of the IMDraw is left set to the given matrix. Its other properties are restored.
+func (img *Image) Draw(imd *imdraw.IMDraw, matrix pixel.Matrix) {
+	for _, s := range img.Shapes {
+		s.Draw(imd, matrix)
+	}
+}
+
+// Triangles returns the triangles of the shapes of the image, transformed by the matrix.
+func (img *Image) Triangles(matrix pixel.Matrix) *pixel.TrianglesData {
+	imd := imdraw.New(nil)
+	img.Draw(imd, matrix)
+	var t trianglesTarget
+	imd.Draw(&t)
+	return &t.tri
+}
+
+// Draw draws the shape into the IMDraw, transformed by the matrix.
+//
+// The matrix of the IMDraw is left set to the given matrix. Its other properties are restored.
+func (s *Shape) Draw(imd *imdraw.IMDraw, matrix pixel.Matrix) {
+	col, fillRule, gradient := imd.Color, imd.FillRule, imd.Gradient
+	endShape, joinShape, miterLimit := imd.EndShape, imd.JoinShape, imd.MiterLimit
+	dash, dashOffset := imd.Dash, imd.DashOffset
+	defer func() {
+		imd.Color, imd.FillRule, imd.Gradient = col, fillRule, gradient
+		imd.EndShape, imd.JoinShape, imd.MiterLimit = endShape, joinShape, miterLimit
+		imd.Dash, imd.DashOffset = dash, dashOffset
+		imd.SetMatrix(matrix)
+	}()
+
+	imd.SetMatrix(s.Matrix.Chained(matrix))
+	imd.Gradient = nil
+	imd.Dash = nil
+	imd.EndShape = imdraw.NoEndShape
+	imd.JoinShape = imdraw.DefaultJoinShape
+
+	if s.Fill.A > 0 {
+		imd.Color = s.Fill
+		imd.FillRule = s.FillRule
+		for _, sub := range s.subpaths() {
+			s.push(imd, sub)
+			imd.ClosePath()
+		}
+		imd.Polygon(0)
+	}
+
+	if s.Stroke.A > 0 && s.StrokeWidth > 0 {
+		imd.Color = s.Stroke
+		imd.EndShape = s.EndShape
+		imd.JoinShape = s.JoinShape
+		imd.MiterLimit = s.MiterLimit
+		imd.Dash = s.Dash
+		imd.DashOffset = s.DashOffset
+		for _, sub := range s.subpaths() {
+			s.push(imd, sub)
+			if sub[len(sub)-1].op == closePath {
+				imd.ClosePath()
+				imd.Polygon(s.StrokeWidth)
+			} else {
+				imd.Line(s.StrokeWidth)
+			}
+		}
+	}
+}
+
+// subpaths splits the path at each move to. Subpaths without any segments are left out.
+func (s *Shape) subpaths() [][]segment {
+	var subs [][]segment
+	for i := 0; i < len(s.path); {
+		j := i + 1
+		for j < len(s.path) && s.path[j].op != moveTo {
+			j++
+		}
+		if j-i > 1 {
+			subs = append(subs, s.path[i:j])
+		}
+		i = j
+	}
+	return subs
+}
+
+// push Pushes the points of the subpath, except for closing it.
+func (s *Shape) push(imd *imdraw.IMDraw, sub []segment) {
+	for _, seg := range sub {
+		switch seg.op {
+		case moveTo, lineTo:
+			imd.Push(seg.to)
+		case quadTo:
+			imd.QuadTo(seg.c1, seg.to)
+		case cubicTo:
+			imd.CubicTo(seg.c1, seg.c2, seg.to)
+		case arcTo:
+			imd.ArcTo(seg.arc.radius, seg.arc.rotation, seg.arc.largeArc, seg.arc.sweep, seg.to)
+		}
+	}
+}
+
+// trianglesTarget is a Target collecting the triangles drawn onto it.
+type trianglesTarget struct {
+	tri pixel.TrianglesData
+}
+
+func (t *trianglesTarget) MakeTriangles(tri pixel.Triangles) pixel.TargetTriangles {
+	td := pixel.MakeTrianglesData(tri.Len())
+	td.Update(tri)
+	return &targetTriangles{TrianglesData: td, dst: t}
+}
+
+func (t *trianglesTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	panic("svg: pictures are not supported")
+}
+
+type targetTriangles struct {
+	*pixel.TrianglesData
+	dst *trianglesTarget
+}
+
+func (tt *targetTriangles) Draw() {
+	tt.dst.tri = append(tt.dst.tri, *tt.TrianglesData...)
+}
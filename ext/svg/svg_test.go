@@ -0,0 +1,271 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/gopxl/pixel/v2/ext/imdraw"
+	"github.com/gopxl/pixel/v2/pixeltest"
+)
+
+func draw(t *testing.T, doc string) (*Image, *software.Canvas) {
+	t.Helper()
+	img, err := Parse(strings.NewReader(doc))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	c := software.NewCanvas(img.Bounds)
+	imd := imdraw.New(nil)
+	img.Draw(imd, pixel.IM)
+	imd.Draw(c)
+	return img, c
+}
+
+func TestParse_Document(t *testing.T) {
+	img, c := draw(t, `<?xml version="1.0"?>
+		<svg xmlns="http://www.w3.org/2000/svg" width="40" height="20">
+			<title>a rectangle</title>
+			<defs><rect width="40" height="20"/></defs>
+			<rect x="5" y="2" width="10" height="5" fill="red"/>
+		</svg>`)
+
+	assert.Equal(t, pixel.R(0, 0, 40, 20), img.Bounds)
+	assert.Len(t, img.Shapes, 1)
+	// the y axis points down in SVG
+	assert.Equal(t, pixel.RGB(1, 0, 0), c.Color(pixel.V(10, 15)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(10, 5)))
+}
+
+func TestParse_ViewBox(t *testing.T) {
+	_, c := draw(t, `<svg width="40" height="20" viewBox="10 10 10 10">
+			<rect x="10" y="10" width="5" height="5"/>
+		</svg>`)
+
+	// the view box is scaled by 2 and centered horizontally
+	assert.Equal(t, black, c.Color(pixel.V(15, 15)))
+	assert.Equal(t, black, c.Color(pixel.V(19, 11)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(9, 15)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(21, 15)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(15, 9)))
+}
+
+func TestParse_Styles(t *testing.T) {
+	img, err := Parse(strings.NewReader(`<svg width="10" height="10">
+		<g fill="#00f" stroke="rgb(0, 100%, 0)" stroke-width="2" opacity="0.5"
+			transform="translate(1 2) scale(2)">
+			<path d="M0 0h1v1z" style="fill-opacity: 0.5; stroke-linecap: square"/>
+			<g stroke-linejoin="round" stroke-dasharray="1, 2" color="red">
+				<circle r="1" fill="currentColor" stroke="none" fill-rule="evenodd"/>
+			</g>
+		</g>
+		<line x2="1" y2="1" stroke="blue"/>
+	</svg>`))
+	assert.NoError(t, err)
+	assert.Len(t, img.Shapes, 3)
+
+	s := img.Shapes[0]
+	assert.Equal(t, pixel.RGB(0, 0, 1).Scaled(0.25), s.Fill)
+	assert.Equal(t, pixel.RGB(0, 1, 0).Scaled(0.5), s.Stroke)
+	assert.Equal(t, 2.0, s.StrokeWidth)
+	assert.Equal(t, imdraw.SquareEndShape, s.EndShape)
+	assert.Equal(t, imdraw.MiterJoinShape, s.JoinShape)
+	assert.Equal(t, 4.0, s.MiterLimit)
+	assert.Equal(t, pixel.V(3, 6), s.Matrix.Project(pixel.V(1, 1)))
+
+	s = img.Shapes[1]
+	assert.Equal(t, pixel.RGB(1, 0, 0).Scaled(0.5), s.Fill)
+	assert.Equal(t, pixel.RGBA{}, s.Stroke)
+	assert.Equal(t, imdraw.EvenOdd, s.FillRule)
+	assert.Equal(t, imdraw.RoundJoinShape, s.JoinShape)
+	assert.Equal(t, []float64{1, 2}, s.Dash)
+
+	// the properties of the group don't leak out of it
+	s = img.Shapes[2]
+	assert.Equal(t, pixel.RGB(0, 0, 0), s.Fill)
+	assert.Equal(t, pixel.RGB(0, 0, 1), s.Stroke)
+	assert.Equal(t, 1.0, s.StrokeWidth)
+	assert.Equal(t, imdraw.NoEndShape, s.EndShape)
+	assert.Nil(t, s.Dash)
+	assert.Equal(t, pixel.V(1, 9), s.Matrix.Project(pixel.V(1, 1)))
+}
+
+func TestParse_RootStyles(t *testing.T) {
+	img, err := Parse(strings.NewReader(`<svg width="10" height="10" fill="none" stroke="red"
+		stroke-width="2"><path d="M2 2 L8 8"/><path d="M0 0h1v1z" fill="blue"/></svg>`))
+	assert.NoError(t, err)
+	assert.Len(t, img.Shapes, 2)
+
+	s := img.Shapes[0]
+	assert.Equal(t, pixel.RGBA{}, s.Fill)
+	assert.Equal(t, pixel.RGB(1, 0, 0), s.Stroke)
+	assert.Equal(t, 2.0, s.StrokeWidth)
+	assert.Equal(t, pixel.V(2, 8), s.Matrix.Project(pixel.V(2, 2)))
+
+	s = img.Shapes[1]
+	assert.Equal(t, pixel.RGB(0, 0, 1), s.Fill)
+	assert.Equal(t, pixel.RGB(1, 0, 0), s.Stroke)
+}
+
+func TestParse_Transform(t *testing.T) {
+	tests := []struct {
+		transform string
+		want      pixel.Vec
+	}{
+		{"translate(2)", pixel.V(3, 1)},
+		{"translate(2, 3) scale(2 3)", pixel.V(4, 6)},
+		{"rotate(90)", pixel.V(-1, 1)},
+		{"rotate(90 1 0)", pixel.V(0, 0)},
+		{"matrix(1 0 1 1 0 0)", pixel.V(2, 1)},
+		{"skewX(45)", pixel.V(2, 1)},
+		{"skewY(45)", pixel.V(1, 2)},
+		{"scale(2) translate(1, 1)", pixel.V(4, 4)},
+	}
+	for _, test := range tests {
+		m, err := transform(test.transform)
+		assert.NoError(t, err, test.transform)
+		got := m.Project(pixel.V(1, 1))
+		assert.InDelta(t, test.want.X, got.X, 1e-9, test.transform)
+		assert.InDelta(t, test.want.Y, got.Y, 1e-9, test.transform)
+	}
+
+	for _, v := range []string{"translate(1", "rotate(1 2)", "shear(1)", "scale(a)"} {
+		_, err := transform(v)
+		assert.Error(t, err, v)
+	}
+}
+
+func TestParse_Colors(t *testing.T) {
+	tests := map[string]pixel.RGBA{
+		"#f00":             pixel.RGB(1, 0, 0),
+		"#0000ff":          pixel.RGB(0, 0, 1),
+		"rgb(0, 255, 0)":   pixel.RGB(0, 1, 0),
+		"rgb(50%, 0%, 0%)": pixel.RGB(0.5, 0, 0),
+		"White":            pixel.RGB(1, 1, 1),
+		"none":             {},
+		// alpha is premultiplied
+		"#f008":                  pixel.RGB(1, 0, 0).Scaled(0x88 / 255.0),
+		"#0000ff80":              pixel.RGB(0, 0, 1).Scaled(0x80 / 255.0),
+		"rgba(0, 255, 0, 0.5)":   pixel.RGB(0, 1, 0).Scaled(0.5),
+		"rgba(0, 0, 255, 25%)":   pixel.RGB(0, 0, 1).Scaled(0.25),
+		"rgb(255, 0, 0, 0.5)":    pixel.RGB(1, 0, 0).Scaled(0.5),
+		"url(#gradient) #00f":    pixel.RGB(0, 0, 1),
+		"url(#gradient)":         {},
+		"url('#gradient') green": pixel.RGB(0, 0x80/255.0, 0),
+	}
+	for v, want := range tests {
+		c, err := paint(v)
+		assert.NoError(t, err, v)
+		pixeltest.AssertColor(t, want, *c, 1e-9, "%v", v)
+	}
+
+	c, err := paint("currentColor")
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+
+	invalid := []string{"#ff", "#ggg", "#fffff", "rgb(1, 2)", "rgba(1, 2, 3, a)", "url(#g", "nocolor"}
+	for _, v := range invalid {
+		_, err := paint(v)
+		assert.Error(t, err, v)
+	}
+}
+
+func TestParse_Shapes(t *testing.T) {
+	_, c := draw(t, `<svg width="60" height="20">
+		<rect x="1" y="1" width="18" height="18" rx="6"/>
+		<circle cx="30" cy="10" r="8"/>
+		<path d="M 41 1 h 18 v 18 h -18 z m 4 4 h 10 v 10 h -10 z" fill-rule="evenodd"/>
+	</svg>`)
+
+	// the corners of the rectangle are rounded
+	assert.Equal(t, black, c.Color(pixel.V(10, 10)))
+	assert.Equal(t, black, c.Color(pixel.V(1.5, 10)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(1.5, 1.5)))
+	assert.Equal(t, black, c.Color(pixel.V(5.5, 5.5)))
+
+	assert.Equal(t, black, c.Color(pixel.V(30.5, 10.5)))
+	assert.Equal(t, black, c.Color(pixel.V(37.5, 10.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(23.5, 3.5)))
+
+	// the inner square is a hole
+	assert.Equal(t, black, c.Color(pixel.V(42.5, 10.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(50.5, 10.5)))
+}
+
+func TestParse_InvalidValues(t *testing.T) {
+	img, err := Parse(strings.NewReader(`<svg width="10" height="10">
+		<g fill="red" stroke="blue" stroke-width="2" transform="translate(1)">
+			<rect width="1" height="1" fill="nocolor" stroke-width="1em" transform="scale(1"/>
+			<rect width="1" height="1" fill="url(#gradient) lime" stroke="rgba(0, 0, 0, 0.5)"/>
+			<rect width="1" height="1" fill="url(#gradient)" stroke-linecap="triangle"/>
+		</g>
+	</svg>`))
+	assert.NoError(t, err)
+	assert.Len(t, img.Shapes, 3)
+
+	// properties with invalid or unsupported values keep the values of the group
+	s := img.Shapes[0]
+	assert.Equal(t, pixel.RGB(1, 0, 0), s.Fill)
+	assert.Equal(t, 2.0, s.StrokeWidth)
+	assert.Equal(t, pixel.V(2, 9), s.Matrix.Project(pixel.V(1, 1)))
+
+	// paint servers fall back to their fallback color, or none
+	s = img.Shapes[1]
+	assert.Equal(t, pixel.RGB(0, 1, 0), s.Fill)
+	assert.Equal(t, pixel.RGB(0, 0, 0).Scaled(0.5), s.Stroke)
+	s = img.Shapes[2]
+	assert.Equal(t, pixel.RGBA{}, s.Fill)
+	assert.Equal(t, imdraw.NoEndShape, s.EndShape)
+}
+
+func TestParse_Strokes(t *testing.T) {
+	_, c := draw(t, `<svg width="40" height="20">
+		<polyline points="5 10 15 10" fill="none" stroke="black" stroke-width="4"/>
+		<polygon points="25,5 35,5 35,15 25,15" fill="none" stroke="black" stroke-width="2"/>
+	</svg>`)
+
+	// the line isn't filled, and its ends are butt
+	assert.Equal(t, black, c.Color(pixel.V(10.5, 11.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(4.5, 10.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(10.5, 7.5)))
+
+	// the polygon is closed, with miter joins
+	assert.Equal(t, black, c.Color(pixel.V(24.5, 10.5)))
+	assert.Equal(t, black, c.Color(pixel.V(24.5, 4.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(30.5, 10.5)))
+}
+
+func TestImage_Triangles(t *testing.T) {
+	img, err := Parse(strings.NewReader(`<svg width="10" height="10">
+		<rect width="10" height="5" fill="red"/>
+	</svg>`))
+	assert.NoError(t, err)
+
+	tri := img.Triangles(pixel.IM.Moved(pixel.V(10, 0)))
+	assert.Equal(t, 6, tri.Len())
+	bounds := pixel.R((*tri)[0].Position.X, (*tri)[0].Position.Y, 0, 0)
+	bounds.Max = bounds.Min
+	for _, v := range *tri {
+		assert.Equal(t, pixel.RGB(1, 0, 0), v.Color)
+		bounds = bounds.Union(pixel.R(v.Position.X, v.Position.Y, v.Position.X, v.Position.Y))
+	}
+	// the rectangle is at the top of the image, moved by the matrix
+	assert.Equal(t, pixel.R(10, 5, 20, 10), bounds)
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, doc := range []string{
+		``,
+		`<html></html>`,
+		`<svg width="10"></svg>`,
+		`<svg width="10" height="10em"></svg>`,
+		`<svg width="10" height="10"><path d="M 0"/></svg>`,
+		`<svg width="10" height="10"><rect`,
+	} {
+		_, err := Parse(strings.NewReader(doc))
+		assert.Error(t, err, doc)
+	}
+}
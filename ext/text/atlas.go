@@ -3,6 +3,7 @@ package text
 import (
 	"image"
 	"image/draw"
+	"slices"
 	"sort"
 	"unicode"
 
@@ -29,6 +30,12 @@ type Atlas struct {
 	ascent     float64
 	descent    float64
 	lineHeight float64
+	spread     float64
+	dynamic    *dynamicAtlas
+
+	// the fonts and shaped glyphs of a shaping Atlas
+	shaper *shaper
+	shaped map[glyphKey]Glyph
 }
 
 // NewAtlas creates a new Atlas containing glyphs of the union of the given sets of runes (plus
@@ -38,16 +45,7 @@ type Atlas struct {
 //
 // Do not destroy or close the font.Face after creating the Atlas. Atlas still uses it.
 func NewAtlas(face font.Face, runeSets ...[]rune) *Atlas {
-	seen := make(map[rune]bool)
-	runes := []rune{unicode.ReplacementChar}
-	for _, set := range runeSets {
-		for _, r := range set {
-			if !seen[r] {
-				runes = append(runes, r)
-				seen[r] = true
-			}
-		}
-	}
+	runes := collectRunes(runeSets)
 
 	fixedMapping, fixedBounds := makeSquareMapping(face, runes, fixed.I(2))
 
@@ -64,6 +62,26 @@ func NewAtlas(face font.Face, runeSets ...[]rune) *Atlas {
 		}
 	}
 
+	return newAtlas(face, atlasImg, fixedMapping, fixedBounds)
+}
+
+// collectRunes returns the union of the given sets of runes plus unicode.ReplacementChar.
+func collectRunes(runeSets [][]rune) []rune {
+	seen := make(map[rune]bool)
+	runes := []rune{unicode.ReplacementChar}
+	for _, set := range runeSets {
+		for _, r := range set {
+			if !seen[r] {
+				runes = append(runes, r)
+				seen[r] = true
+			}
+		}
+	}
+	return runes
+}
+
+// newAtlas creates an Atlas of glyphs drawn to atlasImg according to fixedMapping.
+func newAtlas(face font.Face, atlasImg *image.RGBA, fixedMapping map[rune]fixedGlyph, fixedBounds fixed.Rectangle26_6) *Atlas {
 	bounds := pixel.R(
 		i2f(fixedBounds.Min.X),
 		i2f(fixedBounds.Min.Y),
@@ -100,19 +118,36 @@ func NewAtlas(face font.Face, runeSets ...[]rune) *Atlas {
 
 // Picture returns the underlying Picture containing an arrangement of all the glyphs contained
 // within the Atlas.
+//
+// The Picture of a dynamic Atlas is replaced whenever glyphs are added to it.
 func (a *Atlas) Picture() pixel.Picture {
+	if d := a.dynamic; d != nil && d.dirty {
+		pic := *d.work
+		pic.Pix = slices.Clone(d.work.Pix)
+		a.pic = &pic
+		d.dirty = false
+	}
 	return a.pic
 }
 
 // Contains reports wheter r in contained within the Atlas.
+//
+// A dynamic Atlas contains all runes its font face has a glyph for.
 func (a *Atlas) Contains(r rune) bool {
-	_, ok := a.mapping[r]
-	return ok
+	if _, ok := a.mapping[r]; ok {
+		return true
+	}
+	if a.dynamic != nil {
+		_, _, ok := a.face.GlyphBounds(r)
+		return ok
+	}
+	return false
 }
 
 // Glyph returns the description of r within the Atlas.
 func (a *Atlas) Glyph(r rune) Glyph {
-	return a.mapping[r]
+	g, _ := a.lookup(r)
+	return g
 }
 
 // Kern returns the kerning distance between runes r0 and r1. Positive distance means that the
@@ -141,12 +176,15 @@ func (a *Atlas) LineHeight() float64 {
 // Rect is a rectangle where the glyph should be positioned. Frame is the glyph frame inside the
 // Atlas's Picture. NewDot is the new position of the dot.
 func (a *Atlas) DrawRune(prevR, r rune, dot pixel.Vec) (rect, frame, bounds pixel.Rect, newDot pixel.Vec) {
-	if !a.Contains(r) {
-		r = unicode.ReplacementChar
-	}
 	if !a.Contains(unicode.ReplacementChar) {
 		return pixel.Rect{}, pixel.Rect{}, pixel.Rect{}, dot
 	}
+	glyph, ok := a.lookup(r)
+	if !ok {
+		r = unicode.ReplacementChar
+		glyph, _ = a.lookup(r)
+	}
+	a.touch(glyphKey{r: r})
 	if !a.Contains(prevR) {
 		prevR = unicode.ReplacementChar
 	}
@@ -155,25 +193,40 @@ func (a *Atlas) DrawRune(prevR, r rune, dot pixel.Vec) (rect, frame, bounds pixe
 		dot.X += a.Kern(prevR, r)
 	}
 
-	glyph := a.Glyph(r)
-
 	rect = glyph.Frame.Moved(dot.Sub(glyph.Dot))
-	bounds = rect
-
-	if bounds.W()*bounds.H() != 0 {
-		bounds = pixel.R(
-			bounds.Min.X,
-			dot.Y-a.Descent(),
-			bounds.Max.X,
-			dot.Y+a.Ascent(),
-		)
-	}
+	bounds = a.glyphRectBounds(rect, dot)
 
 	dot.X += glyph.Advance
 
 	return rect, glyph.Frame, bounds, dot
 }
 
+// drawGlyph is like DrawRune for the glyph identified by k, without kerning and without moving
+// the dot. It reports false if the Atlas doesn't have the glyph.
+func (a *Atlas) drawGlyph(k glyphKey, dot pixel.Vec) (rect, frame, bounds pixel.Rect, ok bool) {
+	glyph, ok := a.lookupKey(k)
+	if !ok {
+		return pixel.Rect{}, pixel.Rect{}, pixel.Rect{}, false
+	}
+	a.touch(k)
+	rect = glyph.Frame.Moved(dot.Sub(glyph.Dot))
+	return rect, glyph.Frame, a.glyphRectBounds(rect, dot), true
+}
+
+// glyphRectBounds returns the bounds of a glyph drawn to rect at dot, spanning the line vertically.
+func (a *Atlas) glyphRectBounds(rect pixel.Rect, dot pixel.Vec) pixel.Rect {
+	if rect.W()*rect.H() == 0 {
+		return rect
+	}
+	// the distance field around the glyphs of an SDF atlas isn't a part of the bounds
+	return pixel.R(
+		rect.Min.X+a.spread,
+		dot.Y-a.Descent(),
+		rect.Max.X-a.spread,
+		dot.Y+a.Ascent(),
+	)
+}
+
 type fixedGlyph struct {
 	dot     fixed.Point26_6
 	frame   fixed.Rectangle26_6
@@ -183,18 +236,32 @@ type fixedGlyph struct {
 // makeSquareMapping finds an optimal glyph arrangement of the given runes, so that their common
 // bounding box is as square as possible.
 func makeSquareMapping(face font.Face, runes []rune, padding fixed.Int26_6) (map[rune]fixedGlyph, fixed.Rectangle26_6) {
+	rowHeight := makeRowHeight(face, runes)
 	width := sort.Search(int(fixed.I(1024*1024)), func(i int) bool {
 		width := fixed.Int26_6(i)
-		_, bounds := makeMapping(face, runes, padding, width)
+		_, bounds := makeMapping(face, runes, padding, width, rowHeight)
 		return bounds.Max.X-bounds.Min.X >= bounds.Max.Y-bounds.Min.Y
 	})
-	return makeMapping(face, runes, padding, fixed.Int26_6(width))
+	return makeMapping(face, runes, padding, fixed.Int26_6(width), rowHeight)
+}
+
+// makeRowHeight returns the height of the rows of glyphs of the given runes. It's the height of the
+// face, unless some of the glyphs extend further, like those of a FallbackFace may.
+func makeRowHeight(face font.Face, runes []rune) fixed.Int26_6 {
+	ascent, descent := face.Metrics().Ascent, face.Metrics().Descent
+	for _, r := range runes {
+		if b, _, ok := face.GlyphBounds(r); ok {
+			ascent = max(ascent, -fixed.I(b.Min.Y.Floor()))
+			descent = max(descent, fixed.I(b.Max.Y.Ceil()))
+		}
+	}
+	return ascent + descent
 }
 
 // makeMapping arranges glyphs of the given runes into rows in such a way, that no glyph is located
 // fully to the right of the specified width. Specifically, it places glyphs in a row one by one and
 // once it reaches the specified width, it starts a new row.
-func makeMapping(face font.Face, runes []rune, padding, width fixed.Int26_6) (map[rune]fixedGlyph, fixed.Rectangle26_6) {
+func makeMapping(face font.Face, runes []rune, padding, width, rowHeight fixed.Int26_6) (map[rune]fixedGlyph, fixed.Rectangle26_6) {
 	mapping := make(map[rune]fixedGlyph)
 	bounds := fixed.Rectangle26_6{}
 
@@ -231,7 +298,7 @@ func makeMapping(face font.Face, runes []rune, padding, width fixed.Int26_6) (ma
 		// width exceeded, new row
 		if frame.Max.X >= width {
 			dot.X = 0
-			dot.Y += face.Metrics().Ascent + face.Metrics().Descent
+			dot.Y += rowHeight
 
 			// padding + align to integer
 			dot.Y += padding
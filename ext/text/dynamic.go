@@ -0,0 +1,284 @@
+package text
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"unicode"
+
+	gtfont "github.com/go-text/typesetting/font"
+	"github.com/gopxl/pixel/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// NewDynamicAtlas creates an Atlas which rasterizes glyphs from the given font face on demand, the
+// first time they're drawn, instead of requiring every rune up front. The runes of runeSets (plus
+// unicode.ReplacementChar) are rasterized right away.
+//
+// The Picture of the Atlas grows as needed, up to maxSize×maxSize pixels. Once it's full, the
+// least recently drawn glyphs are evicted to make room for new ones. A Text still showing an
+// evicted glyph draws whatever replaced it until the Text is rewritten, so maxSize should fit the
+// glyphs of all the text drawn at once.
+//
+// Do not destroy or close the font.Face after creating the Atlas. Atlas still uses it.
+func NewDynamicAtlas(face font.Face, maxSize int, runeSets ...[]rune) *Atlas {
+	size := min(maxSize, 64)
+	a := &Atlas{
+		face:       face,
+		mapping:    make(map[rune]Glyph),
+		ascent:     i2f(face.Metrics().Ascent),
+		descent:    i2f(face.Metrics().Descent),
+		lineHeight: i2f(face.Metrics().Height),
+		dynamic: &dynamicAtlas{
+			maxSize: maxSize,
+			work:    pixel.MakePictureData(pixel.R(0, 0, float64(size), float64(size))),
+			cells:   make(map[glyphKey]image.Rectangle),
+			lastUse: make(map[glyphKey]uint64),
+		},
+	}
+	for _, r := range collectRunes(runeSets) {
+		a.lookup(r)
+	}
+	return a
+}
+
+// dynamicAtlas holds the state of an Atlas created by NewDynamicAtlas. Glyph cells are allocated
+// on shelves growing from the bottom of the picture, so growing the picture doesn't move them.
+type dynamicAtlas struct {
+	maxSize int
+	work    *pixel.PictureData
+	dirty   bool
+
+	shelves []shelf
+	free    []image.Rectangle
+	cells   map[glyphKey]image.Rectangle
+
+	clock   uint64
+	lastUse map[glyphKey]uint64
+}
+
+// glyphKey identifies a glyph of a dynamic Atlas: either the glyph of a rune, or, in a shaping
+// Atlas, a glyph of one of its fonts, counted from 1.
+type glyphKey struct {
+	r    rune
+	font int
+	gid  gtfont.GID
+}
+
+// shelf is a row of glyph cells. Glyphs are placed from left to right on the first shelf tall
+// enough for them.
+type shelf struct {
+	y, h, x int
+}
+
+// dynamicPadding is the space left between glyph cells to avoid bleeding.
+const dynamicPadding = 2
+
+// lookup returns the glyph of r, rasterizing it first if the Atlas is dynamic and doesn't contain
+// it yet.
+func (a *Atlas) lookup(r rune) (Glyph, bool) {
+	g, ok := a.mapping[r]
+	if !ok && a.dynamic != nil {
+		g, ok = a.dynamic.add(a, glyphKey{r: r})
+	}
+	return g, ok
+}
+
+// lookupKey returns the glyph identified by k, rasterizing it first if needed.
+func (a *Atlas) lookupKey(k glyphKey) (Glyph, bool) {
+	if k.font == 0 {
+		return a.lookup(k.r)
+	}
+	g, ok := a.shaped[k]
+	if !ok {
+		g, ok = a.dynamic.add(a, k)
+	}
+	return g, ok
+}
+
+// touch marks the glyph identified by k as the most recently drawn one.
+func (a *Atlas) touch(k glyphKey) {
+	if a.dynamic != nil {
+		a.dynamic.clock++
+		a.dynamic.lastUse[k] = a.dynamic.clock
+	}
+}
+
+// store sets the glyph identified by k.
+func (a *Atlas) store(k glyphKey, g Glyph) {
+	if k.font == 0 {
+		a.mapping[k.r] = g
+	} else {
+		a.shaped[k] = g
+	}
+}
+
+// glyphBounds returns the bounds of the glyph identified by k, like font.Face.GlyphBounds.
+func (a *Atlas) glyphBounds(k glyphKey) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	if k.font == 0 {
+		return a.face.GlyphBounds(k.r)
+	}
+	return a.shaper.glyphBounds(k.font-1, k.gid)
+}
+
+// glyphMask returns the mask of the glyph identified by k, like font.Face.Glyph at a zero dot.
+func (a *Atlas) glyphMask(k glyphKey) (dr image.Rectangle, mask image.Image, maskp image.Point, ok bool) {
+	if k.font == 0 {
+		dr, mask, maskp, _, ok = a.face.Glyph(fixed.Point26_6{}, k.r)
+		return dr, mask, maskp, ok
+	}
+	return a.shaper.glyphMask(k.font-1, k.gid)
+}
+
+// add rasterizes the glyph identified by k into the picture of a.
+func (d *dynamicAtlas) add(a *Atlas, k glyphKey) (Glyph, bool) {
+	b, advance, ok := a.glyphBounds(k)
+	if !ok {
+		return Glyph{}, false
+	}
+	frame := image.Rect(b.Min.X.Floor(), b.Min.Y.Floor(), b.Max.X.Ceil(), b.Max.Y.Ceil())
+	if frame.Empty() {
+		g := Glyph{Advance: i2f(advance)}
+		a.store(k, g)
+		return g, true
+	}
+
+	cell, ok := d.alloc(a, frame.Dx()+dynamicPadding, frame.Dy()+dynamicPadding, k)
+	if !ok {
+		return Glyph{}, false
+	}
+	d.cells[k] = cell
+
+	// the picture is y-up, the glyph mask y-down
+	if dr, mask, maskp, ok := a.glyphMask(k); ok {
+		dr = dr.Intersect(frame)
+		for y := dr.Min.Y; y < dr.Max.Y; y++ {
+			for x := dr.Min.X; x < dr.Max.X; x++ {
+				_, _, _, alpha := mask.At(maskp.X+x-dr.Min.X, maskp.Y+y-dr.Min.Y).RGBA()
+				c := uint8(alpha >> 8)
+				at := pixel.V(float64(cell.Min.X+x-frame.Min.X), float64(cell.Min.Y+frame.Max.Y-1-y))
+				d.work.Pix[d.work.Index(at)] = color.RGBA{c, c, c, c}
+			}
+		}
+	}
+	d.dirty = true
+
+	g := Glyph{
+		Dot: pixel.V(float64(cell.Min.X-frame.Min.X), float64(cell.Min.Y+frame.Max.Y)),
+		Frame: pixel.R(
+			float64(cell.Min.X),
+			float64(cell.Min.Y),
+			float64(cell.Min.X+frame.Dx()),
+			float64(cell.Min.Y+frame.Dy()),
+		),
+		Advance: i2f(advance),
+	}
+	a.store(k, g)
+	return g, true
+}
+
+// alloc finds a w×h cell for the glyph identified by k, growing the picture or evicting the least
+// recently used glyphs as needed.
+func (d *dynamicAtlas) alloc(a *Atlas, w, h int, k glyphKey) (image.Rectangle, bool) {
+	for {
+		if cell, ok := d.place(w, h); ok {
+			return cell, true
+		}
+		if d.grow() {
+			continue
+		}
+		if !d.evict(a, k) {
+			return image.Rectangle{}, false
+		}
+	}
+}
+
+// place allocates a w×h cell from the freed cells or the shelves, without growing the picture.
+func (d *dynamicAtlas) place(w, h int) (image.Rectangle, bool) {
+	best := -1
+	for i, c := range d.free {
+		if c.Dx() >= w && c.Dy() >= h && (best < 0 || c.Dx()*c.Dy() < d.free[best].Dx()*d.free[best].Dy()) {
+			best = i
+		}
+	}
+	if best >= 0 {
+		cell := d.free[best]
+		d.free = slices.Delete(d.free, best, best+1)
+		return cell, true
+	}
+
+	width, height := int(d.work.Rect.W()), int(d.work.Rect.H())
+	for i := range d.shelves {
+		s := &d.shelves[i]
+		// don't waste shelves much taller than the glyph
+		if h <= s.h && h*2 > s.h && s.x+w <= width {
+			cell := image.Rect(s.x, s.y, s.x+w, s.y+s.h)
+			s.x += w
+			return cell, true
+		}
+	}
+
+	top := 0
+	if n := len(d.shelves); n > 0 {
+		top = d.shelves[n-1].y + d.shelves[n-1].h
+	}
+	if top+h > height || w > width {
+		return image.Rectangle{}, false
+	}
+	d.shelves = append(d.shelves, shelf{y: top, h: h, x: w})
+	return image.Rect(0, top, w, top+h), true
+}
+
+// grow doubles the smaller dimension of the picture, unless it's already at the maximum size.
+func (d *dynamicAtlas) grow() bool {
+	width, height := int(d.work.Rect.W()), int(d.work.Rect.H())
+	if width >= d.maxSize && height >= d.maxSize {
+		return false
+	}
+	if width <= height && width < d.maxSize {
+		width = min(2*width, d.maxSize)
+	} else {
+		height = min(2*height, d.maxSize)
+	}
+
+	grown := pixel.MakePictureData(pixel.R(0, 0, float64(width), float64(height)))
+	for y := 0; y < int(d.work.Rect.H()); y++ {
+		copy(grown.Pix[y*grown.Stride:], d.work.Pix[y*d.work.Stride:(y+1)*d.work.Stride])
+	}
+	d.work = grown
+	d.dirty = true
+	return true
+}
+
+// evict removes the least recently used glyph other than keep and unicode.ReplacementChar from the
+// picture. It reports false if there's no glyph to evict.
+func (d *dynamicAtlas) evict(a *Atlas, keep glyphKey) bool {
+	replacement := glyphKey{r: unicode.ReplacementChar}
+	victim, found, oldest := glyphKey{}, false, uint64(math.MaxUint64)
+	for k := range d.cells {
+		if k != keep && k != replacement && d.lastUse[k] < oldest {
+			victim, found, oldest = k, true, d.lastUse[k]
+		}
+	}
+	if !found {
+		return false
+	}
+
+	cell := d.cells[victim]
+	for y := cell.Min.Y; y < cell.Max.Y; y++ {
+		row := d.work.Pix[y*d.work.Stride:]
+		clear(row[cell.Min.X:cell.Max.X])
+	}
+	if victim.font == 0 {
+		delete(a.mapping, victim.r)
+	} else {
+		delete(a.shaped, victim)
+	}
+	delete(d.cells, victim)
+	delete(d.lastUse, victim)
+	d.free = append(d.free, cell)
+	d.dirty = true
+	return true
+}
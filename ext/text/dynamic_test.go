@@ -0,0 +1,72 @@
+package text_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font/basicfont"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/gopxl/pixel/v2/ext/text"
+)
+
+func render(atlas *text.Atlas, s string) []uint8 {
+	txt := text.New(pixel.ZV, atlas)
+	txt.WriteString(s)
+	c := software.NewCanvas(pixel.R(0, 0, 80, 20))
+	txt.Draw(c, pixel.IM.Moved(pixel.V(2, 5)))
+	return c.Pixels()
+}
+
+func TestDynamicAtlas_Lazy(t *testing.T) {
+	atlas := text.NewDynamicAtlas(basicfont.Face7x13, 256)
+	assert.Equal(t, pixel.R(0, 0, 64, 64), atlas.Picture().Bounds())
+	assert.True(t, atlas.Contains('x'))
+	assert.False(t, atlas.Contains('Å'))
+
+	assert.Equal(t, render(text.Atlas7x13, "Hello, 123!"), render(atlas, "Hello, 123!"))
+	frame := atlas.Glyph('H').Frame
+
+	// growing the picture keeps the glyphs in place
+	render(atlas, string(text.ASCII))
+	assert.Equal(t, pixel.R(0, 0, 128, 128), atlas.Picture().Bounds())
+	assert.Equal(t, frame, atlas.Glyph('H').Frame)
+	assert.Equal(t, render(text.Atlas7x13, "Hello, 123!"), render(atlas, "Hello, 123!"))
+
+	// a Text drawn before picks up the new picture
+	atlas = text.NewDynamicAtlas(basicfont.Face7x13, 256)
+	txt := text.New(pixel.ZV, atlas)
+	c := software.NewCanvas(pixel.R(0, 0, 80, 20))
+	txt.WriteString("Hello")
+	txt.Draw(c, pixel.IM.Moved(pixel.V(2, 5)))
+	txt.WriteString(", 123!")
+	c.Clear(pixel.RGBA{})
+	txt.Draw(c, pixel.IM.Moved(pixel.V(2, 5)))
+	assert.Equal(t, render(text.Atlas7x13, "Hello, 123!"), c.Pixels())
+
+	// missing runes are replaced
+	assert.Equal(t, render(text.Atlas7x13, "Å"), render(atlas, "Å"))
+}
+
+func TestDynamicAtlas_Evict(t *testing.T) {
+	// fits the replacement glyph and 7 other glyphs
+	atlas := text.NewDynamicAtlas(basicfont.Face7x13, 32)
+	render(atlas, "abcdefg")
+	frameA := atlas.Glyph('a').Frame
+	frameC := atlas.Glyph('c').Frame
+
+	// 'a' is the least recently used glyph
+	render(atlas, "b")
+	render(atlas, "h")
+	assert.Equal(t, frameA, atlas.Glyph('h').Frame)
+	assert.Equal(t, pixel.R(0, 0, 32, 32), atlas.Picture().Bounds())
+
+	// then 'c', since 'b' was drawn again
+	render(atlas, "i")
+	assert.Equal(t, frameC, atlas.Glyph('i').Frame)
+
+	assert.Equal(t, render(text.Atlas7x13, "bdefghi"), render(atlas, "bdefghi"))
+	// an evicted glyph is added back
+	assert.Equal(t, render(text.Atlas7x13, "ace"), render(atlas, "ace"))
+}
@@ -0,0 +1,158 @@
+package text
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// FallbackFace is a font.Face combining an ordered list of faces. Each rune is taken from the
+// first face with a glyph for it, so that, for example, a Latin face can be completed by CJK, emoji
+// or symbol faces:
+//
+//	face := text.NewFallbackFace(latin, cjk, emoji)
+//	atlas := text.NewDynamicAtlas(face, 2048, text.ASCII)
+//
+// The vertical metrics are those of the first face, so Ascent, Descent and LineHeight of an Atlas
+// made from a FallbackFace don't depend on which faces its glyphs come from. All glyphs share the
+// baseline, thus the faces should be of a similar size.
+//
+// A face has a glyph for a rune if GlyphAdvance reports it as present. The faces of
+// golang.org/x/image/font/opentype and basicfont report missing runes as absent, but the faces of
+// github.com/golang/freetype/truetype report them as present, with the glyph drawn for missing
+// runes. Create those with NewTruetypeFace instead of truetype.NewFace.
+type FallbackFace struct {
+	faces []font.Face
+	index map[rune]int
+}
+
+// notdefRune is a noncharacter, which no font has a glyph for.
+const notdefRune = '\uFFFF'
+
+// NewFallbackFace creates a FallbackFace taking runes from the given faces in order. At least one
+// face is required. It panics if a face other than the last one reports missing runes as present,
+// as faces created by truetype.NewFace do, since no rune would be taken from the faces after it.
+func NewFallbackFace(faces ...font.Face) *FallbackFace {
+	if len(faces) == 0 {
+		panic("text.NewFallbackFace: no faces")
+	}
+	for i, face := range faces[:len(faces)-1] {
+		if _, ok := face.GlyphAdvance(notdefRune); ok {
+			panic(fmt.Sprintf("text.NewFallbackFace: face %d reports missing runes as present", i))
+		}
+	}
+	return &FallbackFace{
+		faces: faces,
+		index: make(map[rune]int),
+	}
+}
+
+// NewTruetypeFace returns a face of a truetype font like truetype.NewFace, except that the runes
+// the font has no glyph for are reported as missing, so that a FallbackFace takes them from its
+// next face.
+func NewTruetypeFace(f *truetype.Font, opts *truetype.Options) font.Face {
+	return truetypeFace{Face: truetype.NewFace(f, opts), font: f}
+}
+
+type truetypeFace struct {
+	font.Face
+	font *truetype.Font
+}
+
+func (f truetypeFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	if f.font.Index(r) == 0 {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	return f.Face.Glyph(dot, r)
+}
+
+func (f truetypeFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	if f.font.Index(r) == 0 {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+	return f.Face.GlyphBounds(r)
+}
+
+func (f truetypeFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	if f.font.Index(r) == 0 {
+		return 0, false
+	}
+	return f.Face.GlyphAdvance(r)
+}
+
+// Face returns the face the glyph of r is taken from, or nil if none of the faces has it.
+func (f *FallbackFace) Face(r rune) font.Face {
+	if i := f.find(r); i >= 0 {
+		return f.faces[i]
+	}
+	return nil
+}
+
+func (f *FallbackFace) find(r rune) int {
+	if i, ok := f.index[r]; ok {
+		return i
+	}
+	i := -1
+	for j, face := range f.faces {
+		if _, ok := face.GlyphAdvance(r); ok {
+			i = j
+			break
+		}
+	}
+	f.index[r] = i
+	return i
+}
+
+// Close closes all of the faces, returning the first error.
+func (f *FallbackFace) Close() error {
+	var err error
+	for _, face := range f.faces {
+		if e := face.Close(); err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Glyph returns the glyph of r from the first face that has it.
+func (f *FallbackFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	face := f.Face(r)
+	if face == nil {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	return face.Glyph(dot, r)
+}
+
+// GlyphBounds returns the bounds of r from the first face that has it.
+func (f *FallbackFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	face := f.Face(r)
+	if face == nil {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+	return face.GlyphBounds(r)
+}
+
+// GlyphAdvance returns the advance of r from the first face that has it.
+func (f *FallbackFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	face := f.Face(r)
+	if face == nil {
+		return 0, false
+	}
+	return face.GlyphAdvance(r)
+}
+
+// Kern returns the kerning of r0 and r1 if both are taken from the same face, zero otherwise.
+func (f *FallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	if i := f.find(r0); i >= 0 && i == f.find(r1) {
+		return f.faces[i].Kern(r0, r1)
+	}
+	return 0
+}
+
+// Metrics returns the metrics of the first face.
+func (f *FallbackFace) Metrics() font.Metrics {
+	return f.faces[0].Metrics()
+}
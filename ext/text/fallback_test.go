@@ -0,0 +1,126 @@
+package text_test
+
+import (
+	"image"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+)
+
+func goRegularFace(t *testing.T, size float64) font.Face {
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return face
+}
+
+func TestFallbackFace(t *testing.T) {
+	goFace := goRegularFace(t, 13)
+	face := text.NewFallbackFace(basicfont.Face7x13, goFace)
+
+	assert.Equal(t, basicfont.Face7x13, face.Face('A'))
+	assert.Equal(t, goFace, face.Face('Ω'))
+	assert.Nil(t, face.Face('中'))
+	assert.Equal(t, basicfont.Face7x13.Metrics(), face.Metrics())
+
+	advance, ok := face.GlyphAdvance('Ω')
+	expected, _ := goFace.GlyphAdvance('Ω')
+	assert.True(t, ok)
+	assert.Equal(t, expected, advance)
+	_, ok = face.GlyphAdvance('中')
+	assert.False(t, ok)
+}
+
+// cjkFace adds '中' to basicfont, drawn as '?'.
+type cjkFace struct {
+	font.Face
+}
+
+func (f cjkFace) Glyph(dot fixed.Point26_6, r rune) (
+	dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool,
+) {
+	if r == '中' {
+		r = '?'
+	}
+	return f.Face.Glyph(dot, r)
+}
+
+func (f cjkFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	if r == '中' {
+		r = '?'
+	}
+	return f.Face.GlyphAdvance(r)
+}
+
+func TestFallbackFace_Truetype(t *testing.T) {
+	// faces of truetype.NewFace report missing runes as present, with their missing glyph
+	ttf, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cjk := cjkFace{basicfont.Face7x13}
+	assert.Panics(t, func() {
+		text.NewFallbackFace(truetype.NewFace(ttf, &truetype.Options{Size: 13}), cjk)
+	})
+	assert.NotPanics(t, func() {
+		text.NewFallbackFace(cjk, truetype.NewFace(ttf, &truetype.Options{Size: 13}))
+	})
+
+	ttFace := text.NewTruetypeFace(ttf, &truetype.Options{Size: 13})
+	face := text.NewFallbackFace(ttFace, cjk)
+	assert.Equal(t, ttFace, face.Face('A'))
+	assert.Equal(t, ttFace, face.Face(' '))
+	assert.Equal(t, ttFace, face.Face('Ω'))
+	assert.Equal(t, cjk, face.Face('中'))
+	assert.Nil(t, face.Face('😀'))
+
+	_, _, _, _, ok := ttFace.Glyph(fixed.Point26_6{}, '中')
+	assert.False(t, ok)
+	_, _, ok = ttFace.GlyphBounds('中')
+	assert.False(t, ok)
+	_, ok = ttFace.GlyphAdvance('A')
+	assert.True(t, ok)
+}
+
+func TestFallbackFace_Atlas(t *testing.T) {
+	goFace := goRegularFace(t, 16)
+	face := text.NewFallbackFace(basicfont.Face7x13, goFace)
+	atlas := text.NewAtlas(face, text.ASCII, []rune("ÅΩЖ"))
+
+	assert.True(t, atlas.Contains('Ω'))
+	assert.False(t, atlas.Contains('中'))
+	assert.Equal(t, text.Atlas7x13.LineHeight(), atlas.LineHeight())
+	assert.Equal(t, text.Atlas7x13.Ascent(), atlas.Ascent())
+	assert.Equal(t, text.Atlas7x13.Descent(), atlas.Descent())
+
+	// 'Å' is taller than the glyphs of the primary face, but doesn't overlap other glyphs
+	for _, r := range text.ASCII {
+		assert.Zero(t, atlas.Glyph('Å').Frame.Intersect(atlas.Glyph(r).Frame).Area(), string(r))
+	}
+
+	txt := text.New(pixel.ZV, atlas)
+	txt.WriteString("AΩ")
+	advance, _ := goFace.GlyphAdvance('Ω')
+	assert.InDelta(t, 7+float64(advance)/64, txt.Dot.X, 1e-9)
+
+	assert.Equal(t, render(text.Atlas7x13, "Hello, 123!"), render(atlas, "Hello, 123!"))
+	assert.NotEqual(t, render(atlas, "?"), render(atlas, "Ω"))
+
+	// glyphs of the fallback faces are rasterized on demand as well
+	dynamic := text.NewDynamicAtlas(face, 256)
+	assert.Equal(t, render(atlas, "AÅΩЖ"), render(dynamic, "AÅΩЖ"))
+}
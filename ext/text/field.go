@@ -0,0 +1,492 @@
+package text
+
+import (
+	"image/color"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+// FieldInput is the input a Field is updated from. It's implemented by pixel.Window.
+type FieldInput interface {
+	pixel.WindowInput
+
+	// ClipboardText returns the current value of the clipboard.
+	ClipboardText() string
+
+	// SetClipboardText sets the clipboard.
+	SetClipboardText(text string)
+}
+
+// Field is an editable single line of text, such as a name or a search box, drawn with a Text.
+//
+// Call Update once per frame, after the window has been updated, to apply the input to the Field,
+// and Draw to draw it:
+//
+//	field := text.NewField(pixel.V(20, 20), atlas)
+//	field.Width = 200
+//
+//	for !win.Closed() {
+//		field.Update(win, pixel.IM)
+//		win.Clear(colornames.Black)
+//		field.Draw(win, pixel.IM)
+//		win.Update()
+//	}
+//
+// Clicking the Field focuses it, clicking elsewhere unfocuses it. A focused Field handles typing,
+// moving the caret with the arrow keys, Home and End, selecting with Shift or by dragging the mouse,
+// jumping over words with Ctrl (or Super), deleting with Backspace and Delete, and Ctrl+A, Ctrl+C,
+// Ctrl+X, Ctrl+V, Ctrl+Z and Ctrl+Y (or Ctrl+Shift+Z) for selecting all, copy, cut, paste, undo
+// and redo.
+//
+// With an Atlas created by NewShapingAtlas, the caret and the selection are placed on the shaped
+// text: a right-to-left rune has its caret on its right, and the runes of a ligature split it.
+type Field struct {
+	// Focused reports whether the Field receives keyboard input. It's set by clicking, but can
+	// also be set directly.
+	Focused bool
+
+	// Mask, if not zero, is drawn instead of each rune of the value, e.g. '*' for passwords. The
+	// value of a masked Field can't be copied or cut, and word jumps go to its start or end.
+	Mask rune
+
+	// MaxLength is the maximum number of runes of the value. Zero means no limit.
+	MaxLength int
+
+	// Width is the minimum width of the Field's Bounds, so that an empty Field can be clicked.
+	Width float64
+
+	// Color is the color of the text, SelectionColor of the selection and CaretColor of the caret.
+	Color          color.Color
+	SelectionColor color.Color
+	CaretColor     color.Color
+
+	txt       *Text
+	value     []rune
+	caret     int
+	anchor    int
+	positions []float64
+	edges     [][2]float64
+	dragging  bool
+	dirty     bool
+
+	undo     []fieldState
+	redo     []fieldState
+	lastEdit fieldEdit
+
+	selection  pixel.TrianglesData
+	selectionD pixel.Drawer
+	caretTris  pixel.TrianglesData
+	caretD     pixel.Drawer
+}
+
+// fieldState is a snapshot of a Field kept for undo and redo.
+type fieldState struct {
+	value         []rune
+	caret, anchor int
+}
+
+// fieldEdit is the kind of the last edit, consecutive edits of the same kind are undone at once.
+type fieldEdit int
+
+const (
+	editNone fieldEdit = iota
+	editType
+	editDelete
+	editOther
+)
+
+// maxUndo is the number of edits a Field can undo.
+const maxUndo = 100
+
+// NewField creates a new empty Field drawn with the provided Atlas. The baseline of the text starts
+// at orig.
+func NewField(orig pixel.Vec, atlas *Atlas) *Field {
+	f := &Field{
+		Color:          pixel.Alpha(1),
+		SelectionColor: pixel.RGB(0.2, 0.4, 0.8).Mul(pixel.Alpha(0.5)),
+		CaretColor:     pixel.Alpha(1),
+		txt:            New(orig, atlas),
+		dirty:          true,
+	}
+	f.selectionD.Triangles = &f.selection
+	f.caretD.Triangles = &f.caretTris
+	return f
+}
+
+// Value returns the text of the Field.
+func (f *Field) Value() string {
+	return string(f.value)
+}
+
+// SetValue replaces the text of the Field, moves the caret to its end and clears the undo history.
+func (f *Field) SetValue(s string) {
+	f.value = f.clean(s)
+	f.caret, f.anchor = len(f.value), len(f.value)
+	f.undo, f.redo = nil, nil
+	f.lastEdit = editNone
+	f.dirty = true
+}
+
+// Caret returns the position of the caret as an index of a rune of the value.
+func (f *Field) Caret() int {
+	return f.caret
+}
+
+// SetCaret moves the caret to the rune index i and clears the selection.
+func (f *Field) SetCaret(i int) {
+	f.Select(i, i)
+}
+
+// Selection returns the rune indices of the start and the end of the selected text. Both are equal
+// to the caret if no text is selected.
+func (f *Field) Selection() (start, end int) {
+	return min(f.caret, f.anchor), max(f.caret, f.anchor)
+}
+
+// Select selects the runes from start to end, leaving the caret at end.
+func (f *Field) Select(start, end int) {
+	f.anchor = min(max(start, 0), len(f.value))
+	f.caret = min(max(end, 0), len(f.value))
+	f.lastEdit = editNone
+}
+
+// SelectedText returns the selected text.
+func (f *Field) SelectedText() string {
+	start, end := f.Selection()
+	return string(f.value[start:end])
+}
+
+// Insert replaces the selected text with s, as if it was pasted. Newlines and tabs are replaced by
+// spaces, other control characters are dropped, and the text is cut at MaxLength.
+func (f *Field) Insert(s string) {
+	f.insert(s, editOther)
+}
+
+// Undo reverts the last edit.
+func (f *Field) Undo() {
+	if len(f.undo) == 0 {
+		return
+	}
+	f.redo = append(f.redo, f.state())
+	f.restore(f.undo[len(f.undo)-1])
+	f.undo = f.undo[:len(f.undo)-1]
+}
+
+// Redo reapplies the last undone edit.
+func (f *Field) Redo() {
+	if len(f.redo) == 0 {
+		return
+	}
+	f.undo = append(f.undo, f.state())
+	f.restore(f.redo[len(f.redo)-1])
+	f.redo = f.redo[:len(f.redo)-1]
+}
+
+// Bounds returns the area of the Field which focuses it when clicked, before being transformed by
+// the Matrix it's drawn with. It spans Width or the text, whichever is wider, and the height of a
+// line.
+func (f *Field) Bounds() pixel.Rect {
+	f.layout()
+	atlas := f.txt.Atlas()
+	orig := f.txt.Orig
+	return pixel.R(
+		orig.X,
+		orig.Y-atlas.Descent(),
+		max(orig.X+f.Width, slices.Max(f.positions)),
+		orig.Y+atlas.Ascent(),
+	)
+}
+
+// Update applies the keyboard and mouse input to the Field. The Matrix is the one the Field is
+// drawn with, it's used to find the clicked runes.
+func (f *Field) Update(in FieldInput, matrix pixel.Matrix) {
+	shift := in.Pressed(pixel.KeyLeftShift) || in.Pressed(pixel.KeyRightShift)
+	ctrl := in.Pressed(pixel.KeyLeftControl) || in.Pressed(pixel.KeyRightControl) ||
+		in.Pressed(pixel.KeyLeftSuper) || in.Pressed(pixel.KeyRightSuper)
+
+	mouse := matrix.Unproject(in.MousePosition())
+	if in.JustPressed(pixel.MouseButtonLeft) {
+		f.Focused = f.Bounds().Contains(mouse)
+		f.dragging = f.Focused
+		if f.Focused {
+			f.moveCaret(f.indexAt(mouse.X), shift)
+		}
+	} else if f.dragging {
+		f.dragging = in.Pressed(pixel.MouseButtonLeft)
+		f.moveCaret(f.indexAt(mouse.X), true)
+	}
+
+	if !f.Focused {
+		return
+	}
+
+	pressed := func(button pixel.Button) bool {
+		return in.JustPressed(button) || in.Repeated(button)
+	}
+	start, end := f.Selection()
+
+	switch {
+	case pressed(pixel.KeyLeft) && start != end && !shift:
+		f.SetCaret(start)
+	case pressed(pixel.KeyLeft) && ctrl:
+		f.moveCaret(f.wordStart(f.caret), shift)
+	case pressed(pixel.KeyLeft):
+		f.moveCaret(f.caret-1, shift)
+	case pressed(pixel.KeyRight) && start != end && !shift:
+		f.SetCaret(end)
+	case pressed(pixel.KeyRight) && ctrl:
+		f.moveCaret(f.wordEnd(f.caret), shift)
+	case pressed(pixel.KeyRight):
+		f.moveCaret(f.caret+1, shift)
+	case pressed(pixel.KeyHome):
+		f.moveCaret(0, shift)
+	case pressed(pixel.KeyEnd):
+		f.moveCaret(len(f.value), shift)
+	}
+
+	start, end = f.Selection()
+	switch {
+	case pressed(pixel.KeyBackspace) && start == end && ctrl:
+		f.replace(f.wordStart(f.caret), f.caret, "", editDelete)
+	case pressed(pixel.KeyBackspace) && start == end:
+		f.replace(max(f.caret-1, 0), f.caret, "", editDelete)
+	case pressed(pixel.KeyDelete) && start == end && ctrl:
+		f.replace(f.caret, f.wordEnd(f.caret), "", editDelete)
+	case pressed(pixel.KeyDelete) && start == end:
+		f.replace(f.caret, min(f.caret+1, len(f.value)), "", editDelete)
+	case pressed(pixel.KeyBackspace) || pressed(pixel.KeyDelete):
+		f.insert("", editOther)
+	}
+
+	if ctrl {
+		start, end = f.Selection()
+		switch {
+		case pressed(pixel.KeyA):
+			f.Select(0, len(f.value))
+		case pressed(pixel.KeyC) && f.Mask == 0 && start != end:
+			in.SetClipboardText(f.SelectedText())
+		case pressed(pixel.KeyX) && f.Mask == 0 && start != end:
+			in.SetClipboardText(f.SelectedText())
+			f.insert("", editOther)
+		case pressed(pixel.KeyV):
+			f.insert(in.ClipboardText(), editOther)
+		case pressed(pixel.KeyZ) && shift, pressed(pixel.KeyY):
+			f.Redo()
+		case pressed(pixel.KeyZ):
+			f.Undo()
+		}
+	}
+
+	if typed := in.Typed(); typed != "" {
+		f.insert(typed, editType)
+	}
+}
+
+// Draw draws the Field onto the Target, transformed by the Matrix. The selection and the caret are
+// only drawn while the Field is focused.
+func (f *Field) Draw(t pixel.Target, matrix pixel.Matrix) {
+	f.layout()
+
+	if !f.Focused {
+		f.txt.Draw(t, matrix)
+		return
+	}
+
+	atlas := f.txt.Atlas()
+	y0, y1 := f.txt.Orig.Y-atlas.Descent(), f.txt.Orig.Y+atlas.Ascent()
+	start, end := f.Selection()
+	x := f.positions[f.caret]
+
+	// the selection goes under the text, the caret over it
+	f.selection.SetLen(0)
+	if start != end {
+		// a box for each visually contiguous part of the selection, which may be split by
+		// bidirectional text
+		col := pixel.ToRGBA(f.SelectionColor)
+		var sel pixel.Rect
+		for i := start; i < end; i++ {
+			e := f.edges[i]
+			r := pixel.R(min(e[0], e[1]), y0, max(e[0], e[1]), y1)
+			switch {
+			case i == start:
+				sel = r
+			case r.Min.X == sel.Max.X || r.Max.X == sel.Min.X:
+				sel = sel.Union(r)
+			default:
+				f.selection = appendQuad(f.selection, sel, col)
+				sel = r
+			}
+		}
+		f.selection = appendQuad(f.selection, sel, col)
+		drawBoxes(t, &f.selectionD, matrix)
+	}
+	f.txt.Draw(t, matrix)
+	f.caretTris = appendQuad(f.caretTris[:0], pixel.R(x, y0, x+1, y1), pixel.ToRGBA(f.CaretColor))
+	drawBoxes(t, &f.caretD, matrix)
+}
+
+// drawBoxes transforms the triangles of the Drawer by the Matrix and draws them.
+func drawBoxes(t pixel.Target, d *pixel.Drawer, matrix pixel.Matrix) {
+	tris := *d.Triangles.(*pixel.TrianglesData)
+	for i := range tris {
+		tris[i].Position = matrix.Project(tris[i].Position)
+	}
+	d.Dirty()
+	d.Draw(t)
+}
+
+// layout rewrites the Text and the caret positions after the value changed. With a shaping Atlas,
+// the positions are taken from the shaped glyph clusters, otherwise from the advances of the runes.
+func (f *Field) layout() {
+	if !f.dirty {
+		return
+	}
+	f.dirty = false
+
+	display := f.value
+	if f.Mask != 0 {
+		display = []rune(strings.Repeat(string(f.Mask), len(f.value)))
+	}
+
+	f.txt.Clear()
+	f.txt.Color = f.Color
+	f.txt.WriteString(string(display))
+
+	if atlas := f.txt.Atlas(); atlas.shaper != nil {
+		f.edges = f.txt.runeEdges(len(display), f.edges)
+	} else {
+		f.edges = f.edges[:0]
+		dot := f.txt.Orig
+		prev := rune(-1)
+		for _, r := range display {
+			x := dot.X
+			_, _, _, dot = atlas.DrawRune(prev, r, dot)
+			f.edges = append(f.edges, [2]float64{x, dot.X})
+			prev = r
+		}
+	}
+
+	// the caret is at the leading edge of the rune after it, or after the last rune
+	f.positions = f.positions[:0]
+	for _, e := range f.edges {
+		f.positions = append(f.positions, e[0])
+	}
+	end := f.txt.Orig.X
+	if len(f.edges) > 0 {
+		end = f.edges[len(f.edges)-1][1]
+	}
+	f.positions = append(f.positions, end)
+}
+
+// indexAt returns the index of the caret position closest to x.
+func (f *Field) indexAt(x float64) int {
+	f.layout()
+	best := 0
+	for i, p := range f.positions {
+		if math.Abs(p-x) < math.Abs(f.positions[best]-x) {
+			best = i
+		}
+	}
+	return best
+}
+
+// moveCaret moves the caret to i, extending the selection if selecting.
+func (f *Field) moveCaret(i int, selecting bool) {
+	anchor := f.anchor
+	if !selecting {
+		anchor = i
+	}
+	f.Select(anchor, i)
+}
+
+// wordStart returns the index of the start of the word before i.
+func (f *Field) wordStart(i int) int {
+	if f.Mask != 0 {
+		return 0
+	}
+	for i > 0 && !isWordRune(f.value[i-1]) {
+		i--
+	}
+	for i > 0 && isWordRune(f.value[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordEnd returns the index of the end of the word after i.
+func (f *Field) wordEnd(i int) int {
+	if f.Mask != 0 {
+		return len(f.value)
+	}
+	for i < len(f.value) && !isWordRune(f.value[i]) {
+		i++
+	}
+	for i < len(f.value) && isWordRune(f.value[i]) {
+		i++
+	}
+	return i
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// insert replaces the selection with s.
+func (f *Field) insert(s string, edit fieldEdit) {
+	start, end := f.Selection()
+	f.replace(start, end, s, edit)
+}
+
+// replace replaces the runes from start to end with s and moves the caret after them. Consecutive
+// edits of the same kind, other than editOther, are undone together.
+func (f *Field) replace(start, end int, s string, edit fieldEdit) {
+	runes := f.clean(s)
+	if f.MaxLength > 0 {
+		runes = runes[:min(len(runes), max(f.MaxLength-len(f.value)+end-start, 0))]
+	}
+	if start == end && len(runes) == 0 {
+		return
+	}
+
+	if edit == editOther || edit != f.lastEdit {
+		f.undo = append(f.undo, f.state())
+		if len(f.undo) > maxUndo {
+			f.undo = slices.Delete(f.undo, 0, 1)
+		}
+	}
+	f.redo = nil
+
+	f.value = slices.Replace(f.value, start, end, runes...)
+	f.caret, f.anchor = start+len(runes), start+len(runes)
+	f.lastEdit = edit
+	f.dirty = true
+}
+
+// clean replaces newlines and tabs in s with spaces and drops other control characters.
+func (f *Field) clean(s string) []rune {
+	var runes []rune
+	for _, r := range s {
+		switch {
+		case r == '\n' || r == '\t':
+			runes = append(runes, ' ')
+		case !unicode.IsControl(r):
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}
+
+func (f *Field) state() fieldState {
+	return fieldState{value: slices.Clone(f.value), caret: f.caret, anchor: f.anchor}
+}
+
+func (f *Field) restore(s fieldState) {
+	f.value = s.value
+	f.caret, f.anchor = s.caret, s.anchor
+	f.lastEdit = editNone
+	f.dirty = true
+}
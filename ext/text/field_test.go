@@ -0,0 +1,254 @@
+package text_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/headless"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/gopxl/pixel/v2/ext/text"
+)
+
+func newFieldWindow(t *testing.T) (*headless.Window, *text.Field) {
+	win, err := headless.NewWindow(headless.WindowConfig{
+		Title:  "testing",
+		Bounds: pixel.R(0, 0, 200, 50),
+	})
+	require.NoError(t, err)
+	field := text.NewField(pixel.V(10, 10), text.Atlas7x13)
+	field.Width = 100
+	field.Focused = true
+	return win, field
+}
+
+// update updates the window and then the field.
+func update(win *headless.Window, field *text.Field) {
+	win.Update()
+	field.Update(win, pixel.IM)
+}
+
+// press presses the buttons at once, then releases them.
+func press(win *headless.Window, field *text.Field, buttons ...pixel.Button) {
+	for _, b := range buttons {
+		win.ButtonEvent(b, pixel.Press)
+	}
+	update(win, field)
+	for _, b := range buttons {
+		win.ButtonEvent(b, pixel.Release)
+	}
+	update(win, field)
+}
+
+func TestField_Typing(t *testing.T) {
+	win, field := newFieldWindow(t)
+
+	win.TypeEvent("hello world")
+	update(win, field)
+	assert.Equal(t, "hello world", field.Value())
+	assert.Equal(t, 11, field.Caret())
+
+	press(win, field, pixel.KeyBackspace)
+	assert.Equal(t, "hello worl", field.Value())
+	press(win, field, pixel.KeyLeftControl, pixel.KeyBackspace)
+	assert.Equal(t, "hello ", field.Value())
+
+	press(win, field, pixel.KeyHome)
+	press(win, field, pixel.KeyDelete)
+	assert.Equal(t, "ello ", field.Value())
+	win.ButtonEvent(pixel.KeyDelete, pixel.Press)
+	update(win, field)
+	win.ButtonEvent(pixel.KeyDelete, pixel.Repeat)
+	update(win, field)
+	assert.Equal(t, "lo ", field.Value())
+	win.ButtonEvent(pixel.KeyDelete, pixel.Release)
+
+	// unfocused fields ignore the keyboard
+	field.Focused = false
+	win.TypeEvent("abc")
+	update(win, field)
+	assert.Equal(t, "lo ", field.Value())
+}
+
+func TestField_Undo(t *testing.T) {
+	win, field := newFieldWindow(t)
+
+	win.TypeEvent("hello")
+	update(win, field)
+	win.TypeEvent(" world")
+	update(win, field)
+	press(win, field, pixel.KeyBackspace)
+	press(win, field, pixel.KeyBackspace)
+	assert.Equal(t, "hello wor", field.Value())
+
+	// consecutive typing and deleting are undone at once
+	press(win, field, pixel.KeyLeftControl, pixel.KeyZ)
+	assert.Equal(t, "hello world", field.Value())
+	press(win, field, pixel.KeyLeftControl, pixel.KeyZ)
+	assert.Equal(t, "", field.Value())
+
+	press(win, field, pixel.KeyLeftControl, pixel.KeyY)
+	assert.Equal(t, "hello world", field.Value())
+	press(win, field, pixel.KeyLeftControl, pixel.KeyLeftShift, pixel.KeyZ)
+	assert.Equal(t, "hello wor", field.Value())
+
+	// a new edit drops the redo history
+	press(win, field, pixel.KeyLeftControl, pixel.KeyZ)
+	win.TypeEvent("!")
+	update(win, field)
+	press(win, field, pixel.KeyLeftControl, pixel.KeyY)
+	assert.Equal(t, "hello world!", field.Value())
+}
+
+func TestField_Selection(t *testing.T) {
+	win, field := newFieldWindow(t)
+	field.SetValue("foo bar baz")
+
+	press(win, field, pixel.KeyHome)
+	press(win, field, pixel.KeyLeftControl, pixel.KeyRight)
+	assert.Equal(t, 3, field.Caret())
+	press(win, field, pixel.KeyLeftShift, pixel.KeyLeftControl, pixel.KeyRight)
+	start, end := field.Selection()
+	assert.Equal(t, 3, start)
+	assert.Equal(t, 7, end)
+	assert.Equal(t, " bar", field.SelectedText())
+
+	press(win, field, pixel.KeyLeftControl, pixel.KeyC)
+	assert.Equal(t, " bar", win.ClipboardText())
+	press(win, field, pixel.KeyLeftControl, pixel.KeyX)
+	assert.Equal(t, "foo baz", field.Value())
+	press(win, field, pixel.KeyEnd)
+	press(win, field, pixel.KeyLeftControl, pixel.KeyV)
+	assert.Equal(t, "foo baz bar", field.Value())
+
+	// typing replaces the selection, Left collapses it
+	press(win, field, pixel.KeyLeftShift, pixel.KeyLeft)
+	win.TypeEvent("t")
+	update(win, field)
+	assert.Equal(t, "foo baz bat", field.Value())
+	press(win, field, pixel.KeyLeftControl, pixel.KeyA)
+	assert.Equal(t, "foo baz bat", field.SelectedText())
+	press(win, field, pixel.KeyLeft)
+	assert.Equal(t, 0, field.Caret())
+	assert.Equal(t, "", field.SelectedText())
+
+	// pasted newlines become spaces
+	win.SetClipboardText("a\nb")
+	press(win, field, pixel.KeyLeftControl, pixel.KeyV)
+	assert.Equal(t, "a bfoo baz bat", field.Value())
+}
+
+func TestField_Mouse(t *testing.T) {
+	win, field := newFieldWindow(t)
+	field.Focused = false
+	field.SetValue("hello")
+
+	// glyphs of Atlas7x13 are 7 pixels wide
+	win.MouseMoveEvent(pixel.V(10+2*7+2, 12))
+	win.ButtonEvent(pixel.MouseButtonLeft, pixel.Press)
+	update(win, field)
+	assert.True(t, field.Focused)
+	assert.Equal(t, 2, field.Caret())
+
+	win.MouseMoveEvent(pixel.V(10+4*7+5, 12))
+	update(win, field)
+	win.ButtonEvent(pixel.MouseButtonLeft, pixel.Release)
+	update(win, field)
+	assert.Equal(t, "llo", field.SelectedText())
+
+	// an empty part of the field focuses it too
+	win.MouseMoveEvent(pixel.V(180, 12))
+	press(win, field, pixel.MouseButtonLeft)
+	assert.False(t, field.Focused)
+	win.MouseMoveEvent(pixel.V(100, 12))
+	press(win, field, pixel.MouseButtonLeft)
+	assert.True(t, field.Focused)
+	assert.Equal(t, 5, field.Caret())
+
+	// the mouse is transformed by the matrix of the field
+	matrix := pixel.IM.Scaled(pixel.ZV, 2)
+	win.MouseMoveEvent(pixel.V(2*(10+7), 2*12))
+	win.ButtonEvent(pixel.MouseButtonLeft, pixel.Press)
+	win.Update()
+	field.Update(win, matrix)
+	assert.Equal(t, 1, field.Caret())
+}
+
+func TestField_Mask(t *testing.T) {
+	win, field := newFieldWindow(t)
+	field.Mask = '*'
+	field.MaxLength = 6
+	win.SetClipboardText("clipboard")
+
+	win.TypeEvent("secret!!")
+	update(win, field)
+	assert.Equal(t, "secret", field.Value())
+
+	press(win, field, pixel.KeyLeftControl, pixel.KeyA)
+	press(win, field, pixel.KeyLeftControl, pixel.KeyC)
+	press(win, field, pixel.KeyLeftControl, pixel.KeyX)
+	assert.Equal(t, "clipboard", win.ClipboardText())
+	assert.Equal(t, "secret", field.Value())
+
+	press(win, field, pixel.KeyLeftControl, pixel.KeyLeft)
+	assert.Equal(t, 0, field.Caret())
+
+	field.Focused = false
+	c := software.NewCanvas(pixel.R(0, 0, 80, 20))
+	field.Draw(c, pixel.IM.Moved(pixel.V(-8, -5)))
+	assert.Equal(t, render(text.Atlas7x13, "******"), c.Pixels())
+}
+
+func TestField_Draw(t *testing.T) {
+	_, field := newFieldWindow(t)
+	field.SetValue("hi")
+	field.SelectionColor = pixel.RGB(0, 0, 1)
+	field.CaretColor = pixel.RGB(1, 0, 0)
+	field.Select(0, 1)
+
+	c := software.NewCanvas(pixel.R(0, 0, 40, 30))
+	field.Draw(c, pixel.IM)
+	assert.Equal(t, pixel.RGB(0, 0, 1), c.Color(pixel.V(10.5, 20.5)), "selection")
+	assert.Equal(t, pixel.RGB(1, 0, 0), c.Color(pixel.V(17.5, 12.5)), "caret")
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(22.5, 20.5)))
+
+	field.Focused = false
+	c.Clear(pixel.RGBA{})
+	field.Draw(c, pixel.IM)
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(10.5, 20.5)))
+}
+
+func TestField_Shaping(t *testing.T) {
+	field := text.NewField(pixel.V(10, 10), shapingAtlas(t, hebrew, roboto))
+	field.Focused = true
+	field.Color = pixel.RGBA{}
+	field.SelectionColor = pixel.RGB(0, 0, 1)
+	field.CaretColor = pixel.RGB(1, 0, 0)
+	field.SetValue("אבג")
+	right := field.Bounds().Max.X
+	y := 15.5
+
+	// the first rune of right-to-left text is on the right, and so is the caret before it
+	field.Select(0, 1)
+	c := software.NewCanvas(pixel.R(0, 0, 100, 40))
+	field.Draw(c, pixel.IM)
+	assert.Equal(t, pixel.RGB(0, 0, 1), c.Color(pixel.V(right-1, y)), "selection")
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(10.5, y)))
+
+	field.SetCaret(0)
+	c.Clear(pixel.RGBA{})
+	field.Draw(c, pixel.IM)
+	assert.Equal(t, pixel.RGB(1, 0, 0), c.Color(pixel.V(math.Ceil(right-0.5)+0.5, y)), "caret")
+
+	// the runes of a ligature share its advance
+	field.SetValue("ffi")
+	ffi := field.Bounds().Max.X - 10
+	field.Select(0, 1)
+	c.Clear(pixel.RGBA{})
+	field.Draw(c, pixel.IM)
+	assert.Equal(t, pixel.RGB(0, 0, 1), c.Color(pixel.V(10+ffi/3-1, y)), "selection")
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(10+ffi/3+2, y)))
+}
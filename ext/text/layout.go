@@ -0,0 +1,276 @@
+package text
+
+import (
+	"github.com/gopxl/pixel/v2"
+)
+
+// Align is the horizontal alignment of the lines of a Text.
+type Align int
+
+// Here's the list of all available alignments.
+const (
+	// AlignLeft aligns lines to Orig.
+	AlignLeft Align = iota
+
+	// AlignCenter centers lines within MaxWidth, or around Orig if MaxWidth is zero.
+	AlignCenter
+
+	// AlignRight aligns lines to the right edge of MaxWidth, or to Orig if MaxWidth is zero.
+	AlignRight
+
+	// AlignJustify stretches the spaces of wrapped lines, so that they fill MaxWidth. The last line
+	// of a paragraph is aligned to the left.
+	AlignJustify
+)
+
+const softHyphen = '\u00ad'
+
+type itemKind int
+
+const (
+	itemGlyph itemKind = iota
+	itemSpace
+	itemHyphen
+	itemSoftHyphen
+)
+
+// lineItem is a glyph or a whitespace written on the current line of a Text.
+type lineItem struct {
+	kind itemKind
+
+	// triangles of the item in layer.tris
+	layer      *layer
+	start, end int
+
+	// dot before and after the item, moved along with the item
+	x0, x1 float64
+
+	bounds  pixel.Rect
+	visible bool
+
+	// color and advance of the hyphen drawn if a line breaks at a soft hyphen
+	color  pixel.RGBA
+	hyphen float64
+
+	// with a shaping Atlas, the runes of the paragraph the item was shaped from and whether they
+	// are right-to-left
+	runes, count int
+	rtl          bool
+}
+
+// addItem adds an item to the current line and moves it along with the rest of the line.
+func (txt *Text) addItem(it lineItem) {
+	txt.line = append(txt.line, it)
+	txt.moveItem(len(txt.line)-1, pixel.V(txt.lineShift, 0))
+	if it.visible {
+		txt.lineBounds = unionBounds(txt.lineBounds, txt.line[len(txt.line)-1].bounds)
+	}
+}
+
+func (txt *Text) moveItem(i int, d pixel.Vec) {
+	if d == pixel.ZV {
+		return
+	}
+	it := &txt.line[i]
+	for j := it.start; j < it.end; j++ {
+		it.layer.tris[j].Position = it.layer.tris[j].Position.Add(d)
+	}
+	it.x0 += d.X
+	it.x1 += d.X
+	it.bounds = it.bounds.Moved(d)
+	txt.dirty = true
+}
+
+// updateLineBounds recomputes the bounds of the current line after its items were moved.
+func (txt *Text) updateLineBounds() {
+	txt.lineBounds = pixel.Rect{}
+	for _, it := range txt.line {
+		if it.visible {
+			txt.lineBounds = unionBounds(txt.lineBounds, it.bounds)
+		}
+	}
+}
+
+// contentEnd returns the number of items of the current line without the trailing whitespace.
+func (txt *Text) contentEnd() int {
+	end := len(txt.line)
+	for end > 0 && txt.line[end-1].kind == itemSpace {
+		end--
+	}
+	return end
+}
+
+// alignLine moves the items of the current line according to Align. Justified alignment is only
+// applied if justify is true, that is, when a line is finished by wrapping.
+func (txt *Text) alignLine(justify bool) {
+	end := txt.contentEnd()
+	if end == 0 {
+		return
+	}
+	width := txt.line[end-1].x1 - txt.lineShift - txt.Orig.X
+
+	var shift float64
+	switch txt.Align {
+	case AlignCenter:
+		shift = (txt.MaxWidth - width) / 2
+	case AlignRight:
+		shift = txt.MaxWidth - width
+	case AlignJustify:
+		if justify && txt.MaxWidth > width {
+			txt.justifyLine(end, txt.MaxWidth-width)
+			return
+		}
+	}
+
+	if delta := shift - txt.lineShift; delta != 0 {
+		for i := range txt.line {
+			txt.moveItem(i, pixel.V(delta, 0))
+		}
+		txt.lineShift = shift
+		txt.updateLineBounds()
+	}
+}
+
+// justifyLine distributes extra space evenly between the words of the first end items of the
+// current line.
+func (txt *Text) justifyLine(end int, extra float64) {
+	gaps := 0
+	for i := 0; i < end-1; i++ {
+		if txt.line[i].kind == itemSpace && txt.line[i+1].kind != itemSpace {
+			gaps++
+		}
+	}
+	if gaps == 0 {
+		return
+	}
+
+	gap := 0
+	for i := range txt.line {
+		txt.moveItem(i, pixel.V(float64(gap)*extra/float64(gaps), 0))
+		if i < end-1 && txt.line[i].kind == itemSpace && txt.line[i+1].kind != itemSpace {
+			gap++
+		}
+	}
+	txt.updateLineBounds()
+}
+
+// finishLine aligns the current line and starts a new, empty one.
+func (txt *Text) finishLine(justify bool) {
+	txt.alignLine(justify)
+	txt.linesBounds = unionBounds(txt.linesBounds, txt.lineBounds)
+	txt.line = txt.line[:0]
+	txt.lineShift = 0
+	txt.lineBounds = pixel.Rect{}
+}
+
+// newLine reports whether another line may be started. If MaxLines is reached, the text is
+// truncated instead.
+func (txt *Text) newLine(col pixel.RGBA) bool {
+	if txt.MaxLines > 0 && txt.lines >= txt.MaxLines {
+		txt.truncate(col)
+		return false
+	}
+	txt.lines++
+	return true
+}
+
+// wrap breaks the current line, whose last item overflows MaxWidth, at its last break
+// opportunity and moves the items following it to a new line.
+func (txt *Text) wrap(col pixel.RGBA) {
+	limit := txt.Orig.X + txt.lineShift + txt.MaxWidth
+
+	brk := -1
+	content := false
+	for i := 0; i < len(txt.line)-1; i++ {
+		it := txt.line[i]
+		switch {
+		case it.kind == itemSpace && content,
+			it.kind == itemHyphen,
+			it.kind == itemSoftHyphen && content && it.x0+it.hyphen <= limit:
+			brk = i + 1
+		}
+		if it.kind != itemSpace {
+			content = true
+		}
+	}
+	if brk < 0 {
+		// no break opportunity, break the word anywhere
+		brk = len(txt.line) - 1
+		if brk == 0 {
+			return
+		}
+	}
+
+	if !txt.newLine(col) {
+		return
+	}
+
+	rest := append([]lineItem(nil), txt.line[brk:]...)
+	shift := txt.lineShift
+	txt.line = txt.line[:brk]
+	if last := &txt.line[brk-1]; last.kind == itemSoftHyphen {
+		for j := last.start; j < last.end; j++ {
+			last.layer.tris[j].Color = last.color
+		}
+		last.visible = true
+	}
+	txt.updateLineBounds()
+	txt.finishLine(true)
+
+	d := pixel.V(txt.Orig.X-rest[0].x0, -txt.LineHeight)
+	txt.line = rest
+	for i := range txt.line {
+		txt.moveItem(i, d)
+	}
+	txt.updateLineBounds()
+	txt.Dot = txt.Dot.Add(d).Add(pixel.V(shift, 0))
+}
+
+// truncate ends the current line with an ellipsis, removing as many items from its end as needed
+// for the ellipsis to fit, and drops any text written afterwards.
+func (txt *Text) truncate(col pixel.RGBA) {
+	txt.truncated = true
+
+	atlas := txt.StyleAtlas(txt.markup.style())
+	ellipsis := "..."
+	if atlas.Contains('…') {
+		ellipsis = "…"
+	}
+	var width float64
+	for _, r := range ellipsis {
+		width += atlas.Glyph(r).Advance
+	}
+
+	for len(txt.line) > 0 {
+		last := txt.line[len(txt.line)-1]
+		fits := txt.MaxWidth <= 0 || last.x1-txt.lineShift+width <= txt.Orig.X+txt.MaxWidth
+		if (last.kind == itemGlyph || last.kind == itemHyphen) && fits {
+			break
+		}
+		if last.layer != nil {
+			last.layer.tris = last.layer.tris[:last.start]
+		}
+		txt.line = txt.line[:len(txt.line)-1]
+	}
+	txt.updateLineBounds()
+
+	txt.Dot.X = txt.Orig.X
+	if len(txt.line) > 0 {
+		txt.Dot.X = txt.line[len(txt.line)-1].x1 - txt.lineShift
+	}
+	for _, r := range ellipsis {
+		txt.drawRune(r, col)
+	}
+	txt.dirty = true
+}
+
+// unionBounds returns the union of two bounding boxes, ignoring empty ones.
+func unionBounds(a, b pixel.Rect) pixel.Rect {
+	if b.W()*b.H() == 0 {
+		return a
+	}
+	if a.W()*a.H() == 0 {
+		return b
+	}
+	return a.Union(b)
+}
@@ -0,0 +1,90 @@
+package text_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+)
+
+func TestLayout_Wrap(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		dot  pixel.Vec
+	}{
+		{"words", "aaa bbb ccc", pixel.V(21, -26)},
+		{"spaces", "aaa    bbb", pixel.V(21, -13)},
+		{"long word", "abcdefgh", pixel.V(21, -13)},
+		{"hyphen", "ab-cdef", pixel.V(28, -13)},
+		{"soft hyphen", "ab­cdef", pixel.V(28, -13)},
+		{"newline", "aaa\nbbb ccc", pixel.V(21, -26)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txt := text.New(pixel.ZV, text.Atlas7x13)
+			txt.MaxWidth = 35
+			bounds := txt.BoundsOf(tt.s)
+
+			fmt.Fprint(txt, tt.s)
+			assert.Equal(t, tt.dot, txt.Dot)
+			assert.Equal(t, bounds, txt.Bounds())
+			assert.LessOrEqual(t, txt.Bounds().W(), 35.0)
+		})
+	}
+
+	// the soft hyphen is only drawn when breaking the line
+	txt := text.New(pixel.ZV, text.Atlas7x13)
+	fmt.Fprint(txt, "ab­cd")
+	assert.Equal(t, pixel.V(28, 0), txt.Dot)
+}
+
+func TestLayout_Align(t *testing.T) {
+	write := func(align text.Align, maxWidth float64, s string) pixel.Rect {
+		txt := text.New(pixel.ZV, text.Atlas7x13)
+		txt.Align = align
+		txt.MaxWidth = maxWidth
+		fmt.Fprint(txt, s)
+		return txt.Bounds()
+	}
+
+	left := write(text.AlignLeft, 35, "ab")
+	assert.Equal(t, left.Moved(pixel.V(21, 0)), write(text.AlignRight, 35, "ab"))
+	assert.Equal(t, left.Moved(pixel.V(10.5, 0)), write(text.AlignCenter, 35, "ab"))
+	assert.Equal(t, left.Moved(pixel.V(-14, 0)), write(text.AlignRight, 0, "ab"))
+	assert.Equal(t, left.Moved(pixel.V(-7, 0)), write(text.AlignCenter, 0, "ab"))
+
+	// each line is aligned on its own
+	assert.Equal(t, pixel.R(left.Min.X+14, left.Min.Y-13, left.Max.X+21, left.Max.Y),
+		write(text.AlignRight, 35, "ab\nabc"))
+
+	// the spaces of the wrapped line are stretched by 7 each, the last line isn't justified
+	left = write(text.AlignLeft, 49, "a b c dddd")
+	justified := write(text.AlignJustify, 49, "a b c dddd")
+	assert.Equal(t, left.Max.X+14, justified.Max.X)
+	assert.Equal(t, justified, write(text.AlignJustify, 49, "a b c\ndddd e").Union(justified))
+}
+
+func TestLayout_MaxLines(t *testing.T) {
+	txt := text.New(pixel.ZV, text.Atlas7x13)
+	txt.MaxWidth = 35
+	txt.MaxLines = 2
+	fmt.Fprint(txt, "aaa bbb ccc")
+	// "bbb" is shortened to make room for "..."
+	assert.Equal(t, pixel.V(35, -13), txt.Dot)
+
+	fmt.Fprint(txt, "\nmore")
+	assert.Equal(t, pixel.V(35, -13), txt.Dot)
+
+	txt.Clear()
+	fmt.Fprint(txt, "a\nb\nc")
+	assert.Equal(t, pixel.V(28, -13), txt.Dot)
+
+	txt.Clear()
+	txt.MaxWidth = 0
+	fmt.Fprint(txt, "aaa\nbbbbbbbbbb\nc")
+	assert.Equal(t, pixel.V(91, -13), txt.Dot)
+}
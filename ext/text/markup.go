@@ -0,0 +1,235 @@
+package text
+
+import (
+	"bytes"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gopxl/pixel/v2"
+	"golang.org/x/image/colornames"
+)
+
+// Style is a set of font style flags. A Text written with markup switches between the Atlases set
+// with SetStyleAtlas according to the currently open style tags.
+type Style uint8
+
+// Font style flags. They can be combined, e.g. Bold|Italic.
+const (
+	Bold Style = 1 << iota
+	Italic
+
+	// Regular is the style of the Atlas the Text was created with.
+	Regular Style = 0
+)
+
+// maxTagLen is the length of the longest sequence starting with '[' that is still considered a
+// tag. Longer sequences are written as they are.
+const maxTagLen = 32
+
+type tagKind int
+
+const (
+	tagInvalid tagKind = iota
+	tagEscape
+	tagBold
+	tagItalic
+	tagUnderline
+	tagStrike
+	tagColor
+)
+
+type tag struct {
+	kind  tagKind
+	close bool
+	color pixel.RGBA
+}
+
+// scanTag parses a markup tag at the beginning of p, which must start with '['. If p only contains
+// a prefix of a possible tag and more text may follow (atEOF is false), 0 is returned as the size.
+// A sequence which isn't a valid tag is reported as tagInvalid and should be written as text.
+func scanTag(p []byte, atEOF bool) (t tag, size int) {
+	if len(p) >= 2 && p[1] == '[' {
+		return tag{kind: tagEscape}, 2
+	}
+	end := bytes.IndexByte(p, ']')
+	if end < 0 {
+		if atEOF || len(p) >= maxTagLen || bytes.IndexByte(p, '\n') >= 0 {
+			return tag{}, 1
+		}
+		return tag{}, 0
+	}
+	if end >= maxTagLen {
+		return tag{}, 1
+	}
+
+	name := string(p[1:end])
+	if strings.HasPrefix(name, "/") {
+		t.close = true
+		name = name[1:]
+	}
+	switch {
+	case name == "b":
+		t.kind = tagBold
+	case name == "i":
+		t.kind = tagItalic
+	case name == "u":
+		t.kind = tagUnderline
+	case name == "s":
+		t.kind = tagStrike
+	case name == "color" && t.close:
+		t.kind = tagColor
+	case strings.HasPrefix(name, "color=") && !t.close:
+		c, ok := parseColor(name[len("color="):])
+		if !ok {
+			return tag{}, 1
+		}
+		t.kind = tagColor
+		t.color = c
+	default:
+		return tag{}, 1
+	}
+	return t, end + 1
+}
+
+// parseColor parses a color in one of the #rgb, #rgba, #rrggbb and #rrggbbaa forms, or an SVG 1.1
+// color name, such as "red" or "cornflowerblue".
+func parseColor(s string) (pixel.RGBA, bool) {
+	if !strings.HasPrefix(s, "#") {
+		c, ok := colornames.Map[strings.ToLower(s)]
+		if !ok {
+			return pixel.RGBA{}, false
+		}
+		return pixel.ToRGBA(c), true
+	}
+
+	hex := s[1:]
+	if len(hex) == 3 || len(hex) == 4 {
+		var b strings.Builder
+		for _, c := range hex {
+			b.WriteRune(c)
+			b.WriteRune(c)
+		}
+		hex = b.String()
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return pixel.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return pixel.RGBA{}, false
+	}
+	r, g, b, a := float64(v>>24), float64(v>>16&0xff), float64(v>>8&0xff), float64(v&0xff)
+	// pixel.RGBA is alpha-premultiplied
+	return pixel.RGB(r/255, g/255, b/255).Mul(pixel.Alpha(a / 255)), true
+}
+
+// markupState tracks the tags opened so far in a text written with markup.
+type markupState struct {
+	colors    []pixel.RGBA
+	bold      int
+	italic    int
+	underline int
+	strike    int
+}
+
+func (st *markupState) apply(t tag) {
+	var n *int
+	switch t.kind {
+	case tagColor:
+		if !t.close {
+			st.colors = append(st.colors, t.color)
+		} else if len(st.colors) > 0 {
+			st.colors = st.colors[:len(st.colors)-1]
+		}
+		return
+	case tagBold:
+		n = &st.bold
+	case tagItalic:
+		n = &st.italic
+	case tagUnderline:
+		n = &st.underline
+	case tagStrike:
+		n = &st.strike
+	default:
+		return
+	}
+	if !t.close {
+		*n++
+	} else if *n > 0 {
+		*n--
+	}
+}
+
+func (st *markupState) style() Style {
+	var s Style
+	if st.bold > 0 {
+		s |= Bold
+	}
+	if st.italic > 0 {
+		s |= Italic
+	}
+	return s
+}
+
+// color returns the color of the innermost open color tag, or def if there is none.
+func (st *markupState) color(def color.Color) pixel.RGBA {
+	if len(st.colors) > 0 {
+		return st.colors[len(st.colors)-1]
+	}
+	return pixel.ToRGBA(def)
+}
+
+// next decodes the next rune to be drawn from p. If markup is enabled and p starts with a tag, the
+// tag is applied to st and a negative rune is returned. A zero size is returned when p doesn't
+// contain a full rune or tag yet and more text may follow.
+func (st *markupState) next(p []byte, markup, atEOF bool) (r rune, size int) {
+	if markup && len(p) > 0 && p[0] == '[' {
+		t, n := scanTag(p, atEOF)
+		switch {
+		case n == 0:
+			return 0, 0
+		case t.kind == tagEscape:
+			return '[', n
+		case t.kind != tagInvalid:
+			st.apply(t)
+			return -1, n
+		}
+	}
+	if !atEOF && !utf8.FullRune(p) {
+		return 0, 0
+	}
+	return utf8.DecodeRune(p)
+}
+
+// decorations appends the underline and strikethrough quads of the current markup state spanning
+// from x0 to x1 on the line of dot. The quads are drawn with zero intensity, i.e. in solid color.
+func (st *markupState) decorations(tris pixel.TrianglesData, a *Atlas, dot pixel.Vec, x0, x1 float64, col pixel.RGBA) pixel.TrianglesData {
+	thickness := math.Max(1, math.Round(a.LineHeight()/16))
+	if st.underline > 0 {
+		y := dot.Y - math.Max(thickness, math.Round(a.Descent()/2))
+		tris = appendQuad(tris, pixel.R(x0, y, x1, y+thickness), col)
+	}
+	if st.strike > 0 {
+		y := dot.Y + math.Round(a.Ascent()/3)
+		tris = appendQuad(tris, pixel.R(x0, y, x1, y+thickness), col)
+	}
+	return tris
+}
+
+func appendQuad(tris pixel.TrianglesData, r pixel.Rect, col pixel.RGBA) pixel.TrianglesData {
+	v := r.Vertices()
+	n := tris.Len()
+	tris.SetLen(n + 6)
+	for i, j := range [...]int{0, 1, 2, 0, 2, 3} {
+		tris[n+i].Position = v[j]
+		tris[n+i].Color = col
+		tris[n+i].Intensity = 0
+	}
+	return tris
+}
@@ -0,0 +1,97 @@
+package text_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/gobold"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/gopxl/pixel/v2/ext/text"
+)
+
+func TestMarkup_Tags(t *testing.T) {
+	tests := []struct {
+		s   string
+		dot float64
+	}{
+		{"[b]ab[/b]", 14},
+		{"[color=#ff0]warn[/color]", 28},
+		{"[color=red][i]x[/i][/color]", 7},
+		{"[[b]", 21},
+		{"[foo]", 35},
+		{"[color=nope]", 84},
+		// an unterminated tag is only written once it can't be a tag anymore
+		{"a[", 7},
+	}
+	for _, tt := range tests {
+		txt := text.New(pixel.ZV, text.Atlas7x13)
+		txt.Markup = true
+		bounds := txt.BoundsOf(tt.s)
+
+		fmt.Fprint(txt, tt.s)
+		assert.Equal(t, tt.dot, txt.Dot.X, tt.s)
+		txt.WriteString("\n")
+		assert.Equal(t, bounds, txt.Bounds(), tt.s)
+	}
+
+	txt := text.New(pixel.ZV, text.Atlas7x13)
+	fmt.Fprint(txt, "[b]")
+	assert.Equal(t, pixel.V(21, 0), txt.Dot, "markup is disabled by default")
+}
+
+func TestMarkup_SplitWrites(t *testing.T) {
+	txt := text.New(pixel.ZV, text.Atlas7x13)
+	txt.Markup = true
+	txt.WriteString("a[col")
+	assert.Equal(t, pixel.V(7, 0), txt.Dot)
+	txt.WriteString("or=red]b")
+	assert.Equal(t, pixel.V(14, 0), txt.Dot)
+
+	// a trailing '[' is held back until its tag is known, "[[" is drawn right away
+	txt.WriteString("[")
+	assert.Equal(t, pixel.V(14, 0), txt.Dot)
+	txt.WriteString("[")
+	assert.Equal(t, pixel.V(21, 0), txt.Dot)
+}
+
+func TestMarkup_StyleAtlas(t *testing.T) {
+	ttf, err := truetype.Parse(gobold.TTF)
+	require.NoError(t, err)
+	bold := text.NewAtlas(truetype.NewFace(ttf, &truetype.Options{Size: 13}), text.ASCII)
+
+	txt := text.New(pixel.ZV, text.Atlas7x13)
+	txt.SetStyleAtlas(text.Bold, bold)
+	assert.Same(t, bold, txt.StyleAtlas(text.Bold|text.Italic))
+	assert.Same(t, text.Atlas7x13, txt.StyleAtlas(text.Italic))
+
+	txt.Markup = true
+	fmt.Fprint(txt, "a[b]b[i]c[/i][/b]d")
+	assert.Equal(t, pixel.V(7+bold.Glyph('b').Advance+bold.Glyph('c').Advance+7, 0), txt.Dot)
+
+	txt.SetStyleAtlas(text.Bold, nil)
+	assert.Same(t, text.Atlas7x13, txt.StyleAtlas(text.Bold))
+}
+
+func TestMarkup_Draw(t *testing.T) {
+	txt := text.New(pixel.ZV, text.Atlas7x13)
+	txt.Markup = true
+	fmt.Fprint(txt, "[color=#f00][u]  [/u][/color][s] [/s]")
+
+	c := software.NewCanvas(pixel.R(0, 0, 21, 12))
+	txt.Draw(c, pixel.IM.Moved(pixel.V(0, 4)))
+
+	red, white := pixel.RGB(1, 0, 0), pixel.RGB(1, 1, 1)
+	// the underline spans both spaces one pixel below the baseline
+	assert.Equal(t, red, c.Color(pixel.V(0.5, 3.5)))
+	assert.Equal(t, red, c.Color(pixel.V(13.5, 3.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(14.5, 3.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(0.5, 4.5)))
+	// the strikethrough is drawn in the default color
+	assert.Equal(t, white, c.Color(pixel.V(14.5, 8.5)))
+	assert.Equal(t, pixel.RGBA{}, c.Color(pixel.V(13.5, 8.5)))
+}
@@ -0,0 +1,493 @@
+package text
+
+import (
+	"math"
+	"slices"
+
+	"github.com/go-text/typesetting/di"
+	gtfont "github.com/go-text/typesetting/font"
+	"github.com/go-text/typesetting/shaping"
+	"github.com/gopxl/pixel/v2"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/unicode/bidi"
+)
+
+// paraRune is a rune of the paragraph written to a Text with a shaping Atlas, along with the color
+// and the markup it was written with.
+type paraRune struct {
+	r      rune
+	color  pixel.RGBA
+	markup markupState
+}
+
+// paraStart is the state of a Text before the last line of its current paragraph, which is
+// restored whenever the paragraph is laid out again. The lines before it are final, so only the
+// runes from offset on are laid out again.
+type paraStart struct {
+	dot         pixel.Vec
+	lines       int
+	linesBounds pixel.Rect
+	tris        []int
+	offset      int
+}
+
+// shapeBuf adds the runes written to a Text with a shaping Atlas to the current paragraph and lays
+// the paragraph out again. Newlines end paragraphs, carriage returns are ignored.
+func (txt *Text) shapeBuf() {
+	if !txt.truncated && (!txt.paraOpen || txt.Dot != txt.paraEnd) {
+		// the Dot was moved since the paragraph was laid out
+		txt.startParagraph()
+	}
+
+	for {
+		r, size := txt.markup.next(txt.buf, txt.Markup, false)
+		if size == 0 {
+			break
+		}
+		txt.buf = txt.buf[size:]
+		if r < 0 || r == '\r' || txt.truncated {
+			continue
+		}
+
+		if r == '\n' {
+			txt.layoutParagraph(false)
+			if txt.truncated {
+				continue
+			}
+			if txt.MaxLines > 0 && txt.lines >= txt.MaxLines {
+				txt.layoutParagraph(true)
+				continue
+			}
+			txt.finishLine(false)
+			txt.lines++
+			txt.Dot, _ = txt.controlRune(r, txt.Dot)
+			txt.startParagraph()
+			continue
+		}
+
+		st := txt.markup
+		st.colors = nil
+		txt.para = append(txt.para, paraRune{r: r, color: txt.markup.color(txt.Color), markup: st})
+	}
+
+	if !txt.truncated {
+		txt.layoutParagraph(false)
+	}
+}
+
+// startParagraph starts a new, empty paragraph at the Dot.
+func (txt *Text) startParagraph() {
+	if len(txt.line) > 0 {
+		txt.finishLine(false)
+	}
+	txt.para = txt.para[:0]
+	txt.paraOpen = true
+	txt.paraEnd = txt.Dot
+	txt.paraStart = paraStart{
+		dot:         txt.Dot,
+		lines:       txt.lines,
+		linesBounds: txt.linesBounds,
+		tris:        txt.layerLens(txt.paraStart.tris[:0]),
+	}
+}
+
+// layerLens appends the numbers of triangles of the layers to lens.
+func (txt *Text) layerLens(lens []int) []int {
+	for _, l := range txt.layers {
+		lens = append(lens, l.tris.Len())
+	}
+	return lens
+}
+
+// shapingAtlas returns the Atlas used for shaping text in the given style. Style Atlases which
+// aren't shaping Atlases are ignored.
+func (txt *Text) shapingAtlas(style Style) *Atlas {
+	if atlas := txt.StyleAtlas(style); atlas.shaper != nil {
+		return atlas
+	}
+	return txt.atlas
+}
+
+// faceAtlas returns the shaping Atlas of a font face and the index of the face in it.
+func (txt *Text) faceAtlas(face *gtfont.Face) (*Atlas, int) {
+	if i := txt.atlas.shaper.index(face); i >= 0 {
+		return txt.atlas, i
+	}
+	for _, atlas := range txt.styles {
+		if atlas.shaper != nil {
+			if i := atlas.shaper.index(face); i >= 0 {
+				return atlas, i
+			}
+		}
+	}
+	panic("text: shaped with an unknown font")
+}
+
+// layoutParagraph lays out the last line of the current paragraph again, after restoring the state
+// from before the line. The rest of the paragraph is shaped, broken into lines of MaxWidth and each
+// line is reordered into visual order. All lines but the last one are final afterwards, so that
+// writing a long paragraph piece by piece doesn't lay it out from the start every time. If
+// continues is true, more text follows the paragraph and it's the last one that fits in MaxLines,
+// so its last line is ended with an ellipsis.
+func (txt *Text) layoutParagraph(continues bool) {
+	start := &txt.paraStart
+	for i, l := range txt.layers {
+		n := 0
+		if i < len(start.tris) {
+			n = start.tris[i]
+		}
+		l.tris = l.tris[:n]
+	}
+	txt.line = txt.line[:0]
+	txt.lineShift = 0
+	txt.lineBounds = pixel.Rect{}
+	txt.linesBounds = start.linesBounds
+	txt.lines = start.lines
+	txt.truncated = false
+	txt.Dot = start.dot
+	txt.dirty = true
+
+	config := shaping.WrapConfig{Direction: di.DirectionLTR}
+	if paragraphRTL(txt.para) {
+		config.Direction = di.DirectionRTL
+	}
+	if txt.MaxLines > 0 {
+		config.TruncateAfterLines = max(1, txt.MaxLines-start.lines+1)
+		config.TextContinues = continues
+		config.Truncator = txt.shapeEllipsis(config.Direction)
+	}
+
+	text := make([]rune, len(txt.para))
+	for i, pr := range txt.para {
+		text[i] = pr.r
+		if pr.r == '\t' {
+			// shaped as a space as wide as a tab, snapped to the tab stops when placed
+			text[i] = ' '
+		}
+	}
+	runs := txt.shapeParagraph(text, start.offset, config.Direction)
+
+	wrapper := &txt.atlas.shaper.wrapper
+	wrapper.Prepare(config, text[start.offset:], shaping.NewSliceIterator(runs))
+	last := *start
+	for n := 0; ; n++ {
+		width := fixed.Int26_6(math.MaxInt32)
+		if txt.MaxWidth > 0 {
+			// only the first line starts at the Dot
+			x := txt.Dot.X
+			if n > 0 {
+				x = txt.Orig.X
+			}
+			width = f2i(txt.Orig.X + txt.MaxWidth - x)
+		}
+		line, done := wrapper.WrapNextLineF(width)
+		if len(line.Line) == 0 {
+			break
+		}
+		if n > 0 {
+			txt.finishLine(true)
+			txt.lines++
+			txt.Dot = pixel.V(txt.Orig.X, txt.Dot.Y-txt.LineHeight)
+			if offset, ok := lineOffset(line.Line, config); ok {
+				last = paraStart{
+					dot:         txt.Dot,
+					lines:       txt.lines,
+					linesBounds: txt.linesBounds,
+					tris:        txt.layerLens(start.tris[:0]),
+					offset:      start.offset + offset,
+				}
+			}
+		}
+		txt.placeLine(line.Line, config, done)
+		if done {
+			break
+		}
+	}
+
+	txt.alignLine(false)
+	txt.paraEnd = txt.Dot
+	*start = last
+}
+
+// lineOffset returns the index of the first rune of a line. It returns false if the line is just
+// an ellipsis.
+func lineOffset(line shaping.Line, config shaping.WrapConfig) (offset int, ok bool) {
+	offset = math.MaxInt
+	for _, run := range line {
+		if !isEllipsis(run, config) {
+			offset = min(offset, run.Runes.Offset)
+			ok = true
+		}
+	}
+	return offset, ok
+}
+
+// paragraphRTL reports whether the direction of a paragraph is right-to-left, that is, whether its
+// first strong character is right-to-left.
+func paragraphRTL(para []paraRune) bool {
+	for _, pr := range para {
+		p, _ := bidi.LookupRune(pr.r)
+		switch p.Class() {
+		case bidi.L:
+			return false
+		case bidi.R, bidi.AL:
+			return true
+		}
+	}
+	return false
+}
+
+// shapeParagraph shapes the text of the current paragraph from offset on into runs of glyphs in
+// logical order, whose runes are indexed from offset. The text is split into runs by the Atlas of
+// its style, by direction, by script and by font.
+func (txt *Text) shapeParagraph(text []rune, offset int, dir di.Direction) []shaping.Output {
+	var runs []shaping.Output
+	for start := offset; start < len(txt.para); {
+		atlas := txt.shapingAtlas(txt.para[start].markup.style())
+		end := start + 1
+		for end < len(txt.para) && txt.shapingAtlas(txt.para[end].markup.style()) == atlas {
+			end++
+		}
+
+		// the whole paragraph is passed as the context of the runs, for joining across them and
+		// across the start of the last line
+		s := atlas.shaper
+		input := shaping.Input{
+			Text:      text,
+			RunStart:  start,
+			RunEnd:    end,
+			Direction: dir,
+			Size:      s.size,
+		}
+		for _, in := range s.seg.Split(input, s) {
+			run := s.hb.Shape(in)
+			for i, g := range run.Glyphs {
+				if txt.para[g.ClusterIndex].r == '\t' {
+					run.Glyphs[i].Advance = f2i(txt.TabWidth)
+					run.Glyphs[i].XAdvance = run.Glyphs[i].Advance
+				}
+				run.Glyphs[i].ClusterIndex -= offset
+			}
+			run.Runes.Offset -= offset
+			run.RecomputeAdvance()
+			runs = append(runs, run)
+		}
+		start = end
+	}
+	return runs
+}
+
+// shapeEllipsis shapes the ellipsis ending truncated text.
+func (txt *Text) shapeEllipsis(dir di.Direction) shaping.Output {
+	atlas := txt.shapingAtlas(txt.markup.style())
+	ellipsis := []rune("...")
+	if atlas.Contains('…') {
+		ellipsis = []rune("…")
+	}
+	s := atlas.shaper
+	input := shaping.Input{
+		Text:      ellipsis,
+		RunEnd:    len(ellipsis),
+		Direction: dir,
+		Size:      s.size,
+	}
+	return s.hb.Shape(s.seg.Split(input, s)[0])
+}
+
+// isEllipsis reports whether a run is the ellipsis of a config, which the line wrapper copies.
+func isEllipsis(run shaping.Output, config shaping.WrapConfig) bool {
+	glyphs := config.Truncator.Glyphs
+	return len(run.Glyphs) > 0 && len(glyphs) > 0 && &run.Glyphs[0] == &glyphs[0]
+}
+
+// placeLine draws the runs of a line of the current paragraph in visual order, starting at the Dot,
+// and adds their glyph clusters to the current line. The runes of the runs are indexed from the
+// start of the last line laid out before. If the line isn't the last one of the paragraph and
+// ends with a soft hyphen, a hyphen is drawn there.
+func (txt *Text) placeLine(line shaping.Line, config shaping.WrapConfig, last bool) {
+	end := 0
+	for _, run := range line {
+		if !isEllipsis(run, config) {
+			end = max(end, run.Runes.Offset+run.Runes.Count)
+		}
+	}
+
+	para := txt.para[txt.paraStart.offset:]
+	for _, i := range visualOrder(line, config, para) {
+		run := line[i]
+		atlas, font := txt.faceAtlas(run.Face)
+		ellipsis := isEllipsis(run, config)
+		if ellipsis {
+			txt.truncated = true
+		}
+
+		for j := 0; j < len(run.Glyphs); {
+			k := j + 1
+			for k < len(run.Glyphs) && run.Glyphs[k].ClusterIndex == run.Glyphs[j].ClusterIndex {
+				k++
+			}
+			cluster := run.Glyphs[j:k]
+			j = k
+
+			var pr paraRune
+			if ellipsis {
+				pr = paraRune{r: '…', color: txt.markup.color(txt.Color), markup: txt.markup}
+			} else {
+				pr = para[cluster[0].ClusterIndex]
+			}
+			breaks := !last && cluster[0].ClusterIndex+cluster[0].RuneCount == end
+			txt.placeCluster(atlas, font, cluster, pr, breaks)
+			if !ellipsis {
+				it := &txt.line[len(txt.line)-1]
+				it.runes = txt.paraStart.offset + cluster[0].ClusterIndex
+				it.count = cluster[0].RuneCount
+				it.rtl = run.Direction.Progression() == di.TowardTopLeft
+			}
+		}
+	}
+}
+
+// placeCluster draws the glyphs of a cluster, which were shaped from runes starting with pr, at the
+// Dot and adds the cluster to the current line. A soft hyphen is drawn as a hyphen only if the line
+// breaks after it.
+func (txt *Text) placeCluster(atlas *Atlas, font int, cluster []shaping.Glyph, pr paraRune, breaks bool) {
+	l := txt.layerOf(atlas)
+	it := lineItem{
+		kind:    itemGlyph,
+		layer:   l,
+		start:   l.tris.Len(),
+		x0:      txt.Dot.X,
+		visible: true,
+		color:   pr.color,
+	}
+	dot := txt.Dot
+
+	switch pr.r {
+	case ' ':
+		it.kind = itemSpace
+		it.visible = false
+	case '\t':
+		it.kind = itemSpace
+		it.visible = false
+		txt.Dot, _ = txt.controlRune('\t', txt.Dot)
+		it.end = it.start
+		it.x1 = txt.Dot.X
+		txt.addItem(it)
+		return
+	case softHyphen:
+		it.kind = itemSoftHyphen
+		it.visible = false
+		if breaks {
+			rect, frame, bounds, newDot := atlas.DrawRune(-1, '-', dot)
+			txt.appendGlyph(l, rect, frame, pr.color)
+			it.kind = itemHyphen
+			it.visible = true
+			it.bounds = bounds
+			dot = newDot
+		}
+		it.end = l.tris.Len()
+		it.x1 = dot.X
+		txt.Dot = dot
+		txt.addItem(it)
+		return
+	}
+
+	for _, g := range cluster {
+		if g.GlyphID != gtfont.EmptyGlyph {
+			pos := dot.Add(pixel.V(i2f(g.XOffset), i2f(g.YOffset)))
+			key := glyphKey{font: font + 1, gid: g.GlyphID}
+			if rect, frame, bounds, ok := atlas.drawGlyph(key, pos); ok {
+				txt.appendGlyph(l, rect, frame, pr.color)
+				if it.kind == itemGlyph {
+					it.bounds = unionBounds(it.bounds, bounds)
+				}
+			}
+		}
+		dot.X += i2f(g.Advance)
+	}
+	l.tris = pr.markup.decorations(l.tris, atlas, dot, it.x0, dot.X, pr.color)
+
+	it.end = l.tris.Len()
+	it.x1 = dot.X
+	txt.Dot = dot
+	txt.addItem(it)
+}
+
+// runeEdges returns the leading and trailing x coordinates of the first n runes of the current
+// paragraph, which must be laid out on the current line, in logical order. The runes of a glyph
+// cluster, such as a ligature, share its advance evenly.
+func (txt *Text) runeEdges(n int, edges [][2]float64) [][2]float64 {
+	edges = edges[:0]
+	for i := 0; i < n; i++ {
+		edges = append(edges, [2]float64{txt.Dot.X, txt.Dot.X})
+	}
+	for _, it := range txt.line {
+		w := (it.x1 - it.x0) / float64(max(it.count, 1))
+		for j := 0; j < it.count && it.runes+j < n; j++ {
+			x0, x1 := it.x0+float64(j)*w, it.x0+float64(j+1)*w
+			if it.rtl {
+				x0, x1 = it.x1-float64(j)*w, it.x1-float64(j+1)*w
+			}
+			edges[it.runes+j] = [2]float64{x0, x1}
+		}
+	}
+	return edges
+}
+
+// visualOrder returns the indices of the runs of a line in visual order, following the Unicode
+// Bidirectional Algorithm. The embedding levels of the runs are derived from their directions by
+// the implicit rules: right-to-left runs are at level 1 and left-to-right runs at level 2, except
+// in a left-to-right paragraph, where left-to-right runs are at level 0, unless they're numbers
+// following right-to-left text. The ellipsis is at the level of the paragraph.
+func visualOrder(line shaping.Line, config shaping.WrapConfig, para []paraRune) []int {
+	rtl := config.Direction.Progression() == di.TowardTopLeft
+	order := make([]int, len(line))
+	levels := make([]int, len(line))
+	for i, run := range line {
+		order[i] = i
+		switch {
+		case isEllipsis(run, config):
+			if rtl {
+				levels[i] = 1
+			}
+		case run.Direction.Progression() == di.TowardTopLeft:
+			levels[i] = 1
+		case rtl:
+			levels[i] = 2
+		case i > 0 && levels[i-1] > 0 && isNumber(para[run.Runes.Offset:][:run.Runes.Count]):
+			levels[i] = 2
+		}
+	}
+
+	// reverse the runs at each level or higher, starting from the highest level
+	for level := slices.Max(append(levels, 0)); level > 0; level-- {
+		for i := 0; i < len(order); {
+			if levels[order[i]] < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(order) && levels[order[j]] >= level {
+				j++
+			}
+			slices.Reverse(order[i:j])
+			i = j
+		}
+	}
+	return order
+}
+
+// isNumber reports whether runes contain numbers, but no strong left-to-right characters.
+func isNumber(runes []paraRune) bool {
+	number := false
+	for _, pr := range runes {
+		p, _ := bidi.LookupRune(pr.r)
+		switch p.Class() {
+		case bidi.L:
+			return false
+		case bidi.EN, bidi.AN:
+			number = true
+		}
+	}
+	return number
+}
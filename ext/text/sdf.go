@@ -0,0 +1,289 @@
+package text
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/gopxl/pixel/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// NewSDFAtlas creates a new Atlas like NewAtlas, but instead of the glyphs themselves, its Picture
+// contains their signed distance fields. The alpha channel of the Picture holds the distance to the
+// outline of the glyph mapped to [0, 1], where 0.5 lies on the outline and spread pixels inside and
+// outside of the glyph map to 1 and 0 respectively.
+//
+// Text using an SDF Atlas is drawn with SDFFragmentShader, which keeps the glyphs crisp when the
+// Text is scaled up. The face should be rasterized reasonably large, e.g. at 48px, and the Text
+// scaled down by a Matrix to the desired size. The spread limits the width of the outline and the
+// offset of the shadow drawn by the shader. It must be positive.
+func NewSDFAtlas(face font.Face, spread int, runeSets ...[]rune) *Atlas {
+	if spread <= 0 {
+		panic("text.NewSDFAtlas: spread must be positive")
+	}
+	runes := collectRunes(runeSets)
+
+	pad := fixed.I(spread)
+	// besides the fields, leave room for shadows sampled up to spread pixels away from them
+	fixedMapping, fixedBounds := makeSquareMapping(face, runes, fixed.I(2)+3*pad)
+	fixedBounds.Min = fixedBounds.Min.Sub(fixed.Point26_6{X: pad, Y: pad})
+	fixedBounds.Max = fixedBounds.Max.Add(fixed.Point26_6{X: pad, Y: pad})
+
+	atlasImg := image.NewRGBA(image.Rect(
+		fixedBounds.Min.X.Floor(),
+		fixedBounds.Min.Y.Floor(),
+		fixedBounds.Max.X.Ceil(),
+		fixedBounds.Max.Y.Ceil(),
+	))
+
+	for r, fg := range fixedMapping {
+		dr, mask, maskp, _, ok := face.Glyph(fg.dot, r)
+		if !ok || dr.Empty() {
+			continue
+		}
+
+		glyph := image.NewAlpha(dr.Inset(-spread))
+		draw.Draw(glyph, dr, mask, maskp, draw.Src)
+		draw.Draw(atlasImg, glyph.Rect, distanceField(glyph, spread), glyph.Rect.Min, draw.Src)
+
+		fg.frame.Min = fg.frame.Min.Sub(fixed.Point26_6{X: pad, Y: pad})
+		fg.frame.Max = fg.frame.Max.Add(fixed.Point26_6{X: pad, Y: pad})
+		fixedMapping[r] = fg
+	}
+
+	atlas := newAtlas(face, atlasImg, fixedMapping, fixedBounds)
+	atlas.spread = float64(spread)
+	return atlas
+}
+
+// Spread returns the distance in pixels covered by the signed distance fields of an Atlas created
+// with NewSDFAtlas, or zero for a regular Atlas.
+func (a *Atlas) Spread() float64 {
+	return a.spread
+}
+
+// distanceField computes the signed distance field of a glyph mask, with pixels at least half
+// covered considered inside of the glyph.
+func distanceField(mask *image.Alpha, spread int) *image.Alpha {
+	w, h := mask.Rect.Dx(), mask.Rect.Dy()
+	toInside := make([]float64, w*h)
+	toOutside := make([]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			if mask.Pix[y*mask.Stride+x] >= 0x80 {
+				toOutside[i] = math.Inf(1)
+			} else {
+				toInside[i] = math.Inf(1)
+			}
+		}
+	}
+	distanceTransform(toInside, w, h)
+	distanceTransform(toOutside, w, h)
+
+	sdf := image.NewAlpha(mask.Rect)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*w + x
+			// the outline lies halfway between the centers of an inside and an outside pixel
+			d := -(math.Sqrt(toInside[i]) - 0.5)
+			if toInside[i] == 0 {
+				d = math.Sqrt(toOutside[i]) - 0.5
+			}
+			v := 0.5 + d/float64(2*spread)
+			sdf.Pix[y*sdf.Stride+x] = uint8(math.Round(255 * pixel.Clamp(v, 0, 1)))
+		}
+	}
+	return sdf
+}
+
+// distanceTransform replaces each value of the w×h grid f, which is zero for feature pixels and
+// infinity elsewhere, with the squared euclidean distance to the nearest feature pixel.
+func distanceTransform(f []float64, w, h int) {
+	n := max(w, h)
+	line := make([]float64, n)
+	d := make([]float64, n)
+	v := make([]int, n)
+	z := make([]float64, n+1)
+
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			line[y] = f[y*w+x]
+		}
+		distanceTransform1D(line[:h], d, v, z)
+		for y := 0; y < h; y++ {
+			f[y*w+x] = d[y]
+		}
+	}
+	for y := 0; y < h; y++ {
+		distanceTransform1D(f[y*w:(y+1)*w], d, v, z)
+		copy(f[y*w:(y+1)*w], d)
+	}
+}
+
+// distanceTransform1D computes the squared distance transform of f into d using the lower envelope
+// of parabolas, as described by Felzenszwalb and Huttenlocher. The slices v and z are scratch
+// space of at least len(f) and len(f)+1 elements.
+func distanceTransform1D(f, d []float64, v []int, z []float64) {
+	k := -1
+	for q := range f {
+		if math.IsInf(f[q], 1) {
+			continue
+		}
+		for k >= 0 {
+			s := ((f[q] + float64(q*q)) - (f[v[k]] + float64(v[k]*v[k]))) / float64(2*q-2*v[k])
+			if s > z[k] {
+				k++
+				v[k] = q
+				z[k] = s
+				z[k+1] = math.Inf(1)
+				break
+			}
+			k--
+		}
+		if k < 0 {
+			k = 0
+			v[0] = q
+			z[0] = math.Inf(-1)
+			z[1] = math.Inf(1)
+		}
+	}
+
+	if k < 0 {
+		for q := range f {
+			d[q] = math.Inf(1)
+		}
+		return
+	}
+	k = 0
+	for q := range f {
+		for z[k+1] < float64(q) {
+			k++
+		}
+		dq := float64(q - v[k])
+		d[q] = dq*dq + f[v[k]]
+	}
+}
+
+// SDFStyle holds the effects applied by SDFFragmentShader. Distances are in the pixels of the
+// Atlas's Picture and may not exceed its Spread.
+type SDFStyle struct {
+	// OutlineWidth is the width of the outline drawn around the glyphs in OutlineColor.
+	OutlineWidth float64
+	OutlineColor color.Color
+
+	// ShadowOffset is the offset of the shadow drawn under the glyphs in ShadowColor. The shadow
+	// includes the outline. ShadowSoftness blurs the edges of the shadow.
+	ShadowOffset   pixel.Vec
+	ShadowColor    color.Color
+	ShadowSoftness float64
+}
+
+// SDFUniformSetter is implemented by targets with custom shaders, such as opengl.Canvas.
+type SDFUniformSetter interface {
+	SetUniform(name string, value interface{})
+}
+
+// SDFShaderTarget is implemented by targets with custom fragment shaders, such as opengl.Canvas.
+type SDFShaderTarget interface {
+	SDFUniformSetter
+	SetFragmentShader(src string)
+}
+
+// SetShader sets SDFFragmentShader on the target, with its uniforms set by Apply first.
+//
+// A target declares the uniforms of a shader when the shader is set, so they need to exist by
+// then: setting a new uniform afterwards, as by calling Apply only after SetFragmentShader, makes
+// opengl.Canvas panic when drawing. Once the shader is set, Apply changes the style.
+func (s SDFStyle) SetShader(c SDFShaderTarget, atlas *Atlas) {
+	s.Apply(c, atlas)
+	c.SetFragmentShader(SDFFragmentShader)
+}
+
+// Apply sets the uniforms of SDFFragmentShader to draw text made with the given SDF Atlas. The
+// first time, it needs to be called before the shader is set on the target, see SetShader. It
+// panics if the Atlas wasn't created by NewSDFAtlas.
+func (s SDFStyle) Apply(c SDFUniformSetter, atlas *Atlas) {
+	if atlas.Spread() == 0 {
+		panic("text.SDFStyle.Apply: not an SDF Atlas")
+	}
+	scale := 1 / (2 * atlas.Spread())
+	c.SetUniform("uOutlineWidth", float32(s.OutlineWidth*scale))
+	c.SetUniform("uOutlineColor", rgbaToVec4(s.OutlineColor))
+	c.SetUniform("uShadowOffset", mgl32.Vec2{float32(s.ShadowOffset.X), float32(s.ShadowOffset.Y)})
+	c.SetUniform("uShadowColor", rgbaToVec4(s.ShadowColor))
+	c.SetUniform("uShadowSoftness", float32(s.ShadowSoftness*scale))
+}
+
+func rgbaToVec4(c color.Color) mgl32.Vec4 {
+	if c == nil {
+		return mgl32.Vec4{}
+	}
+	rgba := pixel.ToRGBA(c)
+	return mgl32.Vec4{float32(rgba.R), float32(rgba.G), float32(rgba.B), float32(rgba.A)}
+}
+
+// SDFFragmentShader is a fragment shader for drawing text made with an SDF Atlas created by
+// NewSDFAtlas. Set it on a canvas together with the outline and shadow by SDFStyle.SetShader, and
+// enable smoothing with SetSmooth(true):
+//
+//	text.SDFStyle{OutlineWidth: 2, OutlineColor: colornames.Black}.SetShader(canvas, atlas)
+//	canvas.SetSmooth(true)
+//
+// The style can be changed later by SDFStyle.Apply:
+//
+//	text.SDFStyle{OutlineWidth: 1, OutlineColor: colornames.Red}.Apply(canvas, atlas)
+//
+// Triangles with zero intensity, such as underlines, are drawn in their color like with the default
+// shader.
+var SDFFragmentShader = `
+#version 330 core
+
+in vec4  vColor;
+in vec2  vTexCoords;
+in float vIntensity;
+in vec4  vClipRect;
+
+out vec4 fragColor;
+
+uniform vec4 uColorMask;
+uniform vec4 uTexBounds;
+uniform sampler2D uTexture;
+
+uniform float uOutlineWidth;
+uniform vec4  uOutlineColor;
+uniform vec2  uShadowOffset;
+uniform vec4  uShadowColor;
+uniform float uShadowSoftness;
+
+float coverage(float dist, float width) {
+	return smoothstep(0.5 - width, 0.5 + width, dist);
+}
+
+void main() {
+	if ((vClipRect != vec4(0,0,0,0)) && (gl_FragCoord.x < vClipRect.x || gl_FragCoord.y < vClipRect.y || gl_FragCoord.x > vClipRect.z || gl_FragCoord.y > vClipRect.w))
+		discard;
+
+	if (vIntensity == 0) {
+		fragColor = uColorMask * vColor;
+		return;
+	}
+
+	vec2 t = (vTexCoords - uTexBounds.xy) / uTexBounds.zw;
+	float dist = texture(uTexture, t).a;
+	float width = max(fwidth(dist) * 0.5, 1e-4);
+
+	vec4 color = vColor * coverage(dist, width);
+	color += (1 - color.a) * uOutlineColor * coverage(dist + uOutlineWidth, width);
+
+	vec2 st = (vTexCoords - uShadowOffset - uTexBounds.xy) / uTexBounds.zw;
+	float shadow = texture(uTexture, st).a + uOutlineWidth;
+	color += (1 - color.a) * uShadowColor * coverage(shadow, width + uShadowSoftness);
+
+	fragColor = uColorMask * color;
+}
+`
@@ -0,0 +1,122 @@
+package text
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font/basicfont"
+
+	"github.com/gopxl/pixel/v2"
+)
+
+func TestDistanceField(t *testing.T) {
+	mask := image.NewAlpha(image.Rect(0, 0, 12, 12))
+	for y := 4; y < 8; y++ {
+		for x := 4; x < 8; x++ {
+			mask.SetAlpha(x, y, color.Alpha{A: 0xff})
+		}
+	}
+
+	sdf := distanceField(mask, 4)
+	assert.Equal(t, uint8(143), sdf.AlphaAt(4, 5).A, "inside next to the outline")
+	assert.Equal(t, uint8(112), sdf.AlphaAt(3, 5).A, "outside next to the outline")
+	assert.Equal(t, uint8(175), sdf.AlphaAt(5, 5).A, "inside, 1.5px from the outline")
+	assert.Equal(t, uint8(0), sdf.AlphaAt(0, 0).A, "further than spread")
+	// diagonal distance to the corner of the square
+	assert.Equal(t, uint8(53), sdf.AlphaAt(2, 2).A)
+}
+
+func TestNewSDFAtlas(t *testing.T) {
+	regular := NewAtlas(basicfont.Face7x13, ASCII)
+	sdf := NewSDFAtlas(basicfont.Face7x13, 4, ASCII)
+
+	assert.Equal(t, 4.0, sdf.Spread())
+	assert.Equal(t, 0.0, regular.Spread())
+	assert.Equal(t, regular.Glyph('A').Frame.W()+8, sdf.Glyph('A').Frame.W())
+	assert.Equal(t, regular.Glyph('A').Advance, sdf.Glyph('A').Advance)
+
+	// the drawn quad covers the field, but the bounds are the same
+	rect, _, bounds, dot := sdf.DrawRune(-1, 'A', pixel.ZV)
+	regRect, _, regBounds, regDot := regular.DrawRune(-1, 'A', pixel.ZV)
+	assert.Equal(t, pixel.R(regRect.Min.X-4, regRect.Min.Y-4, regRect.Max.X+4, regRect.Max.Y+4), rect)
+	assert.Equal(t, regBounds, bounds)
+	assert.Equal(t, regDot, dot)
+
+	// the middle of the stem of 'l' is inside of the glyph
+	pic := sdf.Picture().(*pixel.PictureData)
+	frame := sdf.Glyph('l').Frame
+	assert.Greater(t, pic.Color(frame.Center()).A, 0.5)
+	assert.Equal(t, 0.0, pic.Color(frame.Min.Add(pixel.V(0.5, 0.5))).A)
+}
+
+type uniforms map[string]interface{}
+
+func (u uniforms) SetUniform(name string, value interface{}) {
+	u[name] = value
+}
+
+func TestSDFStyle_Apply(t *testing.T) {
+	atlas := NewSDFAtlas(basicfont.Face7x13, 4, ASCII)
+	u := uniforms{}
+	SDFStyle{
+		OutlineWidth: 2,
+		OutlineColor: pixel.RGB(1, 0, 0),
+		ShadowOffset: pixel.V(1, -1),
+	}.Apply(u, atlas)
+
+	assert.Equal(t, uniforms{
+		"uOutlineWidth":   float32(0.25),
+		"uOutlineColor":   mgl32.Vec4{1, 0, 0, 1},
+		"uShadowOffset":   mgl32.Vec2{1, -1},
+		"uShadowColor":    mgl32.Vec4{},
+		"uShadowSoftness": float32(0),
+	}, u)
+
+	// the sizes are relative to the spread, which a regular Atlas doesn't have
+	assert.Panics(t, func() { SDFStyle{}.Apply(u, Atlas7x13) })
+	assert.Panics(t, func() { SDFStyle{}.SetShader(&shaderTarget{uniforms: uniforms{}}, Atlas7x13) })
+	assert.Panics(t, func() { NewSDFAtlas(basicfont.Face7x13, 0, ASCII) })
+}
+
+// shaderTarget declares the uniforms of a shader when it's set, like opengl.Canvas, which panics
+// when drawing with uniforms set afterwards.
+type shaderTarget struct {
+	uniforms
+	declared map[string]bool
+}
+
+func (st *shaderTarget) SetUniform(name string, value interface{}) {
+	if st.declared != nil && !st.declared[name] {
+		panic("undeclared uniform " + name)
+	}
+	st.uniforms.SetUniform(name, value)
+}
+
+func (st *shaderTarget) SetFragmentShader(src string) {
+	st.declared = make(map[string]bool)
+	for name := range st.uniforms {
+		st.declared[name] = true
+	}
+}
+
+func TestSDFStyle_SetShader(t *testing.T) {
+	atlas := NewSDFAtlas(basicfont.Face7x13, 4, ASCII)
+	st := &shaderTarget{uniforms: uniforms{}}
+
+	// the order of the documentation: SetShader, then Apply to change the style
+	assert.NotPanics(t, func() {
+		SDFStyle{OutlineWidth: 2, OutlineColor: pixel.RGB(1, 0, 0)}.SetShader(st, atlas)
+		SDFStyle{OutlineWidth: 1, ShadowColor: pixel.RGB(0, 0, 1)}.Apply(st, atlas)
+	})
+	assert.Len(t, st.declared, 5)
+	assert.Equal(t, float32(0.125), st.uniforms["uOutlineWidth"])
+	assert.Equal(t, mgl32.Vec4{0, 0, 1, 1}, st.uniforms["uShadowColor"])
+
+	// applying the style only after setting the shader leaves the uniforms undeclared
+	st = &shaderTarget{uniforms: uniforms{}}
+	st.SetFragmentShader(SDFFragmentShader)
+	assert.Panics(t, func() { SDFStyle{}.Apply(st, atlas) })
+}
@@ -0,0 +1,244 @@
+package text
+
+import (
+	"bytes"
+	"image"
+	"math"
+	"unicode"
+
+	gtfont "github.com/go-text/typesetting/font"
+	ot "github.com/go-text/typesetting/font/opentype"
+	"github.com/go-text/typesetting/shaping"
+	"github.com/pkg/errors"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// NewShapingAtlas creates a dynamic Atlas (see NewDynamicAtlas) of OpenType or TrueType fonts
+// rendered at size pixels per em, rounded up to whole pixels. A Text using it shapes the written
+// text with the HarfBuzz port of github.com/go-text/typesetting and lays out the resulting glyph
+// runs, instead of placing one glyph per rune. This takes care of ligatures, kerning, the
+// contextual forms of Arabic, the reordering and positioning of marks in Devanagari or Hebrew, and
+// of bidirectional text, whose lines are reordered from logical to visual order after wrapping:
+//
+//	atlas, err := text.NewShapingAtlas(16, 1024, latinTTF, arabicTTF, devanagariTTF)
+//	if err != nil {
+//	    panic(err)
+//	}
+//	txt := text.New(orig, atlas)
+//	txt.MaxWidth = 300
+//	fmt.Fprint(txt, "Hello, مرحبا, नमस्ते!")
+//
+// Runes are taken from the first font with a glyph for them, like in a FallbackFace, and the
+// vertical metrics are those of the first font. Glyphs are rasterized the first time they're drawn
+// and evicted as described for NewDynamicAtlas. Drawing single runes, e.g. with Atlas.DrawRune,
+// takes their glyphs from the fonts without shaping.
+//
+// At least one font is required. An error is returned if any of the fonts can't be parsed.
+func NewShapingAtlas(size float64, maxSize int, fonts ...[]byte) (*Atlas, error) {
+	if len(fonts) == 0 {
+		panic("text.NewShapingAtlas: no fonts")
+	}
+	// the shaper scales fonts to whole pixels, so the glyphs are rasterized at the same size
+	s := &shaper{size: fixed.I(int(math.Ceil(size)))}
+	for i, b := range fonts {
+		face, err := gtfont.ParseTTF(bytes.NewReader(b))
+		if err != nil {
+			return nil, errors.Wrapf(err, "font %d", i)
+		}
+		s.faces = append(s.faces, face)
+	}
+	a := NewDynamicAtlas(shapingFace{s}, maxSize)
+	a.shaper = s
+	a.shaped = make(map[glyphKey]Glyph)
+	return a, nil
+}
+
+// shaper holds the fonts of a shaping Atlas, along with the state reused for shaping and wrapping
+// text written with them.
+type shaper struct {
+	faces []*gtfont.Face
+	size  fixed.Int26_6
+
+	hb      shaping.HarfbuzzShaper
+	seg     shaping.Segmenter
+	wrapper shaping.LineWrapper
+}
+
+// ResolveFace implements shaping.Fontmap. It returns the first font with a glyph for r.
+func (s *shaper) ResolveFace(r rune) *gtfont.Face {
+	i, _, _ := s.nominal(r)
+	return s.faces[i]
+}
+
+// nominal returns the index of the first font with a glyph for r and the glyph itself. If there's
+// no such font, the first one is returned with ok set to false.
+func (s *shaper) nominal(r rune) (i int, gid gtfont.GID, ok bool) {
+	for i, face := range s.faces {
+		if gid, ok := face.NominalGlyph(r); ok {
+			return i, gid, true
+		}
+	}
+	return 0, 0, false
+}
+
+// index returns the index of the font face, or -1 if it isn't one of the fonts.
+func (s *shaper) index(face *gtfont.Face) int {
+	for i, f := range s.faces {
+		if f == face {
+			return i
+		}
+	}
+	return -1
+}
+
+// scale converts font units of the font i to pixels.
+func (s *shaper) scale(i int) float64 {
+	return i2f(s.size) / float64(s.faces[i].Upem())
+}
+
+// glyphBounds returns the bounds and the advance of a glyph of the font i, with the y axis pointing
+// down, like font.Face.GlyphBounds.
+func (s *shaper) glyphBounds(i int, gid gtfont.GID) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	face, scale := s.faces[i], s.scale(i)
+	ext, ok := face.GlyphExtents(gid)
+	if !ok {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+	bounds = fixed.Rectangle26_6{
+		Min: fixed.Point26_6{
+			X: f2i(float64(ext.XBearing) * scale),
+			Y: f2i(-float64(ext.YBearing) * scale),
+		},
+		Max: fixed.Point26_6{
+			X: f2i(float64(ext.XBearing+ext.Width) * scale),
+			Y: f2i(-float64(ext.YBearing+ext.Height) * scale),
+		},
+	}
+	return bounds, f2i(float64(face.HorizontalAdvance(gid)) * scale), true
+}
+
+// glyphMask rasterizes the outline of a glyph of the font i, like font.Face.Glyph at a zero dot.
+// Glyphs without an outline, such as those of bitmap fonts, are reported as missing.
+func (s *shaper) glyphMask(i int, gid gtfont.GID) (dr image.Rectangle, mask image.Image, maskp image.Point, ok bool) {
+	b, _, ok := s.glyphBounds(i, gid)
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, false
+	}
+	var outline gtfont.GlyphOutline
+	switch data := s.faces[i].GlyphData(gid).(type) {
+	case gtfont.GlyphOutline:
+		outline = data
+	case gtfont.GlyphSVG:
+		outline = data.Outline
+	default:
+		return image.Rectangle{}, nil, image.Point{}, false
+	}
+
+	dr = image.Rect(b.Min.X.Floor(), b.Min.Y.Floor(), b.Max.X.Ceil(), b.Max.Y.Ceil())
+	scale := float32(s.scale(i))
+	// the outline is y-up in font units, the mask y-down in pixels
+	pt := func(p gtfont.SegmentPoint) (x, y float32) {
+		return p.X*scale - float32(dr.Min.X), -p.Y*scale - float32(dr.Min.Y)
+	}
+
+	var z vector.Rasterizer
+	z.Reset(dr.Dx(), dr.Dy())
+	for j, seg := range outline.Segments {
+		switch seg.Op {
+		case ot.SegmentOpMoveTo:
+			if j > 0 {
+				z.ClosePath()
+			}
+			z.MoveTo(pt(seg.Args[0]))
+		case ot.SegmentOpLineTo:
+			z.LineTo(pt(seg.Args[0]))
+		case ot.SegmentOpQuadTo:
+			bx, by := pt(seg.Args[0])
+			cx, cy := pt(seg.Args[1])
+			z.QuadTo(bx, by, cx, cy)
+		case ot.SegmentOpCubeTo:
+			bx, by := pt(seg.Args[0])
+			cx, cy := pt(seg.Args[1])
+			dx, dy := pt(seg.Args[2])
+			z.CubeTo(bx, by, cx, cy, dx, dy)
+		}
+	}
+	if len(outline.Segments) > 0 {
+		z.ClosePath()
+	}
+
+	alpha := image.NewAlpha(image.Rect(0, 0, dr.Dx(), dr.Dy()))
+	z.Draw(alpha, alpha.Bounds(), image.Opaque, image.Point{})
+	return dr, alpha, image.Point{}, true
+}
+
+// shapingFace is the font.Face of a shaping Atlas. It maps runes to the glyphs of the fonts without
+// shaping, so that the rest of the Atlas works as usual. Runes missing from all the fonts, except
+// for unicode.ReplacementChar, which is drawn as the missing glyph of the first font, are reported
+// as missing.
+type shapingFace struct {
+	s *shaper
+}
+
+func (f shapingFace) glyph(r rune) (i int, gid gtfont.GID, ok bool) {
+	i, gid, ok = f.s.nominal(r)
+	if !ok && r == unicode.ReplacementChar {
+		return 0, 0, true
+	}
+	return i, gid, ok
+}
+
+func (f shapingFace) Close() error {
+	return nil
+}
+
+func (f shapingFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	i, gid, ok := f.glyph(r)
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	_, advance, _ = f.s.glyphBounds(i, gid)
+	dr, mask, maskp, ok = f.s.glyphMask(i, gid)
+	if !ok {
+		// a glyph without an outline, such as a space
+		return image.Rectangle{}, image.NewAlpha(image.Rectangle{}), image.Point{}, advance, true
+	}
+	return dr.Add(image.Pt(dot.X.Round(), dot.Y.Round())), mask, maskp, advance, true
+}
+
+func (f shapingFace) GlyphBounds(r rune) (bounds fixed.Rectangle26_6, advance fixed.Int26_6, ok bool) {
+	i, gid, ok := f.glyph(r)
+	if !ok {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+	return f.s.glyphBounds(i, gid)
+}
+
+func (f shapingFace) GlyphAdvance(r rune) (advance fixed.Int26_6, ok bool) {
+	_, advance, ok = f.GlyphBounds(r)
+	return advance, ok
+}
+
+// Kern returns zero, the kerning of shaped text is applied by shaping.
+func (f shapingFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	return 0
+}
+
+func (f shapingFace) Metrics() font.Metrics {
+	face, scale := f.s.faces[0], f.s.scale(0)
+	ext, _ := face.FontHExtents()
+	return font.Metrics{
+		Height:     f2i(float64(ext.Ascender-ext.Descender+ext.LineGap) * scale),
+		Ascent:     f2i(float64(ext.Ascender) * scale),
+		Descent:    f2i(-float64(ext.Descender) * scale),
+		XHeight:    f2i(float64(face.LineMetric(gtfont.XHeight)) * scale),
+		CapHeight:  f2i(float64(face.LineMetric(gtfont.CapHeight)) * scale),
+		CaretSlope: image.Point{X: 0, Y: 1},
+	}
+}
+
+func f2i(f float64) fixed.Int26_6 {
+	return fixed.Int26_6(math.Round(f * (1 << 6)))
+}
@@ -0,0 +1,329 @@
+package text_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	hbdata "github.com/go-text/typesetting-utils/harfbuzz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/text"
+)
+
+const (
+	roboto     = "perf_reference/fonts/Roboto-Regular.ttf"
+	amiri      = "perf_reference/fonts/Amiri-Regular.ttf"
+	devanagari = "perf_reference/fonts/NotoSansDevanagari-Regular.ttf"
+	// a font with Hebrew letters only
+	hebrew = "harfbuzz_reference/in-house/fonts/b895f8ff06493cc893ec44de380690ca0074edfa.ttf"
+)
+
+func shapingAtlas(t *testing.T, fonts ...string) *text.Atlas {
+	var ttfs [][]byte
+	for _, name := range fonts {
+		ttf, err := hbdata.Files.ReadFile(name)
+		require.NoError(t, err)
+		ttfs = append(ttfs, ttf)
+	}
+	atlas, err := text.NewShapingAtlas(16, 1024, ttfs...)
+	require.NoError(t, err)
+	return atlas
+}
+
+// glyphQuad is a glyph drawn by a Text: its rectangle and its frame in the Picture of the Atlas.
+type glyphQuad struct {
+	rect, frame pixel.Rect
+	color       pixel.RGBA
+}
+
+// quadTarget records the glyphs drawn to it.
+type quadTarget struct {
+	quads []glyphQuad
+}
+
+func (qt *quadTarget) MakeTriangles(t pixel.Triangles) pixel.TargetTriangles {
+	tri := &quadTriangles{TrianglesData: pixel.MakeTrianglesData(t.Len()), target: qt}
+	tri.Update(t)
+	return tri
+}
+
+func (qt *quadTarget) MakePicture(p pixel.Picture) pixel.TargetPicture {
+	return quadPicture{p}
+}
+
+type quadTriangles struct {
+	*pixel.TrianglesData
+	target *quadTarget
+}
+
+func (tri *quadTriangles) Draw() {
+	for i := 0; i+6 <= tri.Len(); i += 6 {
+		if _, intensity := tri.Picture(i); intensity == 0 {
+			// a decoration
+			continue
+		}
+		q := glyphQuad{color: tri.Color(i)}
+		for j := i; j < i+6; j++ {
+			pos := tri.Position(j)
+			pic, _ := tri.Picture(j)
+			r, f := pixel.R(pos.X, pos.Y, pos.X, pos.Y), pixel.R(pic.X, pic.Y, pic.X, pic.Y)
+			if j == i {
+				q.rect, q.frame = r, f
+			} else {
+				q.rect, q.frame = q.rect.Union(r), q.frame.Union(f)
+			}
+		}
+		if q.frame.Area() > 0 {
+			// not a space
+			tri.target.quads = append(tri.target.quads, q)
+		}
+	}
+}
+
+type quadPicture struct {
+	pixel.Picture
+}
+
+func (p quadPicture) Draw(t pixel.TargetTriangles) {
+	t.Draw()
+}
+
+// quads returns the glyphs of a Text from left to right.
+func quads(txt *text.Text) []glyphQuad {
+	qt := &quadTarget{}
+	txt.Draw(qt, pixel.IM)
+	slices.SortStableFunc(qt.quads, func(a, b glyphQuad) int {
+		switch {
+		case a.rect.Min.X < b.rect.Min.X:
+			return -1
+		case a.rect.Min.X > b.rect.Min.X:
+			return 1
+		}
+		return 0
+	})
+	return qt.quads
+}
+
+func frames(quads []glyphQuad) []pixel.Rect {
+	var frames []pixel.Rect
+	for _, q := range quads {
+		frames = append(frames, q.frame)
+	}
+	return frames
+}
+
+// framesOf returns the frames of the glyphs of runes shaped one by one, skipping spaces.
+func framesOf(atlas *text.Atlas, s string) []pixel.Rect {
+	var frames []pixel.Rect
+	for _, r := range s {
+		if r == ' ' {
+			continue
+		}
+		txt := text.New(pixel.ZV, atlas)
+		txt.WriteString(string(r))
+		for _, q := range quads(txt) {
+			frames = append(frames, q.frame)
+		}
+	}
+	return frames
+}
+
+func TestNewShapingAtlas(t *testing.T) {
+	assert.Panics(t, func() { text.NewShapingAtlas(16, 1024) })
+
+	_, err := text.NewShapingAtlas(16, 1024, []byte("not a font"))
+	assert.Error(t, err)
+
+	atlas := shapingAtlas(t, roboto)
+	assert.True(t, atlas.Contains('a'))
+	assert.False(t, atlas.Contains('ب'))
+
+	// runes missing from the first font are taken from the next one
+	atlas = shapingAtlas(t, roboto, amiri)
+	assert.True(t, atlas.Contains('ب'))
+	assert.Equal(t, shapingAtlas(t, roboto).Ascent(), atlas.Ascent())
+}
+
+func TestShape_Ligatures(t *testing.T) {
+	atlas := shapingAtlas(t, roboto)
+	txt := text.New(pixel.ZV, atlas)
+	fmt.Fprint(txt, "office")
+
+	// o, ffi, c, e
+	got := frames(quads(txt))
+	require.Len(t, got, 4)
+	assert.Equal(t, framesOf(atlas, "o"), got[:1])
+	assert.Equal(t, framesOf(atlas, "ce"), got[2:])
+	assert.NotContains(t, framesOf(atlas, "fi"), got[1])
+}
+
+func TestShape_Arabic(t *testing.T) {
+	atlas := shapingAtlas(t, amiri)
+	txt := text.New(pixel.ZV, atlas)
+	fmt.Fprint(txt, "بيت")
+
+	// the joined forms of beh, yeh and teh differ from the isolated ones
+	got := frames(quads(txt))
+	require.Len(t, got, 3)
+	for _, f := range framesOf(atlas, "بيت") {
+		assert.NotContains(t, got, f)
+	}
+}
+
+func TestShape_Devanagari(t *testing.T) {
+	atlas := shapingAtlas(t, devanagari)
+	txt := text.New(pixel.ZV, atlas)
+	fmt.Fprint(txt, "क्षि")
+
+	// the conjunct of ka, virama and ssa, preceded by the vowel sign i
+	got := quads(txt)
+	require.Len(t, got, 2)
+	assert.Less(t, got[0].rect.Min.X, got[1].rect.Min.X)
+	assert.NotContains(t, framesOf(atlas, "कष"), got[1].frame)
+}
+
+func TestShape_Bidi(t *testing.T) {
+	tests := []struct {
+		name, logical, visual string
+	}{
+		{"right to left", "אבג", "גבא"},
+		{"embedded right to left", "abc אבג def", "abc גבא def"},
+		{"embedded left to right", "אבג def הוז", "זוה def גבא"},
+		{"numbers", "אבג 123 דה", "הד 123 גבא"},
+		{"numbers after right to left", "abc אבג 12", "abc 12 גבא"},
+	}
+	atlas := shapingAtlas(t, hebrew, roboto)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txt := text.New(pixel.ZV, atlas)
+			fmt.Fprint(txt, tt.logical)
+			assert.Equal(t, framesOf(atlas, tt.visual), frames(quads(txt)))
+		})
+	}
+}
+
+func TestShape_Wrap(t *testing.T) {
+	atlas := shapingAtlas(t, hebrew, roboto)
+	width := text.New(pixel.ZV, atlas).BoundsOf("אב גד").W()
+
+	txt := text.New(pixel.ZV, atlas)
+	txt.MaxWidth = width + 1
+	bounds := txt.BoundsOf("אב גד הו")
+	fmt.Fprint(txt, "אב גד הו")
+	assert.Equal(t, pixel.V(txt.Dot.X, -atlas.LineHeight()), txt.Dot)
+	assert.Equal(t, bounds, txt.Bounds())
+	assert.LessOrEqual(t, txt.Bounds().W(), txt.MaxWidth)
+
+	// each line is reordered on its own
+	var first, second []glyphQuad
+	for _, q := range quads(txt) {
+		if q.rect.Max.Y > 0 {
+			first = append(first, q)
+		} else {
+			second = append(second, q)
+		}
+	}
+	assert.Equal(t, framesOf(atlas, "דג בא"), frames(first))
+	assert.Equal(t, framesOf(atlas, "וה"), frames(second))
+
+	// ligatures are formed before breaking lines
+	atlas = shapingAtlas(t, roboto)
+	txt = text.New(pixel.ZV, atlas)
+	txt.MaxWidth = text.New(pixel.ZV, atlas).BoundsOf("office").W() + 1
+	fmt.Fprint(txt, "office office")
+	assert.Equal(t, -atlas.LineHeight(), txt.Dot.Y)
+	assert.Len(t, quads(txt), 8)
+}
+
+func TestShape_MaxLines(t *testing.T) {
+	atlas := shapingAtlas(t, roboto)
+	txt := text.New(pixel.ZV, atlas)
+	txt.MaxWidth = text.New(pixel.ZV, atlas).BoundsOf("office").W() + 1
+	txt.MaxLines = 2
+	fmt.Fprint(txt, "office office office")
+	assert.Equal(t, -atlas.LineHeight(), txt.Dot.Y)
+	assert.LessOrEqual(t, txt.Bounds().W(), txt.MaxWidth)
+
+	// the second line is shortened to make room for the ellipsis
+	var got []pixel.Rect
+	for _, q := range quads(txt) {
+		if q.rect.Max.Y < 0 {
+			got = append(got, q.frame)
+		}
+	}
+	assert.Equal(t, framesOf(atlas, "…"), got[len(got)-1:])
+
+	all := frames(quads(txt))
+	fmt.Fprint(txt, "\nmore")
+	assert.Equal(t, all, frames(quads(txt)))
+}
+
+func TestShape_Writes(t *testing.T) {
+	atlas := shapingAtlas(t, roboto)
+	whole := text.New(pixel.ZV, atlas)
+	whole.Markup = true
+	fmt.Fprint(whole, "[color=#f00]off[/color]ice\nabc")
+
+	// the last line of the paragraph is shaped again on every write, so ligatures span writes
+	split := text.New(pixel.ZV, atlas)
+	split.Markup = true
+	for _, s := range []string{"[color=#f00]o", "ff[/color]", "i", "ce\na", "bc"} {
+		split.WriteString(s)
+	}
+	assert.Equal(t, whole.Dot, split.Dot)
+	assert.Equal(t, quads(whole), quads(split))
+
+	// the ligature takes the color of its first rune
+	var colors []pixel.RGBA
+	for _, q := range quads(whole) {
+		if q.rect.Max.Y > 0 {
+			colors = append(colors, q.color)
+		}
+	}
+	red, white := pixel.RGB(1, 0, 0), pixel.RGB(1, 1, 1)
+	assert.Equal(t, []pixel.RGBA{red, red, white, white}, colors)
+}
+
+func TestShape_WrappedWrites(t *testing.T) {
+	// only the last line of a paragraph is laid out again on a write, the lines before are final
+	atlas := shapingAtlas(t, hebrew, roboto)
+	const para = "office אבג office\tדה 123 office\u00adoffice office"
+	for _, align := range []text.Align{text.AlignLeft, text.AlignJustify} {
+		whole := text.New(pixel.ZV, atlas)
+		split := text.New(pixel.ZV, atlas)
+		for _, txt := range []*text.Text{whole, split} {
+			txt.MaxWidth = text.New(pixel.ZV, atlas).BoundsOf("office office").W()
+			txt.MaxLines = 4
+			txt.Align = align
+		}
+		fmt.Fprint(whole, para)
+		for _, r := range para {
+			split.WriteRune(r)
+		}
+		assert.Equal(t, whole.Dot, split.Dot)
+		assert.Equal(t, whole.Bounds(), split.Bounds())
+		assert.Equal(t, quads(whole), quads(split))
+	}
+}
+
+func BenchmarkShapeWrite(b *testing.B) {
+	ttf, err := hbdata.Files.ReadFile(roboto)
+	require.NoError(b, err)
+	atlas, err := text.NewShapingAtlas(16, 1024, ttf)
+	require.NoError(b, err)
+
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				txt := text.New(pixel.ZV, atlas)
+				txt.MaxWidth = 300
+				for j := 0; j < n; j++ {
+					txt.WriteString("office ")
+				}
+			}
+		})
+	}
+}
@@ -59,6 +59,32 @@ func RangeTable(table *unicode.RangeTable) []rune {
 //
 // Newlines, tabs and carriage returns are supported.
 //
+// If the Markup field is set, the written text may contain inline tags changing the style of the
+// text that follows them:
+//
+//	txt.Markup = true
+//	fmt.Fprint(txt, "[b]Warning:[/b] the [color=#ff0]bridge[/color] is [u]out[/u]")
+//
+// The supported tags are [color=...] with a #rgb, #rgba, #rrggbb, #rrggbbaa or SVG color name
+// value, [b] for bold, [i] for italic, [u] for underline and [s] for strikethrough. Each is closed
+// by its [/...] counterpart, e.g. [/color]. Bold and italic text is drawn with the Atlases set by
+// SetStyleAtlas. A literal '[' is written as "[[", other unknown tags are written as they are. An
+// unterminated tag is held back until the text completing it is written, so a trailing '[' must
+// be written as "[[" to be drawn.
+//
+// Setting MaxWidth makes the Text wrap lines at word boundaries, Align aligns the lines and
+// MaxLines truncates the text with an ellipsis:
+//
+//	txt.MaxWidth = 200
+//	txt.Align = text.AlignCenter
+//	txt.MaxLines = 3
+//
+// With an Atlas created by NewShapingAtlas, each paragraph, that is the text between newlines, is
+// shaped as a whole and its last line is laid out again whenever more of it is written. This draws
+// ligatures and the contextual forms of complex scripts such as Arabic or Devanagari, and puts the
+// lines of bidirectional text in visual order. Style Atlases which aren't shaping Atlases are
+// ignored then.
+//
 // Finally, if we want the written text to show up on some other Target, we can draw it:
 //
 //	txt.Draw(target)
@@ -92,22 +118,70 @@ type Text struct {
 	//   txt.TabWidth = 8 * txt.Atlas().Glyph(' ').Advance
 	TabWidth float64
 
-	atlas *Atlas
+	// Markup enables parsing of inline tags in the written text. See the Text documentation for
+	// the supported tags. A tag may be split across writes, so text from an unterminated '[' on is
+	// kept back, and not drawn, until the tag is closed by a later write.
+	Markup bool
+
+	// MaxWidth is the width at which lines are wrapped. Lines are broken after spaces and hyphens,
+	// or at soft hyphens (U+00AD), which are then drawn as '-'. Words that don't fit on a line by
+	// themselves are broken anywhere. With a shaping Atlas, lines are broken at the Unicode line
+	// break opportunities of the shaped text instead. Zero disables wrapping.
+	MaxWidth float64
+
+	// Align is the horizontal alignment of lines. Changing it only affects the lines written
+	// afterwards. Dot is not affected by the alignment.
+	Align Align
+
+	// MaxLines is the maximum number of lines. If the text doesn't fit, the last line is ended with
+	// an ellipsis and the rest of the text is dropped until the Text is cleared. Zero means no
+	// limit.
+	MaxLines int
+
+	atlas  *Atlas
+	styles map[Style]*Atlas
 
 	buf    []byte
 	prevR  rune
-	bounds pixel.Rect
 	glyph  pixel.TrianglesData
-	tris   pixel.TrianglesData
+	markup markupState
+	layers []*layer
+
+	// the paragraph written with a shaping Atlas, its last line laid out again on every write
+	para      []paraRune
+	paraOpen  bool
+	paraStart paraStart
+	paraEnd   pixel.Vec
+
+	line        []lineItem
+	lineShift   float64
+	lineBounds  pixel.Rect
+	linesBounds pixel.Rect
+	lines       int
+	truncated   bool
 
 	mat    pixel.Matrix
 	col    pixel.RGBA
-	trans  pixel.TrianglesData
-	transD pixel.Drawer
 	dirty  bool
 	anchor pixel.Anchor
 }
 
+// layer holds the triangles of the text drawn with one Atlas.
+type layer struct {
+	atlas  *Atlas
+	tris   pixel.TrianglesData
+	trans  pixel.TrianglesData
+	transD pixel.Drawer
+}
+
+func newLayer(atlas *Atlas) *layer {
+	l := &layer{atlas: atlas}
+	l.transD.Picture = atlas.Picture()
+	l.transD.Triangles = &l.trans
+	l.transD.Cached = true
+	return l
+}
+
 // New creates a new Text capable of drawing runes contained in the provided Atlas. Orig and Dot
 // will be initially set to orig.
 //
@@ -139,9 +213,7 @@ func New(orig pixel.Vec, atlas *Atlas) *Text {
 		txt.glyph[i].Intensity = 1
 	}
 
-	txt.transD.Picture = txt.atlas.pic
-	txt.transD.Triangles = &txt.trans
-	txt.transD.Cached = true
+	txt.layers = []*layer{newLayer(atlas)}
 
 	txt.Clear()
 
@@ -154,20 +226,73 @@ func (txt *Text) Atlas() *Atlas {
 	return txt.atlas
 }
 
+// SetStyleAtlas sets the Atlas used for text written in the given style with Markup enabled, for
+// example a bold face for Bold. Passing a nil Atlas removes it. Text in a style without an Atlas is
+// drawn in the closest style available: Bold|Italic falls back to Bold, then to Italic, and all
+// styles fall back to the Atlas passed to New, which is also always used for the Regular style.
+func (txt *Text) SetStyleAtlas(style Style, atlas *Atlas) {
+	if txt.styles == nil {
+		txt.styles = make(map[Style]*Atlas)
+	}
+	if atlas == nil {
+		delete(txt.styles, style)
+		return
+	}
+	txt.styles[style] = atlas
+}
+
+// StyleAtlas returns the Atlas used for text written in the given style.
+func (txt *Text) StyleAtlas(style Style) *Atlas {
+	for _, s := range [...]Style{style, style & Bold, style & Italic} {
+		if a := txt.styles[s]; a != nil && s != Regular {
+			return a
+		}
+	}
+	return txt.atlas
+}
+
+// layerOf returns the layer for drawing glyphs of atlas, adding one if needed.
+func (txt *Text) layerOf(atlas *Atlas) *layer {
+	for _, l := range txt.layers {
+		if l.atlas == atlas {
+			return l
+		}
+	}
+	l := newLayer(atlas)
+	txt.layers = append(txt.layers, l)
+	return l
+}
+
 // Bounds returns the bounding box of the text currently written to the Text excluding whitespace.
 //
 // If the Text is empty, a zero rectangle is returned.
 func (txt *Text) Bounds() pixel.Rect {
-	return txt.bounds
+	return unionBounds(txt.linesBounds, txt.lineBounds)
 }
 
 // BoundsOf returns the bounding box of s if it was to be written to the Text right now.
+//
+// When wrapping, s is measured as if it started a new word. With a shaping Atlas, s is measured as
+// if it started a new paragraph.
 func (txt *Text) BoundsOf(s string) pixel.Rect {
+	if txt.MaxWidth > 0 || txt.MaxLines > 0 || txt.Align != AlignLeft || txt.atlas.shaper != nil {
+		return txt.layoutBoundsOf(s)
+	}
+
 	dot := txt.Dot
 	prevR := txt.prevR
 	bounds := pixel.Rect{}
 
-	for _, r := range s {
+	st := txt.markup
+	st.colors = nil // colors don't affect the bounds, don't touch the Text's stack
+
+	for p := []byte(s); len(p) > 0; {
+		r, size := st.next(p, txt.Markup, true)
+		p = p[size:]
+		if r < 0 {
+			continue
+		}
+
 		var control bool
 		dot, control = txt.controlRune(r, dot)
 		if control {
@@ -175,12 +300,9 @@ func (txt *Text) BoundsOf(s string) pixel.Rect {
 		}
 
 		var b pixel.Rect
-		_, _, b, dot = txt.Atlas().DrawRune(prevR, r, dot)
-
-		if bounds.W()*bounds.H() == 0 {
-			bounds = b
-		} else {
-			bounds = bounds.Union(b)
+		_, _, b, dot = txt.StyleAtlas(st.style()).DrawRune(prevR, r, dot)
+		if r != ' ' {
+			bounds = unionBounds(bounds, b)
 		}
 
 		prevR = r
@@ -189,17 +311,49 @@ func (txt *Text) BoundsOf(s string) pixel.Rect {
 	return bounds
 }
 
+// layoutBoundsOf measures s by laying it out in a scratch Text with the same settings.
+func (txt *Text) layoutBoundsOf(s string) pixel.Rect {
+	scratch := New(txt.Orig, txt.atlas)
+	scratch.Dot = txt.Dot
+	scratch.LineHeight = txt.LineHeight
+	scratch.TabWidth = txt.TabWidth
+	scratch.Markup = txt.Markup
+	scratch.MaxWidth = txt.MaxWidth
+	scratch.Align = txt.Align
+	scratch.MaxLines = txt.MaxLines
+	scratch.styles = txt.styles
+	scratch.prevR = txt.prevR
+	scratch.lines = txt.lines
+	scratch.markup = txt.markup
+	scratch.markup.colors = nil
+	if scratch.truncated = txt.truncated; !scratch.truncated {
+		scratch.WriteString(s)
+	}
+	return scratch.Bounds()
+}
+
 // AlignedTo returns the text moved by the given anchor.
 func (txt *Text) AlignedTo(anchor pixel.Anchor) *Text {
 	txt.anchor = anchor
 	return txt
 }
 
-// Clear removes all written text from the Text. The Dot field is reset to Orig.
+// Clear removes all written text from the Text. The Dot field is reset to Orig and all open markup
+// tags are closed.
 func (txt *Text) Clear() {
 	txt.prevR = -1
-	txt.bounds = pixel.Rect{}
-	txt.tris.SetLen(0)
+	txt.markup = markupState{colors: txt.markup.colors[:0]}
+	txt.para = txt.para[:0]
+	txt.paraOpen = false
+	for _, l := range txt.layers {
+		l.tris.SetLen(0)
+	}
+	txt.line = txt.line[:0]
+	txt.lineShift = 0
+	txt.lineBounds = pixel.Rect{}
+	txt.linesBounds = pixel.Rect{}
+	txt.lines = 1
+	txt.truncated = false
 	txt.dirty = true
 	txt.Dot = txt.Orig
 }
@@ -271,19 +425,29 @@ func (txt *Text) DrawColorMask(t pixel.Target, matrix pixel.Matrix, mask color.C
 	}
 
 	if txt.dirty {
-		txt.trans.SetLen(txt.tris.Len())
-		txt.trans.Update(&txt.tris)
+		for _, l := range txt.layers {
+			l.trans.SetLen(l.tris.Len())
+			l.trans.Update(&l.tris)
 
-		for i := range txt.trans {
-			txt.trans[i].Position = txt.mat.Project(txt.trans[i].Position)
-			txt.trans[i].Color = txt.trans[i].Color.Mul(txt.col)
-		}
+			for i := range l.trans {
+				l.trans[i].Position = txt.mat.Project(l.trans[i].Position)
+				l.trans[i].Color = l.trans[i].Color.Mul(txt.col)
+			}
 
-		txt.transD.Dirty()
+			l.transD.Dirty()
+		}
 		txt.dirty = false
 	}
 
-	txt.transD.Draw(t)
+	for _, l := range txt.layers {
+		if pic := l.atlas.Picture(); pic != l.transD.Picture {
+			// a dynamic atlas changed, don't let the Drawer cache all of its pictures
+			l.transD = pixel.Drawer{Picture: pic, Triangles: &l.trans, Cached: true}
+		}
+		if l.trans.Len() > 0 {
+			l.transD.Draw(t)
+		}
+	}
 }
 
 // controlRune checks if r is a control rune (newline, tab, ...). If it is, a new dot position and
@@ -309,56 +473,118 @@ func (txt *Text) controlRune(r rune, dot pixel.Vec) (newDot pixel.Vec, control b
 }
 
 func (txt *Text) drawBuf() {
-	if !utf8.FullRune(txt.buf) {
+	if txt.atlas.shaper != nil {
+		txt.shapeBuf()
 		return
 	}
 
-	rgba := pixel.ToRGBA(txt.Color)
-	for i := range txt.glyph {
-		txt.glyph[i].Color = rgba
-	}
+	rgba := txt.markup.color(txt.Color)
 
-	for utf8.FullRune(txt.buf) {
-		r, size := utf8.DecodeRune(txt.buf)
+	for {
+		r, size := txt.markup.next(txt.buf, txt.Markup, false)
+		if size == 0 {
+			break
+		}
 		txt.buf = txt.buf[size:]
+		if r < 0 {
+			// a tag was applied
+			rgba = txt.markup.color(txt.Color)
+			continue
+		}
+		if txt.truncated {
+			continue
+		}
 
-		var control bool
-		txt.Dot, control = txt.controlRune(r, txt.Dot)
-		if control {
+		switch r {
+		case '\n':
+			if txt.newLine(rgba) {
+				txt.finishLine(false)
+				txt.Dot, _ = txt.controlRune(r, txt.Dot)
+			}
+			continue
+		case '\t':
+			x0 := txt.Dot.X
+			txt.Dot, _ = txt.controlRune(r, txt.Dot)
+			txt.addItem(lineItem{kind: itemSpace, x0: x0, x1: txt.Dot.X})
+			continue
+		case '\r':
+			txt.Dot, _ = txt.controlRune(r, txt.Dot)
 			continue
 		}
 
-		var rect, frame, bounds pixel.Rect
-		rect, frame, bounds, txt.Dot = txt.Atlas().DrawRune(txt.prevR, r, txt.Dot)
+		kind := txt.drawRune(r, rgba)
+		if txt.MaxWidth > 0 && kind != itemSpace && txt.Dot.X-txt.Orig.X > txt.MaxWidth {
+			txt.wrap(rgba)
+		}
+	}
+
+	txt.alignLine(false)
+}
 
+// drawRune draws r at the Dot in the current markup style and adds it to the current line.
+func (txt *Text) drawRune(r rune, col pixel.RGBA) itemKind {
+	it := lineItem{kind: itemGlyph, visible: true, color: col}
+	switch r {
+	case ' ':
+		it.kind = itemSpace
+		it.visible = false
+	case '-':
+		it.kind = itemHyphen
+	case softHyphen:
+		// drawn as an invisible hyphen, revealed if the line breaks here
+		it.kind = itemSoftHyphen
+		it.visible = false
+		r = '-'
+		col = pixel.RGBA{}
+	}
+
+	atlas := txt.StyleAtlas(txt.markup.style())
+	l := txt.layerOf(atlas)
+	it.layer = l
+	it.start = l.tris.Len()
+	it.x0 = txt.Dot.X
+
+	rect, frame, bounds, dot := atlas.DrawRune(txt.prevR, r, txt.Dot)
+	txt.appendGlyph(l, rect, frame, col)
+	if it.kind == itemSoftHyphen {
+		it.hyphen = dot.X - txt.Dot.X
+		dot = txt.Dot
+	} else {
+		l.tris = txt.markup.decorations(l.tris, atlas, dot, it.x0, dot.X, col)
 		txt.prevR = r
+	}
+	txt.dirty = true
 
-		rv := [...]pixel.Vec{
-			{X: rect.Min.X, Y: rect.Min.Y},
-			{X: rect.Max.X, Y: rect.Min.Y},
-			{X: rect.Max.X, Y: rect.Max.Y},
-			{X: rect.Min.X, Y: rect.Max.Y},
-		}
+	it.end = l.tris.Len()
+	it.x1 = dot.X
+	it.bounds = bounds
+	txt.Dot = dot
+	txt.addItem(it)
 
-		fv := [...]pixel.Vec{
-			{X: frame.Min.X, Y: frame.Min.Y},
-			{X: frame.Max.X, Y: frame.Min.Y},
-			{X: frame.Max.X, Y: frame.Max.Y},
-			{X: frame.Min.X, Y: frame.Max.Y},
-		}
+	return it.kind
+}
 
-		for i, j := range [...]int{0, 1, 2, 0, 2, 3} {
-			txt.glyph[i].Position = rv[j]
-			txt.glyph[i].Picture = fv[j]
-		}
+// appendGlyph appends the quad of a glyph drawn to rect, showing frame of the Atlas, to the layer.
+func (txt *Text) appendGlyph(l *layer, rect, frame pixel.Rect, col pixel.RGBA) {
+	rv := [...]pixel.Vec{
+		{X: rect.Min.X, Y: rect.Min.Y},
+		{X: rect.Max.X, Y: rect.Min.Y},
+		{X: rect.Max.X, Y: rect.Max.Y},
+		{X: rect.Min.X, Y: rect.Max.Y},
+	}
 
-		txt.tris = append(txt.tris, txt.glyph...)
-		txt.dirty = true
+	fv := [...]pixel.Vec{
+		{X: frame.Min.X, Y: frame.Min.Y},
+		{X: frame.Max.X, Y: frame.Min.Y},
+		{X: frame.Max.X, Y: frame.Max.Y},
+		{X: frame.Min.X, Y: frame.Max.Y},
+	}
 
-		if txt.bounds.W()*txt.bounds.H() == 0 {
-			txt.bounds = bounds
-		} else {
-			txt.bounds = txt.bounds.Union(bounds)
-		}
+	for i, j := range [...]int{0, 1, 2, 0, 2, 3} {
+		txt.glyph[i].Position = rv[j]
+		txt.glyph[i].Picture = fv[j]
+		txt.glyph[i].Color = col
 	}
+
+	l.tris = append(l.tris, txt.glyph...)
 }
@@ -0,0 +1,248 @@
+package pixel
+
+import (
+	"image/color"
+	"math"
+)
+
+// NineSliceMode determines how the edges and the center of a NineSlice fill their space.
+type NineSliceMode int
+
+const (
+	// NineSliceStretch stretches the part of the frame over the whole space.
+	NineSliceStretch NineSliceMode = iota
+	// NineSliceTile repeats the part of the frame in its original size, cutting off the last tile.
+	NineSliceTile
+)
+
+// NineSlice is a drawable frame of a Picture, that can be drawn at any size without distorting its
+// borders, like UI panels and buttons. It's anchored by its center, like Sprite.
+//
+// The frame is split into nine parts by the insets of the borders. The corners are drawn as they
+// are, the edges are stretched or tiled along the edge and the center is stretched or tiled in both
+// directions. If the size is smaller than the borders, the borders are scaled down to fit.
+//
+//	panel := pixel.NewNineSlice(pic, pic.Bounds(), 8, 8, 8, 8)
+//	panel.SetSize(pixel.V(200, 100))
+//	panel.Draw(win, pixel.IM.Moved(win.Bounds().Center()))
+type NineSlice struct {
+	tri   *TrianglesData
+	frame Rect
+	d     Drawer
+
+	left, bottom, right, top float64
+
+	size       Vec
+	edgeMode   NineSliceMode
+	centerMode NineSliceMode
+
+	matrix Matrix
+	mask   RGBA
+}
+
+// NewNineSlice creates a NineSlice from the supplied frame of a Picture and the widths of its left,
+// bottom, right and top borders. Its size is initially the size of the frame.
+func NewNineSlice(pic Picture, frame Rect, left, bottom, right, top float64) *NineSlice {
+	tri := MakeTrianglesData(0)
+	n := &NineSlice{
+		tri:    tri,
+		d:      Drawer{Triangles: tri, Cached: true},
+		left:   left,
+		bottom: bottom,
+		right:  right,
+		top:    top,
+		size:   frame.Size(),
+	}
+	n.matrix = IM
+	n.mask = Alpha(1)
+	n.Set(pic, frame)
+	return n
+}
+
+// Set sets a new frame of a Picture for this NineSlice.
+func (n *NineSlice) Set(pic Picture, frame Rect) {
+	n.d.Picture = pic
+	if frame != n.frame {
+		n.frame = frame
+		n.calcData()
+	}
+}
+
+// SetInsets sets the widths of the left, bottom, right and top borders of the frame.
+func (n *NineSlice) SetInsets(left, bottom, right, top float64) {
+	n.left, n.bottom, n.right, n.top = left, bottom, right, top
+	n.calcData()
+}
+
+// Insets returns the widths of the left, bottom, right and top borders of the frame.
+func (n *NineSlice) Insets() (left, bottom, right, top float64) {
+	return n.left, n.bottom, n.right, n.top
+}
+
+// SetSize sets the size the NineSlice is drawn at.
+func (n *NineSlice) SetSize(size Vec) {
+	if size != n.size {
+		n.size = size
+		n.calcData()
+	}
+}
+
+// Size returns the size the NineSlice is drawn at.
+func (n *NineSlice) Size() Vec {
+	return n.size
+}
+
+// SetEdgeMode sets how the edges fill their space. The default is NineSliceStretch.
+func (n *NineSlice) SetEdgeMode(mode NineSliceMode) {
+	if mode != n.edgeMode {
+		n.edgeMode = mode
+		n.calcData()
+	}
+}
+
+// SetCenterMode sets how the center fills its space. The default is NineSliceStretch.
+func (n *NineSlice) SetCenterMode(mode NineSliceMode) {
+	if mode != n.centerMode {
+		n.centerMode = mode
+		n.calcData()
+	}
+}
+
+// SetCached makes the NineSlice cache all the incoming pictures if the argument is true, and
+// doesn't make it do that if the argument is false.
+func (n *NineSlice) SetCached(cached bool) {
+	n.d.Cached = cached
+}
+
+// Picture returns the current NineSlice's Picture.
+func (n *NineSlice) Picture() Picture {
+	return n.d.Picture
+}
+
+// Frame returns the current NineSlice's frame.
+func (n *NineSlice) Frame() Rect {
+	return n.frame
+}
+
+// Draw draws the NineSlice onto the provided Target. The NineSlice will be transformed by the given
+// Matrix.
+//
+// This method is equivalent to calling DrawColorMask with nil color mask.
+func (n *NineSlice) Draw(t Target, matrix Matrix) {
+	n.DrawColorMask(t, matrix, nil)
+}
+
+// DrawColorMask draws the NineSlice onto the provided Target. The NineSlice will be transformed by
+// the given Matrix and all of it's color will be multiplied by the given mask.
+//
+// If the mask is nil, a fully opaque white mask will be used, which causes no effect.
+func (n *NineSlice) DrawColorMask(t Target, matrix Matrix, mask color.Color) {
+	dirty := false
+	if matrix != n.matrix {
+		n.matrix = matrix
+		dirty = true
+	}
+	if mask == nil {
+		mask = Alpha(1)
+	}
+	rgba := ToRGBA(mask)
+	if rgba != n.mask {
+		n.mask = rgba
+		dirty = true
+	}
+
+	if dirty {
+		n.calcData()
+	}
+
+	n.d.Draw(t)
+}
+
+// nineSliceSplits returns the positions splitting a frame from min to max with the given borders,
+// and the positions splitting the destination of the given size, centered around zero.
+func nineSliceSplits(min, max, start, end, size float64) (src, dst [4]float64) {
+	src = [4]float64{min, min + start, max - end, max}
+
+	// scale the borders down if they don't fit
+	if start+end > size && start+end > 0 {
+		scale := size / (start + end)
+		start, end = start*scale, end*scale
+	}
+	dst = [4]float64{-size / 2, -size/2 + start, size/2 - end, size / 2}
+	return src, dst
+}
+
+func (n *NineSlice) calcData() {
+	srcX, dstX := nineSliceSplits(n.frame.Min.X, n.frame.Max.X, n.left, n.right, n.size.X)
+	srcY, dstY := nineSliceSplits(n.frame.Min.Y, n.frame.Max.Y, n.bottom, n.top, n.size.Y)
+
+	// positions and picture coordinates of the corners of the quads
+	var quads [][2]Rect
+	for j := 0; j < 3; j++ {
+		for i := 0; i < 3; i++ {
+			src := R(srcX[i], srcY[j], srcX[i+1], srcY[j+1])
+			dst := R(dstX[i], dstY[j], dstX[i+1], dstY[j+1])
+			if src.W() <= 0 || src.H() <= 0 || dst.W() <= 0 || dst.H() <= 0 {
+				continue
+			}
+
+			mode := n.edgeMode
+			if i == 1 && j == 1 {
+				mode = n.centerMode
+			}
+			tileX := mode == NineSliceTile && i == 1
+			tileY := mode == NineSliceTile && j == 1
+			quads = appendTiles(quads, src, dst, tileX, tileY)
+		}
+	}
+
+	n.tri.SetLen(6 * len(quads))
+	for q, quad := range quads {
+		src, dst := quad[0], quad[1]
+		corners := [6][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 0}, {1, 1}, {0, 1}}
+		for k, c := range corners {
+			v := &(*n.tri)[6*q+k]
+			v.Position = n.matrix.Project(V(
+				dst.Min.X+c[0]*dst.W(),
+				dst.Min.Y+c[1]*dst.H(),
+			))
+			v.Picture = V(
+				src.Min.X+c[0]*src.W(),
+				src.Min.Y+c[1]*src.H(),
+			)
+			v.Color = n.mask
+			v.Intensity = 1
+		}
+	}
+
+	n.d.Dirty()
+}
+
+// appendTiles appends the quads covering dst with src, stretched or repeated in each direction.
+func appendTiles(quads [][2]Rect, src, dst Rect, tileX, tileY bool) [][2]Rect {
+	stepX, stepY := dst.W(), dst.H()
+	if tileX {
+		stepX = src.W()
+	}
+	if tileY {
+		stepY = src.H()
+	}
+
+	for y := dst.Min.Y; y < dst.Max.Y; y += stepY {
+		h := math.Min(stepY, dst.Max.Y-y)
+		for x := dst.Min.X; x < dst.Max.X; x += stepX {
+			w := math.Min(stepX, dst.Max.X-x)
+
+			// stretched quads use the whole source, cut off tiles only a part of it
+			s := src
+			if tileX {
+				s.Max.X = s.Min.X + w
+			}
+			if tileY {
+				s.Max.Y = s.Min.Y + h
+			}
+			quads = append(quads, [2]Rect{s, R(x, y, x+w, y+h)})
+		}
+	}
+	return quads
+}
@@ -0,0 +1,192 @@
+// Package pixeltest implements golden image snapshot testing for the Pixel game development library.
+//
+// A scene is rendered into an offscreen software Canvas (or any image, such as one obtained from
+// PictureData.Image) and compared against a PNG golden file stored in the testdata directory of the
+// package under test:
+//
+//	func TestPlayer(t *testing.T) {
+//		pixeltest.AssertScene(t, "player", pixel.R(0, 0, 64, 64), 0, func(target pixel.ComposeTarget) {
+//			player.Draw(target)
+//		})
+//	}
+//
+// Run the tests with the -update flag to (re)generate the golden files:
+//
+//	go test ./... -update
+//
+// The flag is defined by pixeltest, so a package using it must not define an -update flag of its
+// own. Look it up with flag.Lookup("update") to update other golden files along with the images.
+//
+// When an image doesn't match its golden file, a diff image highlighting the mismatching pixels in
+// red is written next to the golden file.
+package pixeltest
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// Dir is the directory, relative to the package under test, where golden files are stored.
+var Dir = "testdata"
+
+// Render draws a scene into a new software Canvas with the given bounds and returns its content.
+func Render(bounds pixel.Rect, scene func(t pixel.ComposeTarget)) *image.RGBA {
+	c := software.NewCanvas(bounds)
+	scene(c)
+	return c.Image()
+}
+
+// AssertScene renders a scene using Render and compares it with the named golden file using
+// AssertGolden.
+func AssertScene(t testing.TB, name string, bounds pixel.Rect, tolerance uint8, scene func(t pixel.ComposeTarget)) bool {
+	t.Helper()
+	return AssertGolden(t, name, Render(bounds, scene), tolerance)
+}
+
+// AssertGolden compares the image with the named golden file, allowing each color channel of each
+// pixel to differ by at most tolerance. It reports whether the image matches.
+//
+// On mismatch, the test is marked as failed and a diff image is written next to the golden file. If
+// the -update flag is set, the golden file is overwritten with the image instead.
+func AssertGolden(t testing.TB, name string, got image.Image, tolerance uint8) bool {
+	t.Helper()
+
+	path := goldenPath(name)
+	diffPath := diffPath(name)
+
+	if *update {
+		if err := writePNG(path, got); err != nil {
+			t.Fatalf("pixeltest: updating golden file: %v", err)
+			return false
+		}
+		os.Remove(diffPath)
+		return true
+	}
+
+	want, err := readPNG(path)
+	if err != nil {
+		t.Fatalf("pixeltest: reading golden file (run with -update to create it): %v", err)
+		return false
+	}
+
+	diff, mismatched := Compare(want, got, tolerance)
+	if diff == nil {
+		t.Errorf("pixeltest: %s: image size %v does not match golden size %v", name, got.Bounds().Size(), want.Bounds().Size())
+		return false
+	}
+	if mismatched > 0 {
+		if err := writePNG(diffPath, diff); err != nil {
+			t.Errorf("pixeltest: writing diff image: %v", err)
+		}
+		t.Errorf("pixeltest: %s: %d pixels differ from golden file, see %s", name, mismatched, diffPath)
+		return false
+	}
+
+	os.Remove(diffPath)
+	return true
+}
+
+// Compare compares two images pixel by pixel, allowing each color channel to differ by at most
+// tolerance. It returns the number of mismatching pixels and a diff image, where matching pixels
+// are a faded version of the wanted image and mismatching pixels are red.
+//
+// If the images differ in size, the returned diff image is nil.
+func Compare(want, got image.Image, tolerance uint8) (diff *image.RGBA, mismatched int) {
+	wb, gb := want.Bounds(), got.Bounds()
+	if wb.Size() != gb.Size() {
+		return nil, 0
+	}
+
+	diff = image.NewRGBA(image.Rect(0, 0, wb.Dx(), wb.Dy()))
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			w := color.RGBAModel.Convert(want.At(wb.Min.X+x, wb.Min.Y+y)).(color.RGBA)
+			g := color.RGBAModel.Convert(got.At(gb.Min.X+x, gb.Min.Y+y)).(color.RGBA)
+
+			if channelDiff(w.R, g.R) > tolerance ||
+				channelDiff(w.G, g.G) > tolerance ||
+				channelDiff(w.B, g.B) > tolerance ||
+				channelDiff(w.A, g.A) > tolerance {
+				diff.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+				mismatched++
+				continue
+			}
+
+			gray := uint8((uint16(w.R) + uint16(w.G) + uint16(w.B)) / 3)
+			diff.SetRGBA(x, y, color.RGBA{R: gray / 4, G: gray / 4, B: gray / 4, A: 255})
+		}
+	}
+
+	return diff, mismatched
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// AssertColor checks that each channel of got differs from want by at most delta, marking the test
+// as failed otherwise. The optional message is a format string followed by its arguments. It
+// reports whether the colors match.
+func AssertColor(t testing.TB, want, got pixel.RGBA, delta float64, msgAndArgs ...any) bool {
+	t.Helper()
+	if math.Abs(want.R-got.R) <= delta && math.Abs(want.G-got.G) <= delta &&
+		math.Abs(want.B-got.B) <= delta && math.Abs(want.A-got.A) <= delta {
+		return true
+	}
+	msg := ""
+	if len(msgAndArgs) > 0 {
+		msg = fmt.Sprintf(fmt.Sprint(msgAndArgs[0]), msgAndArgs[1:]...) + ": "
+	}
+	t.Errorf("%scolor %v differs from %v by more than %v", msg, got, want, delta)
+	return false
+}
+
+func goldenPath(name string) string {
+	return filepath.Join(Dir, name+".png")
+}
+
+func diffPath(name string) string {
+	return filepath.Join(Dir, name+".diff.png")
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return png.Decode(f)
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	return f.Close()
+}
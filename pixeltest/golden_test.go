@@ -0,0 +1,148 @@
+package pixeltest
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/ext/imdraw"
+	"github.com/gopxl/pixel/v2/ext/text"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a testing.TB that records failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failed = true
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failed = true
+}
+
+func TestCompare(t *testing.T) {
+	want := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	got := image.NewRGBA(image.Rect(5, 5, 7, 7))
+	got.SetRGBA(5, 5, color.RGBA{R: 3})
+	got.SetRGBA(6, 6, color.RGBA{G: 10})
+
+	diff, mismatched := Compare(want, got, 5)
+	require.NotNil(t, diff)
+	assert.Equal(t, 1, mismatched)
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, diff.RGBAAt(1, 1))
+	assert.Equal(t, color.RGBA{A: 255}, diff.RGBAAt(0, 0))
+
+	diff, _ = Compare(want, image.NewRGBA(image.Rect(0, 0, 3, 2)), 0)
+	assert.Nil(t, diff)
+}
+
+func TestAssertColor(t *testing.T) {
+	r := &recorder{TB: t}
+	assert.True(t, AssertColor(r, pixel.RGB(1, 0.5, 0), pixel.RGB(1, 0.5+1e-10, 0), 1e-9))
+	assert.False(t, r.failed)
+	assert.False(t, AssertColor(r, pixel.RGB(1, 0.5, 0), pixel.Alpha(0.5), 1e-9, "x=%v", 1))
+	assert.True(t, r.failed)
+}
+
+func TestAssertGolden(t *testing.T) {
+	defer func(dir string, upd bool) { Dir, *update = dir, upd }(Dir, *update)
+	Dir = t.TempDir()
+	*update = false
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+
+	// missing golden
+	rec := &recorder{TB: t}
+	assert.False(t, AssertGolden(rec, "img", img, 0))
+	assert.True(t, rec.failed)
+
+	*update = true
+	assert.True(t, AssertGolden(t, "img", img, 0))
+	*update = false
+	assert.FileExists(t, filepath.Join(Dir, "img.png"))
+
+	assert.True(t, AssertGolden(t, "img", img, 0))
+
+	img.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+	rec = &recorder{TB: t}
+	assert.False(t, AssertGolden(rec, "img", img, 0))
+	assert.True(t, rec.failed)
+	assert.FileExists(t, filepath.Join(Dir, "img.diff.png"))
+
+	img.SetRGBA(0, 0, color.RGBA{})
+	assert.True(t, AssertGolden(t, "img", img, 0))
+	_, err := os.Stat(filepath.Join(Dir, "img.diff.png"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUpdateFlag(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	if dir := os.Getenv("PIXELTEST_DIR"); dir != "" {
+		// run by the test below
+		Dir = dir
+		AssertGolden(t, "img", img, 0)
+		return
+	}
+
+	// the test binary is run again, with and without the flag
+	dir := t.TempDir()
+	run := func(args ...string) error {
+		args = append([]string{"-test.run=^TestUpdateFlag$"}, args...)
+		cmd := exec.Command(os.Args[0], args...)
+		cmd.Env = append(os.Environ(), "PIXELTEST_DIR="+dir)
+		return cmd.Run()
+	}
+	assert.Error(t, run())
+	assert.NoFileExists(t, filepath.Join(dir, "img.png"))
+	require.NoError(t, run("-update"))
+	assert.FileExists(t, filepath.Join(dir, "img.png"))
+	assert.NoError(t, run())
+}
+
+func TestScene_IMDrawPolygon(t *testing.T) {
+	AssertScene(t, "imdraw_polygon", pixel.R(0, 0, 32, 32), 0, func(target pixel.ComposeTarget) {
+		imd := imdraw.New(nil)
+		imd.Color = pixel.RGB(1, 0, 0)
+		imd.Push(pixel.V(4, 4))
+		imd.Color = pixel.RGB(0, 1, 0)
+		imd.Push(pixel.V(28, 8))
+		imd.Color = pixel.RGB(0, 0, 1)
+		imd.Push(pixel.V(16, 28))
+		imd.Polygon(0)
+		imd.Draw(target)
+	})
+}
+
+func TestScene_Text(t *testing.T) {
+	AssertScene(t, "text", pixel.R(0, 0, 64, 16), 0, func(target pixel.ComposeTarget) {
+		txt := text.New(pixel.V(2, 4), text.Atlas7x13)
+		txt.Color = pixel.RGB(1, 1, 0)
+		txt.WriteString("Pixel")
+		txt.Draw(target, pixel.IM)
+	})
+}
+
+func TestScene_SpriteColorMask(t *testing.T) {
+	pic := pixel.MakePictureData(pixel.R(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			pic.Pix[y*pic.Stride+x] = color.RGBA{uint8(x * 64), uint8(y * 64), 255, 255}
+		}
+	}
+
+	AssertScene(t, "sprite_color_mask", pixel.R(0, 0, 16, 16), 0, func(target pixel.ComposeTarget) {
+		sprite := pixel.NewSprite(pic, pic.Bounds())
+		sprite.DrawColorMask(target, pixel.IM.Scaled(pixel.ZV, 3).Moved(pixel.V(8, 8)), pixel.RGB(1, 0.5, 0.5))
+	})
+}
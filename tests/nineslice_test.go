@@ -0,0 +1,85 @@
+package pixel_test
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/gopxl/pixel/v2"
+	"github.com/gopxl/pixel/v2/backends/software"
+	"github.com/stretchr/testify/assert"
+)
+
+// ninePicture returns a w by h picture with a unique color in each pixel.
+func ninePicture(w, h int) *pixel.PictureData {
+	pic := pixel.MakePictureData(pixel.R(0, 0, float64(w), float64(h)))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			pic.Pix[pic.Index(pixel.V(float64(x), float64(y)))] = color.RGBA{uint8(40 * x), uint8(40 * y), 255, 255}
+		}
+	}
+	return pic
+}
+
+func ninePixel(pic *pixel.PictureData, x, y float64) pixel.RGBA {
+	return pixel.ToRGBA(pic.Color(pixel.V(x, y)))
+}
+
+func TestNineSlice_Stretch(t *testing.T) {
+	pic := ninePicture(3, 3)
+	n := pixel.NewNineSlice(pic, pic.Bounds(), 1, 1, 1, 1)
+	assert.Equal(t, pixel.V(3, 3), n.Size())
+
+	n.SetSize(pixel.V(6, 5))
+	c := software.NewCanvas(pixel.R(0, 0, 6, 5))
+	n.Draw(c, pixel.IM.Moved(c.Bounds().Center()))
+
+	// corners keep their size
+	assert.Equal(t, ninePixel(pic, 0, 0), c.Color(pixel.V(0, 0)))
+	assert.Equal(t, ninePixel(pic, 2, 0), c.Color(pixel.V(5, 0)))
+	assert.Equal(t, ninePixel(pic, 0, 2), c.Color(pixel.V(0, 4)))
+	assert.Equal(t, ninePixel(pic, 2, 2), c.Color(pixel.V(5, 4)))
+
+	// edges and center are stretched
+	for x := 1.0; x < 5; x++ {
+		assert.Equal(t, ninePixel(pic, 1, 0), c.Color(pixel.V(x, 0)))
+		assert.Equal(t, ninePixel(pic, 1, 2), c.Color(pixel.V(x, 4)))
+		for y := 1.0; y < 4; y++ {
+			assert.Equal(t, ninePixel(pic, 1, 1), c.Color(pixel.V(x, y)))
+		}
+	}
+	for y := 1.0; y < 4; y++ {
+		assert.Equal(t, ninePixel(pic, 0, 1), c.Color(pixel.V(0, y)))
+		assert.Equal(t, ninePixel(pic, 2, 1), c.Color(pixel.V(5, y)))
+	}
+}
+
+func TestNineSlice_Tile(t *testing.T) {
+	pic := ninePicture(4, 4)
+	n := pixel.NewNineSlice(pic, pic.Bounds(), 1, 1, 1, 1)
+	n.SetEdgeMode(pixel.NineSliceTile)
+	n.SetCenterMode(pixel.NineSliceTile)
+	n.SetSize(pixel.V(7, 4))
+
+	c := software.NewCanvas(pixel.R(0, 0, 7, 4))
+	n.Draw(c, pixel.IM.Moved(c.Bounds().Center()))
+
+	// the 2 pixels wide middle is repeated, the last tile is cut off
+	for i, x := range []float64{1, 2, 1, 2, 1} {
+		assert.Equal(t, ninePixel(pic, x, 0), c.Color(pixel.V(float64(i+1), 0)), "bottom edge at %v", i+1)
+		assert.Equal(t, ninePixel(pic, x, 1), c.Color(pixel.V(float64(i+1), 1)), "center at %v", i+1)
+		assert.Equal(t, ninePixel(pic, x, 2), c.Color(pixel.V(float64(i+1), 2)), "center at %v", i+1)
+	}
+	assert.Equal(t, ninePixel(pic, 3, 3), c.Color(pixel.V(6, 3)))
+}
+
+func TestNineSlice_SmallerThanBorders(t *testing.T) {
+	pic := ninePicture(4, 4)
+	n := pixel.NewNineSlice(pic, pic.Bounds(), 2, 2, 2, 2)
+	n.SetSize(pixel.V(2, 2))
+
+	// the 2 pixels wide borders are scaled down to a pixel each
+	c := software.NewCanvas(pixel.R(0, 0, 2, 2))
+	n.Draw(c, pixel.IM.Moved(c.Bounds().Center()))
+	assert.Equal(t, ninePixel(pic, 1, 1), c.Color(pixel.V(0, 0)))
+	assert.Equal(t, ninePixel(pic, 3, 3), c.Color(pixel.V(1, 1)))
+}
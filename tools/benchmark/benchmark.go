@@ -17,7 +17,7 @@ type Config struct {
 	Description string
 
 	// New returns the benchmark to be executed
-	New func(win *opengl.Window) (Benchmark, error)
+	New func(win pixel.Window) (Benchmark, error)
 	// Duration sets the maximum duration to run the benchmark
 	Duration time.Duration
 	// WindowConfig defines the input parameters to the benchmark's window
@@ -93,7 +93,7 @@ loop:
 
 // Benchmark provides hooks into the stages of a window's lifecycle
 type Benchmark interface {
-	Step(win *opengl.Window, delta float64)
+	Step(win pixel.Window, delta float64)
 }
 
 // Registry is a collection of benchmark configs
@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/gopxl/pixel/v2"
-	"github.com/gopxl/pixel/v2/backends/opengl"
 	"github.com/gopxl/pixel/v2/ext/imdraw"
 )
 
@@ -42,7 +41,7 @@ func init() {
 	)
 }
 
-func newStaticTriangles(win *opengl.Window) (Benchmark, error) {
+func newStaticTriangles(win pixel.Window) (Benchmark, error) {
 	bounds := win.Bounds()
 	width := bounds.W()
 	height := bounds.H()
@@ -57,7 +56,7 @@ func newStaticTriangles(win *opengl.Window) (Benchmark, error) {
 	return benchmark, nil
 }
 
-func newStaticTrianglesBatched(win *opengl.Window) (Benchmark, error) {
+func newStaticTrianglesBatched(win pixel.Window) (Benchmark, error) {
 	benchmark, err := newStaticTriangles(win)
 	if err != nil {
 		return nil, err
@@ -75,7 +74,7 @@ type staticTriangles struct {
 	cell       pixel.Vec
 }
 
-func (st *staticTriangles) Step(win *opengl.Window, delta float64) {
+func (st *staticTriangles) Step(win pixel.Window, delta float64) {
 	win.Clear(backgroundColor)
 
 	var target pixel.BasicTarget
@@ -99,7 +98,7 @@ func (st *staticTriangles) Step(win *opengl.Window, delta float64) {
 	}
 }
 
-func newMovingTriangles(win *opengl.Window) (Benchmark, error) {
+func newMovingTriangles(win pixel.Window) (Benchmark, error) {
 	bounds := win.Bounds()
 	width := bounds.W()
 	height := bounds.H()
@@ -114,7 +113,7 @@ func newMovingTriangles(win *opengl.Window) (Benchmark, error) {
 	return benchmark, nil
 }
 
-func newMovingTrianglesBatched(win *opengl.Window) (Benchmark, error) {
+func newMovingTrianglesBatched(win pixel.Window) (Benchmark, error) {
 	benchmark, err := newMovingTriangles(win)
 	if err != nil {
 		return nil, err
@@ -133,7 +132,7 @@ type movingTriangles struct {
 	yOffset    float64
 }
 
-func (mt *movingTriangles) Step(win *opengl.Window, delta float64) {
+func (mt *movingTriangles) Step(win pixel.Window, delta float64) {
 	win.Clear(backgroundColor)
 
 	var target pixel.BasicTarget
@@ -10,7 +10,6 @@ import (
 	"time"
 
 	"github.com/gopxl/pixel/v2"
-	"github.com/gopxl/pixel/v2/backends/opengl"
 )
 
 var (
@@ -52,7 +51,7 @@ func init() {
 	)
 }
 
-func newSpriteStatic(win *opengl.Window) (Benchmark, error) {
+func newSpriteStatic(win pixel.Window) (Benchmark, error) {
 	sprite, err := loadSprite(logoPath, logoFrame)
 	if err != nil {
 		return nil, err
@@ -72,7 +71,7 @@ func newSpriteStatic(win *opengl.Window) (Benchmark, error) {
 	return benchmark, nil
 }
 
-func newSpriteStaticBatched(win *opengl.Window) (Benchmark, error) {
+func newSpriteStaticBatched(win pixel.Window) (Benchmark, error) {
 	benchmark, err := newSpriteStatic(win)
 	if err != nil {
 		return nil, err
@@ -89,7 +88,7 @@ type spriteStatic struct {
 	batch      *pixel.Batch
 }
 
-func (ss *spriteStatic) Step(win *opengl.Window, delta float64) {
+func (ss *spriteStatic) Step(win pixel.Window, delta float64) {
 	win.Clear(backgroundColor)
 	var target pixel.Target
 	if ss.batch != nil {
@@ -104,7 +103,7 @@ func (ss *spriteStatic) Step(win *opengl.Window, delta float64) {
 	}
 }
 
-func newSpriteMoving(win *opengl.Window) (Benchmark, error) {
+func newSpriteMoving(win pixel.Window) (Benchmark, error) {
 	sprite, err := loadSprite(logoPath, logoFrame)
 	if err != nil {
 		return nil, err
@@ -122,7 +121,7 @@ func newSpriteMoving(win *opengl.Window) (Benchmark, error) {
 	return benchmark, nil
 }
 
-func newSpriteMovingBatched(win *opengl.Window) (Benchmark, error) {
+func newSpriteMovingBatched(win pixel.Window) (Benchmark, error) {
 	benchmark, err := newSpriteMoving(win)
 	if err != nil {
 		return nil, err
@@ -140,7 +139,7 @@ type spriteMoving struct {
 	yOffset    float64
 }
 
-func (sm *spriteMoving) Step(win *opengl.Window, delta float64) {
+func (sm *spriteMoving) Step(win pixel.Window, delta float64) {
 	win.Clear(backgroundColor)
 	var target pixel.Target
 	if sm.batch != nil {
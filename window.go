@@ -0,0 +1,122 @@
+package pixel
+
+import "image/color"
+
+// Window is a backend agnostic window, something that can be drawn onto, receives input and is
+// updated every frame. It's implemented by the Window types of all backends.
+//
+// Write game systems against this interface instead of a concrete backend's Window, so that the
+// backend can be swapped (e.g. for a headless one in tests) without rewriting them.
+type Window interface {
+	ComposeTarget
+	WindowInput
+
+	// Update swaps buffers and polls events. Call this method at the end of each frame.
+	Update()
+
+	// Closed returns the closed flag of the Window, which reports whether the Window should be
+	// closed.
+	Closed() bool
+
+	// SetClosed sets the closed flag of the Window.
+	SetClosed(closed bool)
+
+	// SetTitle changes the title of the Window.
+	SetTitle(title string)
+
+	// Bounds returns the current bounds of the Window.
+	Bounds() Rect
+
+	// SetBounds sets the bounds of the Window in pixels.
+	SetBounds(bounds Rect)
+
+	// Clear clears the Window with a single color.
+	Clear(c color.Color)
+
+	// Color returns the color of the pixel over the given position inside the Window.
+	Color(at Vec) RGBA
+
+	// SetSmooth sets whether the stretched Pictures drawn onto this Window should be drawn smooth
+	// or pixely.
+	SetSmooth(smooth bool)
+
+	// Smooth returns whether the stretched Pictures drawn onto this Window are set to be drawn
+	// smooth or pixely.
+	Smooth() bool
+
+	// SetVSync sets whether the Window's Update should synchronize with the monitor refresh rate.
+	SetVSync(vsync bool)
+
+	// VSync returns whether the Window is set to synchronize with the monitor refresh rate.
+	VSync() bool
+
+	// ClipboardText returns the current value of the clipboard.
+	ClipboardText() string
+
+	// SetClipboardText sets the clipboard.
+	SetClipboardText(text string)
+
+	// SetCursorVisible sets the visibility of the mouse cursor inside the Window client area.
+	SetCursorVisible(visible bool)
+
+	// CursorVisible returns the visibility status of the mouse cursor.
+	CursorVisible() bool
+
+	// SetMousePosition positions the mouse cursor anywhere within the Window's Bounds.
+	SetMousePosition(v Vec)
+}
+
+// WindowInput is the input state of a Window, as of the last call to its Update method.
+type WindowInput interface {
+	// Pressed returns whether the Button is currently pressed down.
+	Pressed(button Button) bool
+
+	// JustPressed returns whether the Button has been pressed in the last frame.
+	JustPressed(button Button) bool
+
+	// JustReleased returns whether the Button has been released in the last frame.
+	JustReleased(button Button) bool
+
+	// Repeated returns whether a repeat event has been triggered on button.
+	Repeated(button Button) bool
+
+	// MousePosition returns the current mouse position in the Window's Bounds.
+	MousePosition() Vec
+
+	// MousePreviousPosition returns the previous mouse position in the Window's Bounds.
+	MousePreviousPosition() Vec
+
+	// MouseInsideWindow returns true if the mouse position is within the Window's Bounds.
+	MouseInsideWindow() bool
+
+	// MouseScroll returns the mouse scroll amount (in both axes) since the last Update.
+	MouseScroll() Vec
+
+	// Typed returns the text typed on the keyboard since the last Update.
+	Typed() string
+
+	// JoystickPresent returns if the joystick is currently connected.
+	JoystickPresent(js Joystick) bool
+
+	// JoystickName returns the name of the joystick. A disconnected joystick will return an empty
+	// string.
+	JoystickName(js Joystick) string
+
+	// JoystickButtonCount returns the number of buttons a connected joystick has.
+	JoystickButtonCount(js Joystick) int
+
+	// JoystickAxisCount returns the number of axes a connected joystick has.
+	JoystickAxisCount(js Joystick) int
+
+	// JoystickPressed returns whether the joystick Button is currently pressed down.
+	JoystickPressed(js Joystick, button GamepadButton) bool
+
+	// JoystickJustPressed returns whether the joystick Button has just been pressed down.
+	JoystickJustPressed(js Joystick, button GamepadButton) bool
+
+	// JoystickJustReleased returns whether the joystick Button has just been released up.
+	JoystickJustReleased(js Joystick, button GamepadButton) bool
+
+	// JoystickAxis returns the value of a joystick axis at the last Update.
+	JoystickAxis(js Joystick, axis GamepadAxis) float64
+}